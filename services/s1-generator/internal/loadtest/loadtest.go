@@ -0,0 +1,292 @@
+package loadtest
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/leandroalencar/banco-dados/shared/models"
+	"github.com/leandroalencar/banco-dados/shared/utils"
+)
+
+// tick is how often the publisher wakes up to send the messages due
+const tick = 10 * time.Millisecond
+
+// Config describes how the publish rate ramps up
+type Config struct {
+	// StartRate and MaxRate are transactions per second
+	StartRate float64
+	MaxRate   float64
+	// Step is added to the rate every StepDuration until MaxRate is reached
+	Step         float64
+	StepDuration time.Duration
+	// Drain is how long to wait for outstanding results after the last step
+	Drain time.Duration
+	// Queue receives the transactions; ResultsQueue carries their results
+	// and RejectionsQueue those that failed validation
+	Queue           string
+	ResultsQueue    string
+	RejectionsQueue string
+}
+
+// Validate checks that the config ramps through at least one step
+func (c Config) Validate() error {
+	switch {
+	case c.StartRate <= 0:
+		return errors.New("start rate must be positive")
+	case c.StartRate > c.MaxRate:
+		return fmt.Errorf("start rate %.1f is above max rate %.1f, leaving no steps to run", c.StartRate, c.MaxRate)
+	case c.StepDuration <= 0:
+		return errors.New("step duration must be positive")
+	}
+	return nil
+}
+
+// Broker publishes tagged messages and consumes a queue
+type Broker interface {
+	PublishMessageWithID(queue, messageID string, message interface{}) error
+	ConsumeMessages(queue string, handler func([]byte) error) error
+}
+
+// stepStats aggregates one rate step
+type stepStats struct {
+	target    float64
+	start     time.Time
+	end       time.Time
+	sent      int
+	completed int
+	rejected  int
+	// invalid counts transactions the validator rejected, which never
+	// reach the processor
+	invalid   int
+	pubErrors int
+	latencies []time.Duration
+}
+
+// Runner sends transactions at increasing rates and measures how long the
+// pipeline takes to answer each of them
+type Runner struct {
+	cfg    Config
+	broker Broker
+	next   func() (models.Transaction, bool)
+
+	mu      sync.Mutex
+	steps   []*stepStats
+	pending map[string]pendingMessage
+}
+
+type pendingMessage struct {
+	sentAt time.Time
+	step   *stepStats
+}
+
+// NewRunner creates a runner generating transactions with next, which
+// returns false when no transaction can be generated
+func NewRunner(cfg Config, broker Broker, next func() (models.Transaction, bool)) *Runner {
+	return &Runner{
+		cfg:     cfg,
+		broker:  broker,
+		next:    next,
+		pending: make(map[string]pendingMessage),
+	}
+}
+
+// Run ramps the publish rate, waits for outstanding results and writes the
+// report to out
+func (r *Runner) Run(ctx context.Context, out io.Writer) error {
+	if err := r.cfg.Validate(); err != nil {
+		return err
+	}
+	if err := r.broker.ConsumeMessages(r.cfg.ResultsQueue, r.handleResult); err != nil {
+		return fmt.Errorf("failed to consume %s: %w", r.cfg.ResultsQueue, err)
+	}
+	if err := r.broker.ConsumeMessages(r.cfg.RejectionsQueue, r.handleRejection); err != nil {
+		return fmt.Errorf("failed to consume %s: %w", r.cfg.RejectionsQueue, err)
+	}
+
+	for rate := r.cfg.StartRate; rate <= r.cfg.MaxRate && ctx.Err() == nil; rate += r.cfg.Step {
+		log.Printf("Publishing %.1f transactions/s for %s", rate, r.cfg.StepDuration)
+		r.runStep(ctx, rate)
+		if r.cfg.Step <= 0 {
+			break
+		}
+	}
+
+	log.Printf("Waiting %s for outstanding results", r.cfg.Drain)
+	select {
+	case <-ctx.Done():
+	case <-time.After(r.cfg.Drain):
+	}
+
+	r.report(out)
+	return nil
+}
+
+// runStep publishes at rate for one step duration
+func (r *Runner) runStep(ctx context.Context, rate float64) {
+	step := &stepStats{target: rate, start: time.Now()}
+	r.mu.Lock()
+	r.steps = append(r.steps, step)
+	r.mu.Unlock()
+
+	ticker := time.NewTicker(tick)
+	defer ticker.Stop()
+	deadline := time.After(r.cfg.StepDuration)
+
+	budget := 0.0
+	for {
+		select {
+		case <-ctx.Done():
+			step.end = time.Now()
+			return
+		case <-deadline:
+			step.end = time.Now()
+			return
+		case <-ticker.C:
+		}
+
+		budget += rate * tick.Seconds()
+		for ; budget >= 1; budget-- {
+			r.send(step)
+		}
+	}
+}
+
+func (r *Runner) send(step *stepStats) {
+	transaction, ok := r.next()
+	if !ok {
+		return
+	}
+	id := utils.NewMessageID()
+	transaction.Timestamp = time.Now()
+
+	r.mu.Lock()
+	r.pending[id] = pendingMessage{sentAt: transaction.Timestamp, step: step}
+	r.mu.Unlock()
+
+	err := r.broker.PublishMessageWithID(r.cfg.Queue, id, transaction)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err != nil {
+		delete(r.pending, id)
+		step.pubErrors++
+		return
+	}
+	step.sent++
+}
+
+func (r *Runner) handleResult(body []byte) error {
+	received := time.Now()
+
+	var result models.TransactionResult
+	if err := json.Unmarshal(body, &result); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	msg, ok := r.pending[result.CorrelationID]
+	if !ok {
+		// Not sent by this run, or a duplicate result
+		return nil
+	}
+	delete(r.pending, result.CorrelationID)
+
+	msg.step.latencies = append(msg.step.latencies, received.Sub(msg.sentAt))
+	if result.Event == models.TransactionRejected {
+		msg.step.rejected++
+	} else {
+		msg.step.completed++
+	}
+	return nil
+}
+
+// handleRejection counts a transaction rejected by the validator as an error
+func (r *Runner) handleRejection(body []byte) error {
+	var rejection models.Rejection
+	if err := json.Unmarshal(body, &rejection); err != nil {
+		return err
+	}
+	if rejection.Queue != r.cfg.Queue {
+		return nil
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	msg, ok := r.pending[rejection.MessageID]
+	if !ok {
+		return nil
+	}
+	delete(r.pending, rejection.MessageID)
+	msg.step.invalid++
+	return nil
+}
+
+// report prints per-step and overall throughput, latency and errors
+func (r *Runner) report(out io.Writer) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	missing := make(map[*stepStats]int)
+	for _, msg := range r.pending {
+		missing[msg.step]++
+	}
+
+	fmt.Fprintln(out, "Load test report")
+	fmt.Fprintf(out, "%8s %8s %8s %8s %8s %8s %8s %8s %10s %10s %10s %10s\n",
+		"target/s", "sent/s", "sent", "ok", "rejected", "invalid", "no-reply", "pub-err", "p50", "p95", "p99", "max")
+
+	total := &stepStats{}
+	for _, step := range r.steps {
+		elapsed := step.end.Sub(step.start).Seconds()
+		printRow(out, fmt.Sprintf("%8.1f", step.target), float64(step.sent)/elapsed, step, missing[step])
+
+		if total.start.IsZero() {
+			total.start = step.start
+		}
+		total.end = step.end
+		total.sent += step.sent
+		total.completed += step.completed
+		total.rejected += step.rejected
+		total.invalid += step.invalid
+		total.pubErrors += step.pubErrors
+		total.latencies = append(total.latencies, step.latencies...)
+	}
+
+	elapsed := total.end.Sub(total.start).Seconds()
+	printRow(out, fmt.Sprintf("%8s", "total"), float64(total.sent)/elapsed, total, len(r.pending))
+	if elapsed > 0 {
+		fmt.Fprintf(out, "Achieved throughput: %.1f results/s\n", float64(total.completed+total.rejected)/elapsed)
+	}
+	fmt.Fprintf(out, "Errors: %d (invalid %d, no reply %d, publish %d)\n",
+		total.invalid+len(r.pending)+total.pubErrors, total.invalid, len(r.pending), total.pubErrors)
+}
+
+func printRow(out io.Writer, label string, sentRate float64, s *stepStats, missing int) {
+	sort.Slice(s.latencies, func(i, j int) bool { return s.latencies[i] < s.latencies[j] })
+	fmt.Fprintf(out, "%s %8.1f %8d %8d %8d %8d %8d %8d %10s %10s %10s %10s\n",
+		label, sentRate, s.sent, s.completed, s.rejected, s.invalid, missing, s.pubErrors,
+		percentile(s.latencies, 50), percentile(s.latencies, 95), percentile(s.latencies, 99), percentile(s.latencies, 100))
+}
+
+// percentile returns the nearest-rank percentile of sorted latencies
+func percentile(sorted []time.Duration, p float64) string {
+	if len(sorted) == 0 {
+		return "-"
+	}
+	rank := int(math.Ceil(p/100*float64(len(sorted)))) - 1
+	if rank < 0 {
+		rank = 0
+	}
+	return sorted[rank].Round(time.Microsecond).String()
+}
@@ -0,0 +1,75 @@
+package loadtest
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/leandroalencar/banco-dados/shared/models"
+)
+
+func TestConfigValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     Config
+		wantErr bool
+	}{
+		{name: "ramp", cfg: Config{StartRate: 10, MaxRate: 100, Step: 10, StepDuration: time.Second}},
+		{name: "single step", cfg: Config{StartRate: 10, MaxRate: 10, StepDuration: time.Second}},
+		{name: "start above max", cfg: Config{StartRate: 20, MaxRate: 10, Step: 10, StepDuration: time.Second}, wantErr: true},
+		{name: "zero start", cfg: Config{MaxRate: 10, Step: 10, StepDuration: time.Second}, wantErr: true},
+		{name: "zero step duration", cfg: Config{StartRate: 10, MaxRate: 10}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.cfg.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestRunnerCorrelatesAnswers(t *testing.T) {
+	tests := []struct {
+		name      string
+		result    *models.TransactionResult
+		rejection *models.Rejection
+		completed int
+		rejected  int
+		invalid   int
+		pending   int
+	}{
+		{name: "completed", result: &models.TransactionResult{Event: models.TransactionCompleted, CorrelationID: "a"}, completed: 1},
+		{name: "rejected by the processor", result: &models.TransactionResult{Event: models.TransactionRejected, CorrelationID: "a"}, rejected: 1},
+		{name: "rejected by the validator", rejection: &models.Rejection{Queue: "transactions", MessageID: "a"}, invalid: 1},
+		{name: "rejection from another queue", rejection: &models.Rejection{Queue: "quotations", MessageID: "a"}, pending: 1},
+		{name: "answer to another run", result: &models.TransactionResult{Event: models.TransactionCompleted, CorrelationID: "b"}, pending: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := NewRunner(Config{Queue: "transactions"}, nil, nil)
+			step := &stepStats{}
+			r.pending["a"] = pendingMessage{sentAt: time.Now(), step: step}
+
+			if tt.result != nil {
+				body, _ := json.Marshal(tt.result)
+				if err := r.handleResult(body); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if tt.rejection != nil {
+				body, _ := json.Marshal(tt.rejection)
+				if err := r.handleRejection(body); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			if step.completed != tt.completed || step.rejected != tt.rejected || step.invalid != tt.invalid || len(r.pending) != tt.pending {
+				t.Errorf("completed %d, rejected %d, invalid %d, pending %d; want %d, %d, %d, %d",
+					step.completed, step.rejected, step.invalid, len(r.pending), tt.completed, tt.rejected, tt.invalid, tt.pending)
+			}
+		})
+	}
+}
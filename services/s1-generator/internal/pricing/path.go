@@ -0,0 +1,105 @@
+package pricing
+
+import (
+	"math"
+	"math/rand"
+)
+
+// SecondsPerYear converts elapsed seconds to the years used by annualized
+// parameters
+const SecondsPerYear = 365 * 24 * 60 * 60
+
+// Params configures a price path. Rates are annualized.
+type Params struct {
+	// Drift is the expected log return per year
+	Drift float64 `json:"drift" yaml:"drift"`
+	// Volatility is the standard deviation of log returns per year
+	Volatility float64 `json:"volatility" yaml:"volatility"`
+	// MeanReversion is the speed at which the price returns to its trend;
+	// zero gives a plain geometric Brownian motion
+	MeanReversion float64 `json:"mean_reversion" yaml:"mean_reversion"`
+	// JumpIntensity is the expected number of jumps per year
+	JumpIntensity float64 `json:"jump_intensity" yaml:"jump_intensity"`
+	// JumpMean and JumpStdDev describe the log size of each jump
+	JumpMean   float64 `json:"jump_mean" yaml:"jump_mean"`
+	JumpStdDev float64 `json:"jump_stddev" yaml:"jump_stddev"`
+}
+
+// Path simulates a price as a geometric Brownian motion with optional mean
+// reversion towards a drifting trend and Poisson-distributed jumps
+// (Merton's jump diffusion).
+type Path struct {
+	params Params
+	rng    *rand.Rand
+	// log of the current price and of the trend it reverts to
+	logPrice float64
+	logTrend float64
+}
+
+// NewPath starts a path at initial using rng for all randomness, so the
+// same seed always produces the same path
+func NewPath(initial float64, params Params, rng *rand.Rand) *Path {
+	return &Path{
+		params:   params,
+		rng:      rng,
+		logPrice: math.Log(initial),
+		logTrend: math.Log(initial),
+	}
+}
+
+// Price returns the current price
+func (p *Path) Price() float64 {
+	return math.Exp(p.logPrice)
+}
+
+// Step advances the path by dt years and returns the new price
+func (p *Path) Step(dt float64) float64 {
+	if dt <= 0 {
+		return p.Price()
+	}
+	prm := p.params
+
+	drift := (prm.Drift - prm.Volatility*prm.Volatility/2) * dt
+	p.logTrend += drift
+
+	reversion := prm.MeanReversion * (p.logTrend - p.logPrice) * dt
+	diffusion := prm.Volatility * math.Sqrt(dt) * p.rng.NormFloat64()
+	p.logPrice += drift + reversion + diffusion
+
+	for n := p.poisson(prm.JumpIntensity * dt); n > 0; n-- {
+		p.logPrice += prm.JumpMean + prm.JumpStdDev*p.rng.NormFloat64()
+	}
+
+	return p.Price()
+}
+
+// Shock moves the price and its trend by pct percent at once. pct must be
+// greater than -100, which Scenario.Validate ensures for phase shocks.
+func (p *Path) Shock(pct float64) {
+	shift := math.Log(1 + pct/100)
+	p.logPrice += shift
+	p.logTrend += shift
+}
+
+// poisson draws from a Poisson distribution using Knuth's method, which is
+// efficient for the small means of a single step
+func (p *Path) poisson(mean float64) int {
+	if mean <= 0 {
+		return 0
+	}
+	limit := math.Exp(-mean)
+	n, product := 0, p.rng.Float64()
+	for product > limit {
+		n++
+		product *= p.rng.Float64()
+	}
+	return n
+}
+
+// Quote splits a mid price into buy and sell prices separated by spread,
+// rounded outwards to four decimals so buy never exceeds sell
+func Quote(mid, spread float64) (buy, sell float64) {
+	buy = math.Floor((mid-spread/2)*10000) / 10000
+	sell = math.Ceil((mid+spread/2)*10000) / 10000
+	return buy, sell
+}
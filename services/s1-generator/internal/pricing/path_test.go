@@ -0,0 +1,141 @@
+package pricing
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func TestPathDistribution(t *testing.T) {
+	const (
+		paths   = 2000
+		steps   = 250
+		horizon = 1.0
+	)
+
+	tests := []struct {
+		name   string
+		params Params
+		// mean and variance of the log return over the horizon
+		mean, variance float64
+	}{
+		{
+			name:     "deterministic drift",
+			params:   Params{Drift: 0.05},
+			mean:     0.05,
+			variance: 0,
+		},
+		{
+			name:     "geometric Brownian motion",
+			params:   Params{Drift: 0.05, Volatility: 0.2},
+			mean:     0.05 - 0.2*0.2/2,
+			variance: 0.2 * 0.2,
+		},
+		{
+			// Ornstein-Uhlenbeck around the trend: variance settles at
+			// σ²(1-e^{-2κT})/2κ instead of growing with time
+			name:     "mean reversion",
+			params:   Params{Volatility: 0.2, MeanReversion: 10},
+			mean:     -0.2 * 0.2 / 2,
+			variance: 0.2 * 0.2 * (1 - math.Exp(-2*10*horizon)) / (2 * 10),
+		},
+		{
+			// Merton jumps only: a compound Poisson process with mean λTm
+			// and variance λT(s²+m²)
+			name:     "jumps",
+			params:   Params{JumpIntensity: 12, JumpMean: -0.01, JumpStdDev: 0.02},
+			mean:     12 * -0.01,
+			variance: 12 * (0.02*0.02 + 0.01*0.01),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rng := rand.New(rand.NewSource(1))
+			var sum, sumSquares float64
+			for i := 0; i < paths; i++ {
+				path := NewPath(5, tt.params, rng)
+				for s := 0; s < steps; s++ {
+					path.Step(horizon / steps)
+				}
+				r := math.Log(path.Price() / 5)
+				sum += r
+				sumSquares += r * r
+			}
+			mean := sum / paths
+			variance := sumSquares/paths - mean*mean
+
+			// Four standard errors of the sample mean, plus rounding
+			if tolerance := 4*math.Sqrt(tt.variance/paths) + 1e-9; math.Abs(mean-tt.mean) > tolerance {
+				t.Errorf("mean log return = %.5f, want %.5f ± %.5f", mean, tt.mean, tolerance)
+			}
+			if tolerance := 0.15*tt.variance + 1e-9; math.Abs(variance-tt.variance) > tolerance {
+				t.Errorf("variance of log return = %.5f, want %.5f ± %.5f", variance, tt.variance, tolerance)
+			}
+		})
+	}
+}
+
+func TestPathIsReproducible(t *testing.T) {
+	params := Params{Drift: 0.1, Volatility: 0.3, MeanReversion: 2, JumpIntensity: 50, JumpMean: 0.01, JumpStdDev: 0.05}
+	a := NewPath(5, params, rand.New(rand.NewSource(42)))
+	b := NewPath(5, params, rand.New(rand.NewSource(42)))
+	for i := 0; i < 100; i++ {
+		if pa, pb := a.Step(0.01), b.Step(0.01); pa != pb {
+			t.Fatalf("step %d: prices %v and %v differ for the same seed", i, pa, pb)
+		}
+	}
+}
+
+func TestPathStep(t *testing.T) {
+	tests := []struct {
+		name  string
+		shock float64
+		dt    float64
+		want  float64
+	}{
+		{name: "no time passes", dt: 0, want: 5},
+		{name: "negative time", dt: -1, want: 5},
+		{name: "shock up", shock: 10, dt: 0, want: 5.5},
+		{name: "shock down", shock: -20, dt: 0, want: 4},
+		// The trend moves with the shock, so reversion doesn't undo it
+		{name: "shock survives reversion", shock: 10, dt: 1, want: 5.5},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := NewPath(5, Params{MeanReversion: 50}, rand.New(rand.NewSource(1)))
+			if tt.shock != 0 {
+				path.Shock(tt.shock)
+			}
+			if got := path.Step(tt.dt); math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("price = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestQuote(t *testing.T) {
+	tests := []struct {
+		name      string
+		mid       float64
+		spread    float64
+		buy, sell float64
+	}{
+		{name: "exact", mid: 5, spread: 0.02, buy: 4.99, sell: 5.01},
+		{name: "rounded outwards", mid: 5.12345, spread: 0.001, buy: 5.1229, sell: 5.1240},
+		{name: "no spread", mid: 5.12345, spread: 0, buy: 5.1234, sell: 5.1235},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buy, sell := Quote(tt.mid, tt.spread)
+			if math.Abs(buy-tt.buy) > 1e-9 || math.Abs(sell-tt.sell) > 1e-9 {
+				t.Errorf("Quote = %v, %v, want %v, %v", buy, sell, tt.buy, tt.sell)
+			}
+			if buy > sell {
+				t.Errorf("buy %v exceeds sell %v", buy, sell)
+			}
+		})
+	}
+}
@@ -0,0 +1,106 @@
+package replay
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"time"
+
+	"github.com/leandroalencar/banco-dados/shared/utils"
+)
+
+// Publisher publishes a message tagged with an ID to a queue
+type Publisher interface {
+	PublishMessageWithID(queue, messageID string, message interface{}) error
+}
+
+// Options controls how a capture is replayed
+type Options struct {
+	// Speed multiplies the original pace; 1 replays in real time and zero
+	// or less publishes as fast as possible
+	Speed float64
+	// Retime shifts each message's top-level "timestamp" by the time
+	// elapsed since it was captured, so consumers don't see stale data
+	Retime bool
+}
+
+// Result counts the messages a replay published and failed to publish
+type Result struct {
+	Published int
+	Failed    int
+}
+
+// Run republishes the messages read from r with their original queue and
+// message ID. A message that fails to publish is logged and counted, and
+// the replay moves on.
+func Run(ctx context.Context, r io.Reader, publisher Publisher, opts Options) (Result, error) {
+	reader := utils.NewCaptureReader(r)
+
+	var (
+		first   time.Time
+		started time.Time
+		result  Result
+	)
+	for {
+		msg, err := reader.Next()
+		if errors.Is(err, io.EOF) {
+			return result, nil
+		}
+		if err != nil {
+			return result, err
+		}
+
+		if first.IsZero() {
+			first, started = msg.CapturedAt, time.Now()
+		}
+		if opts.Speed > 0 {
+			due := started.Add(time.Duration(float64(msg.CapturedAt.Sub(first)) / opts.Speed))
+			select {
+			case <-ctx.Done():
+				return result, ctx.Err()
+			case <-time.After(time.Until(due)):
+			}
+		} else if ctx.Err() != nil {
+			return result, ctx.Err()
+		}
+
+		body := msg.Body
+		if opts.Retime {
+			body = retime(body, time.Since(msg.CapturedAt))
+		}
+
+		if err := publisher.PublishMessageWithID(msg.Queue, msg.MessageID, body); err != nil {
+			log.Printf("Error replaying message to %s: %v", msg.Queue, err)
+			result.Failed++
+			continue
+		}
+		result.Published++
+	}
+}
+
+// retime adds shift to the body's top-level "timestamp" field, leaving
+// bodies without one unchanged
+func retime(body json.RawMessage, shift time.Duration) json.RawMessage {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(body, &fields); err != nil {
+		return body
+	}
+	raw, ok := fields["timestamp"]
+	if !ok {
+		return body
+	}
+
+	var ts time.Time
+	if err := json.Unmarshal(raw, &ts); err != nil {
+		return body
+	}
+	fields["timestamp"], _ = json.Marshal(ts.Add(shift))
+
+	retimed, err := json.Marshal(fields)
+	if err != nil {
+		return body
+	}
+	return retimed
+}
@@ -0,0 +1,50 @@
+package replay
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+// failingPublisher fails to publish to the queues in fail
+type failingPublisher struct {
+	fail map[string]bool
+}
+
+func (p *failingPublisher) PublishMessageWithID(queue, messageID string, message interface{}) error {
+	if p.fail[queue] {
+		return errors.New("broker unavailable")
+	}
+	return nil
+}
+
+func TestRunCountsFailures(t *testing.T) {
+	capture := strings.Join([]string{
+		`{"queue":"quotations","message_id":"a","captured_at":"2026-01-01T00:00:00Z","body":{}}`,
+		`{"queue":"transactions","message_id":"b","captured_at":"2026-01-01T00:00:01Z","body":{}}`,
+		`{"queue":"quotations","message_id":"c","captured_at":"2026-01-01T00:00:02Z","body":{}}`,
+	}, "\n")
+
+	tests := []struct {
+		name string
+		fail map[string]bool
+		want Result
+	}{
+		{name: "all published", want: Result{Published: 3}},
+		{name: "one queue failing", fail: map[string]bool{"transactions": true}, want: Result{Published: 2, Failed: 1}},
+		{name: "all failing", fail: map[string]bool{"quotations": true, "transactions": true}, want: Result{Failed: 3}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Run(context.Background(), strings.NewReader(capture), &failingPublisher{fail: tt.fail}, Options{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("Run() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,285 @@
+package scenario
+
+import (
+	"context"
+	"log"
+	"math"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/leandroalencar/banco-dados/services/s1-generator/internal/pricing"
+	"github.com/leandroalencar/banco-dados/shared/models"
+	"github.com/leandroalencar/banco-dados/shared/utils"
+)
+
+const (
+	QuotationsQueue   = "quotations"
+	TransactionsQueue = "transactions"
+	UsersQueue        = "users"
+)
+
+// Publisher publishes a message tagged with an ID to a queue
+type Publisher interface {
+	PublishMessageWithID(queue, messageID string, message interface{}) error
+}
+
+// Engine publishes the quotations and transactions described by a scenario
+type Engine struct {
+	scenario  *Scenario
+	publisher Publisher
+
+	mu sync.Mutex
+	// prices, transactions and user events draw from separate sources, so
+	// the sequence each produces for a seed does not depend on how the
+	// goroutines publishing them interleave
+	paths   map[string]*pricing.Path
+	txRng   *rand.Rand
+	userRng *rand.Rand
+	users   *population
+	start   time.Time
+	// number of phases entered so far, whose rate shocks were applied
+	entered int
+}
+
+// NewEngine creates an engine for a validated scenario
+func NewEngine(s *Scenario, publisher Publisher) *Engine {
+	seed := s.Seed
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+	log.Printf("Using seed %d", seed)
+
+	priceRng := rand.New(rand.NewSource(seed))
+	paths := make(map[string]*pricing.Path, len(s.Pairs))
+	for _, p := range s.Pairs {
+		paths[p.Symbol] = pricing.NewPath(p.Rate, p.Params, priceRng)
+	}
+
+	userRng := rand.New(rand.NewSource(seed + 2))
+	return &Engine{
+		scenario:  s,
+		publisher: publisher,
+		paths:     paths,
+		txRng:     rand.New(rand.NewSource(seed + 1)),
+		userRng:   userRng,
+		users:     newPopulation(userRng),
+	}
+}
+
+// Run publishes the initial users, then messages until ctx is cancelled
+func (e *Engine) Run(ctx context.Context) {
+	e.start = time.Now()
+	log.Printf("Running scenario %q", e.scenario.Name)
+	e.PublishUsers()
+
+	var wg sync.WaitGroup
+	wg.Add(3)
+	go func() {
+		defer wg.Done()
+		e.RunQuotations(ctx)
+	}()
+	go func() {
+		defer wg.Done()
+		e.loop(ctx, func(p Phase) float64 {
+			return e.scenario.Rates.Transactions * multiplier(p.TransactionsMultiplier)
+		}, e.publishTransaction)
+	}()
+	go func() {
+		defer wg.Done()
+		e.loop(ctx, func(Phase) float64 {
+			return e.scenario.Rates.Users
+		}, e.publishUserEvent)
+	}()
+	wg.Wait()
+}
+
+// PublishUsers creates the scenario's initial user population
+func (e *Engine) PublishUsers() {
+	for i := 0; i < e.scenario.Users.Count; i++ {
+		e.mu.Lock()
+		event := e.users.create()
+		e.mu.Unlock()
+
+		if err := e.publish(UsersQueue, event); err != nil {
+			log.Printf("Error publishing user event: %v", err)
+		}
+	}
+}
+
+// RunQuotations publishes only the scenario's quotations until ctx is
+// cancelled, for modes that generate transactions themselves
+func (e *Engine) RunQuotations(ctx context.Context) {
+	if e.start.IsZero() {
+		e.start = time.Now()
+	}
+	e.loop(ctx, func(p Phase) float64 {
+		return e.scenario.Rates.Quotations * multiplier(p.QuotationsMultiplier)
+	}, e.publishQuotations)
+}
+
+// NextTransaction generates a transaction following the scenario's base mix.
+// It returns false while no users exist.
+func (e *Engine) NextTransaction() (models.Transaction, bool) {
+	return e.nextTransaction(Phase{})
+}
+
+// loop calls publish at the rate given for the current phase, passing the
+// interval until the next call
+func (e *Engine) loop(ctx context.Context, rate func(Phase) float64, publish func(Phase, time.Duration)) {
+	for {
+		phase := e.currentPhase()
+		interval := time.Second
+		if r := rate(phase); r > 0 {
+			interval = time.Duration(float64(time.Second) / r)
+			publish(phase, interval)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(interval):
+		}
+	}
+}
+
+// currentPhase returns the phase for the elapsed time, applying the rate
+// shock of every phase entered since the last call
+func (e *Engine) currentPhase() Phase {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	phases := e.scenario.Phases
+	if len(phases) == 0 {
+		return Phase{}
+	}
+
+	elapsed := time.Since(e.start)
+	index := len(phases) - 1
+	var end time.Duration
+	for i, p := range phases {
+		end += time.Duration(p.Duration)
+		if elapsed < end {
+			index = i
+			break
+		}
+	}
+
+	for e.entered <= index {
+		p := phases[e.entered]
+		log.Printf("Entering phase %q", p.Name)
+		if p.RateShockPct != 0 {
+			for _, path := range e.paths {
+				path.Shock(p.RateShockPct)
+			}
+		}
+		e.entered++
+	}
+	return phases[index]
+}
+
+func (e *Engine) publishQuotations(_ Phase, interval time.Duration) {
+	// Advance prices by the nominal interval rather than the time actually
+	// slept, so a seeded run produces the same path every time
+	dt := interval.Seconds() * e.scenario.TimeScale / pricing.SecondsPerYear
+	for _, pair := range e.scenario.Pairs {
+		quotation := e.nextQuotation(pair, dt)
+		if err := e.publish(QuotationsQueue, quotation); err != nil {
+			log.Printf("Error publishing quotation: %v", err)
+		}
+	}
+}
+
+func (e *Engine) publishTransaction(phase Phase, _ time.Duration) {
+	transaction, ok := e.nextTransaction(phase)
+	if !ok {
+		return
+	}
+	if err := e.publish(TransactionsQueue, transaction); err != nil {
+		log.Printf("Error publishing transaction: %v", err)
+	}
+}
+
+func (e *Engine) publishUserEvent(Phase, time.Duration) {
+	e.mu.Lock()
+	var (
+		event models.UserEvent
+		ok    = true
+	)
+	r := e.userRng.Float64()
+	switch {
+	case r < e.scenario.Users.DeleteRatio:
+		event, ok = e.users.remove()
+	case r < e.scenario.Users.DeleteRatio+e.scenario.Users.UpdateRatio:
+		event, ok = e.users.update()
+	default:
+		event = e.users.create()
+	}
+	e.mu.Unlock()
+
+	if !ok {
+		return
+	}
+	if err := e.publish(UsersQueue, event); err != nil {
+		log.Printf("Error publishing user event: %v", err)
+	}
+}
+
+func (e *Engine) nextQuotation(pair Pair, dt float64) models.Quotation {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	mid := e.paths[pair.Symbol].Step(dt)
+	buy, sell := pricing.Quote(mid, pair.Spread*mid/pair.Rate)
+	return models.Quotation{
+		CurrencyPair:  pair.Symbol,
+		BuyPrice:      buy,
+		SellPrice:     sell,
+		Timestamp:     time.Now(),
+		LastUpdatedBy: "generator",
+	}
+}
+
+func (e *Engine) nextTransaction(phase Phase) (models.Transaction, bool) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	userID, ok := e.users.pick(e.txRng)
+	if !ok {
+		return models.Transaction{}, false
+	}
+
+	buyRatio := e.scenario.Mix.BuyRatio
+	if phase.BuyRatio != nil {
+		buyRatio = *phase.BuyRatio
+	}
+	transactionType := models.Sell
+	if e.txRng.Float64() < buyRatio {
+		transactionType = models.Buy
+	}
+
+	mix := e.scenario.Mix
+	amount := mix.MinAmount + e.txRng.Float64()*(mix.MaxAmount-mix.MinAmount)
+
+	return models.Transaction{
+		UserID:       userID,
+		Type:         transactionType,
+		CurrencyPair: e.scenario.Pairs[e.txRng.Intn(len(e.scenario.Pairs))].Symbol,
+		Amount:       math.Round(amount*100) / 100,
+		Status:       "PENDING",
+		Timestamp:    time.Now(),
+	}, true
+}
+
+// publish sends message to queue with a new message ID, which consumers use
+// to recognize redeliveries
+func (e *Engine) publish(queue string, message interface{}) error {
+	return e.publisher.PublishMessageWithID(queue, utils.NewMessageID(), message)
+}
+
+func multiplier(m float64) float64 {
+	if m == 0 {
+		return 1
+	}
+	return m
+}
@@ -0,0 +1,109 @@
+package scenario
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/leandroalencar/banco-dados/shared/models"
+)
+
+var (
+	firstNames = []string{"Ana", "Bruno", "Carla", "Diego", "Eduarda", "Felipe", "Gabriela", "Henrique", "Isabela", "João", "Larissa", "Lucas", "Mariana", "Mateus", "Natália", "Pedro", "Rafaela", "Thiago", "Vitória", "Leandro"}
+	lastNames  = []string{"Silva", "Santos", "Oliveira", "Souza", "Lima", "Pereira", "Ferreira", "Costa", "Rodrigues", "Almeida", "Nascimento", "Carvalho", "Gomes", "Martins", "Rocha", "Dias", "Alencar"}
+)
+
+// population tracks the users that currently exist, so transactions only
+// reference users whose creation was published and not yet deleted.
+// IDs are sequential numbers behind idPrefix. s2 keys generated users on
+// these IDs apart from its own numeric ones, which they must not look like.
+// rng draws the user events; transactions pick users with their own.
+type population struct {
+	rng    *rand.Rand
+	users  []models.User
+	nextID int
+}
+
+// idPrefix namespaces the IDs of generated users
+const idPrefix = "gen-"
+
+func newPopulation(rng *rand.Rand) *population {
+	return &population{
+		rng:    rng,
+		nextID: 1,
+	}
+}
+
+// create adds a new user with a random name and opening balance
+func (p *population) create() models.UserEvent {
+	id := idPrefix + strconv.Itoa(p.nextID)
+	p.nextID++
+
+	first := firstNames[p.rng.Intn(len(firstNames))]
+	last := lastNames[p.rng.Intn(len(lastNames))]
+	now := time.Now()
+	user := models.User{
+		ID:        id,
+		Name:      first + " " + last,
+		Email:     email(first, last, id),
+		Balance:   math.Round((1000+p.rng.Float64()*49000)*100) / 100,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+
+	p.users = append(p.users, user)
+	return models.UserEvent{Event: models.UserCreated, User: user, OccurredAt: now}
+}
+
+// update changes the surname and email of a random user, as after a
+// marriage
+func (p *population) update() (models.UserEvent, bool) {
+	if len(p.users) == 0 {
+		return models.UserEvent{}, false
+	}
+	i := p.rng.Intn(len(p.users))
+	user := &p.users[i]
+
+	first := strings.Fields(user.Name)[0]
+	last := lastNames[p.rng.Intn(len(lastNames))]
+	user.Name = first + " " + last
+	user.Email = email(first, last, user.ID)
+	user.UpdatedAt = time.Now()
+
+	return models.UserEvent{Event: models.UserUpdated, User: *user, OccurredAt: user.UpdatedAt}, true
+}
+
+// remove deletes a random user
+func (p *population) remove() (models.UserEvent, bool) {
+	if len(p.users) == 0 {
+		return models.UserEvent{}, false
+	}
+	i := p.rng.Intn(len(p.users))
+	user := p.users[i]
+
+	last := len(p.users) - 1
+	p.users[i] = p.users[last]
+	p.users = p.users[:last]
+
+	return models.UserEvent{Event: models.UserDeleted, User: models.User{ID: user.ID}, OccurredAt: time.Now()}, true
+}
+
+// pick returns the ID of a random existing user, drawn from rng
+func (p *population) pick(rng *rand.Rand) (string, bool) {
+	if len(p.users) == 0 {
+		return "", false
+	}
+	return p.users[rng.Intn(len(p.users))].ID, true
+}
+
+func email(first, last, id string) string {
+	return fmt.Sprintf("%s.%s%s@example.com", strings.ToLower(ascii(first)), strings.ToLower(ascii(last)), strings.TrimPrefix(id, idPrefix))
+}
+
+// ascii drops the accents used in the name lists
+func ascii(s string) string {
+	return strings.NewReplacer("ã", "a", "á", "a", "é", "e", "í", "i", "ó", "o", "ú", "u", "ç", "c").Replace(s)
+}
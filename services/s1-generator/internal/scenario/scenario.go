@@ -0,0 +1,225 @@
+package scenario
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/leandroalencar/banco-dados/services/s1-generator/internal/pricing"
+	"gopkg.in/yaml.v3"
+)
+
+// Duration is a time.Duration written as a string such as "90s" or "5m"
+// in scenario files
+type Duration time.Duration
+
+func (d *Duration) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+	return d.parse(s)
+}
+
+func (d *Duration) UnmarshalYAML(node *yaml.Node) error {
+	return d.parse(node.Value)
+}
+
+func (d *Duration) parse(s string) error {
+	parsed, err := time.ParseDuration(s)
+	if err != nil {
+		return err
+	}
+	*d = Duration(parsed)
+	return nil
+}
+
+// Scenario describes the traffic the generator produces
+type Scenario struct {
+	Name        string  `json:"name" yaml:"name"`
+	Description string  `json:"description" yaml:"description"`
+	Pairs       []Pair  `json:"pairs" yaml:"pairs"`
+	Users       Users   `json:"users" yaml:"users"`
+	Mix         Mix     `json:"transactions" yaml:"transactions"`
+	Rates       Rates   `json:"rates" yaml:"rates"`
+	Phases      []Phase `json:"phases" yaml:"phases"`
+	// Seed makes prices and transactions reproducible; zero picks a seed
+	// from the clock
+	Seed int64 `json:"seed" yaml:"seed"`
+	// TimeScale is the number of simulated seconds per real second used to
+	// advance price paths
+	TimeScale float64 `json:"time_scale" yaml:"time_scale"`
+}
+
+// Pair is a currency pair quoted by the scenario
+type Pair struct {
+	Symbol string `json:"symbol" yaml:"symbol"`
+	// Rate is the initial mid price
+	Rate float64 `json:"rate" yaml:"rate"`
+	// Spread is the difference between sell and buy price at Rate; it
+	// scales with the price so it stays proportional
+	Spread float64 `json:"spread" yaml:"spread"`
+	// Price path parameters
+	pricing.Params `yaml:",inline"`
+}
+
+// Users describes the simulated user population
+type Users struct {
+	// Count is the number of users created when the scenario starts
+	Count int `json:"count" yaml:"count"`
+	// UpdateRatio and DeleteRatio are the fractions of lifecycle events
+	// that update or delete a user; the rest create new users
+	UpdateRatio float64 `json:"update_ratio" yaml:"update_ratio"`
+	DeleteRatio float64 `json:"delete_ratio" yaml:"delete_ratio"`
+}
+
+// Mix describes the transactions requested by users
+type Mix struct {
+	// BuyRatio is the fraction of transactions that are purchases
+	BuyRatio  float64 `json:"buy_ratio" yaml:"buy_ratio"`
+	MinAmount float64 `json:"min_amount" yaml:"min_amount"`
+	MaxAmount float64 `json:"max_amount" yaml:"max_amount"`
+}
+
+// Rates are messages published per second
+type Rates struct {
+	Quotations   float64 `json:"quotations_per_second" yaml:"quotations_per_second"`
+	Transactions float64 `json:"transactions_per_second" yaml:"transactions_per_second"`
+	// Users is the rate of lifecycle events after the initial population
+	Users float64 `json:"users_per_second" yaml:"users_per_second"`
+}
+
+// Phase changes the scenario for a period of time. Phases run in order and
+// the last one lasts until the generator is stopped.
+type Phase struct {
+	Name     string   `json:"name" yaml:"name"`
+	Duration Duration `json:"duration" yaml:"duration"`
+	// RateShockPct moves every pair's rate by this percentage when the
+	// phase starts. It must be greater than -100, as rates stay positive.
+	RateShockPct float64 `json:"rate_shock_pct" yaml:"rate_shock_pct"`
+	// Multipliers scale the base message rates during the phase; zero
+	// keeps the base rate
+	QuotationsMultiplier   float64 `json:"quotations_multiplier" yaml:"quotations_multiplier"`
+	TransactionsMultiplier float64 `json:"transactions_multiplier" yaml:"transactions_multiplier"`
+	// BuyRatio overrides the transaction mix when set
+	BuyRatio *float64 `json:"buy_ratio" yaml:"buy_ratio"`
+}
+
+// Default reproduces the generator's original behaviour: one USD/BRL quote
+// and one transaction every five seconds
+func Default() *Scenario {
+	return &Scenario{
+		Name: "default",
+		Pairs: []Pair{{
+			Symbol: "USD/BRL",
+			Rate:   5.00,
+			Spread: 0.10,
+			Params: pricing.Params{Volatility: 0.15},
+		}},
+		Users:     Users{Count: 100},
+		Mix:       Mix{BuyRatio: 0.5, MinAmount: 1, MaxAmount: 1000},
+		Rates:     Rates{Quotations: 0.2, Transactions: 0.2},
+		TimeScale: 1,
+	}
+}
+
+// Load reads a scenario from a YAML or JSON file. A bare name is looked up
+// in dir with a .yaml, .yml or .json extension.
+func Load(nameOrPath, dir string) (*Scenario, error) {
+	path, err := resolve(nameOrPath, dir)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	s := Default()
+	s.Phases = nil
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		err = json.Unmarshal(data, s)
+	} else {
+		err = yaml.Unmarshal(data, s)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error parsing scenario %s: %w", path, err)
+	}
+
+	if err := s.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid scenario %s: %w", path, err)
+	}
+	return s, nil
+}
+
+func resolve(nameOrPath, dir string) (string, error) {
+	if _, err := os.Stat(nameOrPath); err == nil {
+		return nameOrPath, nil
+	}
+	for _, ext := range []string{".yaml", ".yml", ".json"} {
+		path := filepath.Join(dir, nameOrPath+ext)
+		if _, err := os.Stat(path); err == nil {
+			return path, nil
+		}
+	}
+	return "", fmt.Errorf("scenario %q not found in %s", nameOrPath, dir)
+}
+
+// Validate checks that the scenario can be run
+func (s *Scenario) Validate() error {
+	if len(s.Pairs) == 0 {
+		return errors.New("at least one pair is required")
+	}
+	for _, p := range s.Pairs {
+		if p.Symbol == "" {
+			return errors.New("pair symbol is required")
+		}
+		if p.Rate <= 0 {
+			return fmt.Errorf("pair %s: rate must be positive", p.Symbol)
+		}
+		if p.Spread < 0 || p.Spread >= p.Rate {
+			return fmt.Errorf("pair %s: spread must be between 0 and the rate", p.Symbol)
+		}
+		if p.Volatility < 0 || p.MeanReversion < 0 || p.JumpIntensity < 0 || p.JumpStdDev < 0 {
+			return fmt.Errorf("pair %s: volatility, mean_reversion and jump parameters must not be negative", p.Symbol)
+		}
+	}
+	if s.Users.Count <= 0 {
+		return errors.New("users.count must be positive")
+	}
+	if s.Users.UpdateRatio < 0 || s.Users.DeleteRatio < 0 || s.Users.UpdateRatio+s.Users.DeleteRatio > 1 {
+		return errors.New("users.update_ratio and users.delete_ratio must be non-negative and sum to at most 1")
+	}
+	if s.Mix.BuyRatio < 0 || s.Mix.BuyRatio > 1 {
+		return errors.New("transactions.buy_ratio must be between 0 and 1")
+	}
+	if s.Mix.MinAmount <= 0 || s.Mix.MaxAmount < s.Mix.MinAmount {
+		return errors.New("transactions amounts must satisfy 0 < min_amount <= max_amount")
+	}
+	if s.Rates.Quotations < 0 || s.Rates.Transactions < 0 || s.Rates.Users < 0 {
+		return errors.New("rates must not be negative")
+	}
+	if s.TimeScale <= 0 {
+		return errors.New("time_scale must be positive")
+	}
+	for i, p := range s.Phases {
+		if p.Duration <= 0 && i < len(s.Phases)-1 {
+			return fmt.Errorf("phase %q: only the last phase may omit its duration", p.Name)
+		}
+		if p.QuotationsMultiplier < 0 || p.TransactionsMultiplier < 0 {
+			return fmt.Errorf("phase %q: multipliers must not be negative", p.Name)
+		}
+		if p.RateShockPct <= -100 {
+			return fmt.Errorf("phase %q: rate_shock_pct must be greater than -100", p.Name)
+		}
+		if p.BuyRatio != nil && (*p.BuyRatio < 0 || *p.BuyRatio > 1) {
+			return fmt.Errorf("phase %q: buy_ratio must be between 0 and 1", p.Name)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,166 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/leandroalencar/banco-dados/services/s2-processor/internal/domain/models"
+	"github.com/leandroalencar/banco-dados/services/s2-processor/internal/domain/repositories"
+	"github.com/leandroalencar/banco-dados/services/s2-processor/internal/domain/services"
+	"github.com/leandroalencar/banco-dados/services/s2-processor/internal/dto"
+)
+
+// createAccount opens an account for the authenticated user
+func createAccount(accountService *services.AccountService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var request dto.CreateAccountRequest
+		if err := c.ShouldBindJSON(&request); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		account := request.ToModel()
+		if err := accountService.Open(c.Request.Context(), currentUserID(c), account); err != nil {
+			respondAccountError(c, err)
+			return
+		}
+
+		c.JSON(http.StatusCreated, dto.NewAccount(account))
+	}
+}
+
+// listAccounts lists the authenticated user's open accounts and their total
+// balance in each currency
+func listAccounts(accountService *services.AccountService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		accounts, err := accountService.List(c.Request.Context(), currentUserID(c))
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		response := make([]dto.Account, len(accounts))
+		balances := make(map[string]float64)
+		for i := range accounts {
+			response[i] = dto.NewAccount(&accounts[i])
+			balances[accounts[i].Currency] += accounts[i].Balance
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"accounts": response,
+			"balances": balances,
+		})
+	}
+}
+
+// getAccount returns one of the authenticated user's accounts
+func getAccount(accountService *services.AccountService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, ok := idParam(c, "account")
+		if !ok {
+			return
+		}
+
+		account, err := accountService.Get(c.Request.Context(), currentUserID(c), id)
+		if err != nil {
+			respondAccountError(c, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, dto.NewAccount(account))
+	}
+}
+
+// updateAccount changes the name, type or description of an account
+func updateAccount(accountService *services.AccountService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, ok := idParam(c, "account")
+		if !ok {
+			return
+		}
+
+		var request dto.UpdateAccountRequest
+		if err := c.ShouldBindJSON(&request); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		changes := services.AccountChanges{
+			Name:        request.Name,
+			Description: request.Description,
+		}
+		if request.Type != nil {
+			accountType := models.AccountType(*request.Type)
+			changes.Type = &accountType
+		}
+
+		account, err := accountService.Update(c.Request.Context(), currentUserID(c), id, changes)
+		if err != nil {
+			respondAccountError(c, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, dto.NewAccount(account))
+	}
+}
+
+// deleteAccount closes an account, which must be empty
+func deleteAccount(accountService *services.AccountService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, ok := idParam(c, "account")
+		if !ok {
+			return
+		}
+
+		if err := accountService.Close(c.Request.Context(), currentUserID(c), id); err != nil {
+			respondAccountError(c, err)
+			return
+		}
+
+		c.Status(http.StatusNoContent)
+	}
+}
+
+// idParam parses the :id path parameter, replying 400 if it is not a valid
+// ID of what
+func idParam(c *gin.Context, what string) (uint, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid " + what + " id"})
+		return 0, false
+	}
+	return uint(id), true
+}
+
+// monthQuery parses the optional month query parameter (YYYY-MM), replying
+// 400 if it is malformed. It defaults to the current month.
+func monthQuery(c *gin.Context) (time.Time, bool) {
+	v := c.Query("month")
+	if v == "" {
+		return time.Now(), true
+	}
+	month, err := time.Parse("2006-01", v)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid month, expected YYYY-MM"})
+		return time.Time{}, false
+	}
+	return month, true
+}
+
+// respondAccountError maps account service errors to HTTP statuses
+func respondAccountError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, repositories.ErrAccountNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, repositories.ErrAccountNotEmpty):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrAccountNameRequired),
+		errors.Is(err, services.ErrInvalidAccountType),
+		errors.Is(err, services.ErrInvalidCurrency):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"net/http"
+	"strconv"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestAccounts(t *testing.T) {
+	tests := []struct {
+		name  string
+		body  gin.H
+		owner bool
+		want  int
+	}{
+		{name: "own account", body: gin.H{"name": "Checking", "type": "checking"}, owner: true, want: http.StatusOK},
+		{name: "another user's account", body: gin.H{"name": "Checking", "type": "checking"}, want: http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			api := newTestAPI()
+			ana := api.register(t, "ana@example.com")
+			bia := api.register(t, "bia@example.com")
+
+			var account struct{ ID uint }
+			if code := api.do(t, http.MethodPost, "/accounts", ana, tt.body, &account); code != http.StatusCreated {
+				t.Fatalf("creating account: status %d", code)
+			}
+
+			token := bia
+			if tt.owner {
+				token = ana
+			}
+			path := "/accounts/" + strconv.FormatUint(uint64(account.ID), 10)
+			if code := api.do(t, http.MethodGet, path, token, nil, nil); code != tt.want {
+				t.Errorf("status = %d, want %d", code, tt.want)
+			}
+		})
+	}
+}
+
+func TestCreateAccountValidation(t *testing.T) {
+	tests := []struct {
+		name string
+		body gin.H
+		want int
+	}{
+		{name: "valid", body: gin.H{"name": "Savings", "type": "savings", "currency": "usd"}, want: http.StatusCreated},
+		{name: "unknown type", body: gin.H{"name": "Savings", "type": "crypto"}, want: http.StatusBadRequest},
+		{name: "missing name", body: gin.H{"type": "savings"}, want: http.StatusBadRequest},
+		{name: "bad currency", body: gin.H{"name": "Savings", "type": "savings", "currency": "dollar"}, want: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			api := newTestAPI()
+			token := api.register(t, "ana@example.com")
+			if code := api.do(t, http.MethodPost, "/accounts", token, tt.body, nil); code != tt.want {
+				t.Errorf("status = %d, want %d", code, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,38 @@
+package main
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/leandroalencar/banco-dados/services/s2-processor/internal/domain/services"
+)
+
+// userIDKey is where requireAuth stores the authenticated user's ID
+const userIDKey = "user_id"
+
+// requireAuth rejects requests without a valid "Authorization: Bearer"
+// token issued by userService
+func requireAuth(userService *services.UserService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		token, ok := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer ")
+		if !ok || token == "" {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "missing bearer token"})
+			return
+		}
+
+		userID, err := userService.Authenticate(token)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+			return
+		}
+
+		c.Set(userIDKey, userID)
+		c.Next()
+	}
+}
+
+// currentUserID returns the user authenticated by requireAuth
+func currentUserID(c *gin.Context) uint {
+	return c.GetUint(userIDKey)
+}
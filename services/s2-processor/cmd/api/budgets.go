@@ -0,0 +1,150 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/leandroalencar/banco-dados/services/s2-processor/internal/domain/repositories"
+	"github.com/leandroalencar/banco-dados/services/s2-processor/internal/domain/services"
+	"github.com/leandroalencar/banco-dados/services/s2-processor/internal/dto"
+)
+
+// createBudget adds a monthly budget of the authenticated user
+func createBudget(budgetService *services.BudgetService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var request dto.BudgetRequest
+		if err := c.ShouldBindJSON(&request); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		budget := request.ToModel()
+		if err := budgetService.Create(c.Request.Context(), currentUserID(c), budget); err != nil {
+			respondBudgetError(c, err)
+			return
+		}
+
+		c.JSON(http.StatusCreated, dto.NewBudget(budget))
+	}
+}
+
+// listBudgets lists the authenticated user's budgets
+func listBudgets(budgetService *services.BudgetService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		budgets, err := budgetService.List(c.Request.Context(), currentUserID(c))
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		response := make([]dto.Budget, len(budgets))
+		for i := range budgets {
+			response[i] = dto.NewBudget(&budgets[i])
+		}
+		c.JSON(http.StatusOK, gin.H{"budgets": response})
+	}
+}
+
+// getBudget returns one of the authenticated user's budgets
+func getBudget(budgetService *services.BudgetService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, ok := idParam(c, "budget")
+		if !ok {
+			return
+		}
+
+		budget, err := budgetService.Get(c.Request.Context(), currentUserID(c), id)
+		if err != nil {
+			respondBudgetError(c, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, dto.NewBudget(budget))
+	}
+}
+
+// updateBudget changes the limit or thresholds of one of the authenticated
+// user's budgets
+func updateBudget(budgetService *services.BudgetService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, ok := idParam(c, "budget")
+		if !ok {
+			return
+		}
+
+		var request dto.UpdateBudgetRequest
+		if err := c.ShouldBindJSON(&request); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		budget, err := budgetService.Update(c.Request.Context(), currentUserID(c), id, services.BudgetChanges{
+			Limit:      request.Limit,
+			Thresholds: request.Thresholds,
+		})
+		if err != nil {
+			respondBudgetError(c, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, dto.NewBudget(budget))
+	}
+}
+
+// deleteBudget removes one of the authenticated user's budgets
+func deleteBudget(budgetService *services.BudgetService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, ok := idParam(c, "budget")
+		if !ok {
+			return
+		}
+
+		if err := budgetService.Delete(c.Request.Context(), currentUserID(c), id); err != nil {
+			respondBudgetError(c, err)
+			return
+		}
+
+		c.Status(http.StatusNoContent)
+	}
+}
+
+// getBudgetStatus returns the spending against one of the authenticated
+// user's budgets. The optional month (YYYY-MM, UTC) defaults to the current
+// one.
+func getBudgetStatus(budgetService *services.BudgetService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, ok := idParam(c, "budget")
+		if !ok {
+			return
+		}
+
+		month, ok := monthQuery(c)
+		if !ok {
+			return
+		}
+
+		status, err := budgetService.Status(c.Request.Context(), currentUserID(c), id, month)
+		if err != nil {
+			respondBudgetError(c, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, dto.NewBudgetStatus(status))
+	}
+}
+
+// respondBudgetError maps budget service errors to HTTP statuses
+func respondBudgetError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, repositories.ErrBudgetNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, repositories.ErrCategoryNotFound),
+		errors.Is(err, services.ErrInvalidAmount),
+		errors.Is(err, services.ErrInvalidCurrency),
+		errors.Is(err, services.ErrInvalidThreshold):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
@@ -0,0 +1,125 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/leandroalencar/banco-dados/services/s2-processor/internal/domain/repositories"
+	"github.com/leandroalencar/banco-dados/services/s2-processor/internal/domain/services"
+	"github.com/leandroalencar/banco-dados/services/s2-processor/internal/dto"
+)
+
+// createCategory adds a category of the authenticated user
+func createCategory(categoryService *services.CategoryService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var request dto.CategoryRequest
+		if err := c.ShouldBindJSON(&request); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		category := request.ToModel()
+		if err := categoryService.Create(c.Request.Context(), currentUserID(c), category); err != nil {
+			respondCategoryError(c, err)
+			return
+		}
+
+		c.JSON(http.StatusCreated, dto.NewCategory(category))
+	}
+}
+
+// listCategories lists the system categories and the authenticated user's
+func listCategories(categoryService *services.CategoryService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		categories, err := categoryService.List(c.Request.Context(), currentUserID(c))
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		response := make([]dto.Category, len(categories))
+		for i := range categories {
+			response[i] = dto.NewCategory(&categories[i])
+		}
+		c.JSON(http.StatusOK, gin.H{"categories": response})
+	}
+}
+
+// getCategory returns a category the authenticated user can use
+func getCategory(categoryService *services.CategoryService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, ok := idParam(c, "category")
+		if !ok {
+			return
+		}
+
+		category, err := categoryService.Get(c.Request.Context(), currentUserID(c), id)
+		if err != nil {
+			respondCategoryError(c, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, dto.NewCategory(category))
+	}
+}
+
+// updateCategory renames or moves one of the authenticated user's categories
+func updateCategory(categoryService *services.CategoryService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, ok := idParam(c, "category")
+		if !ok {
+			return
+		}
+
+		var request dto.UpdateCategoryRequest
+		if err := c.ShouldBindJSON(&request); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		category, err := categoryService.Update(c.Request.Context(), currentUserID(c), id, services.CategoryChanges{
+			Name:     request.Name,
+			ParentID: request.ParentID,
+		})
+		if err != nil {
+			respondCategoryError(c, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, dto.NewCategory(category))
+	}
+}
+
+// deleteCategory removes one of the authenticated user's categories
+func deleteCategory(categoryService *services.CategoryService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, ok := idParam(c, "category")
+		if !ok {
+			return
+		}
+
+		if err := categoryService.Delete(c.Request.Context(), currentUserID(c), id); err != nil {
+			respondCategoryError(c, err)
+			return
+		}
+
+		c.Status(http.StatusNoContent)
+	}
+}
+
+// respondCategoryError maps category service errors to HTTP statuses
+func respondCategoryError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, repositories.ErrCategoryNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrCategoryReadOnly):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case errors.Is(err, repositories.ErrCategoryHasChildren):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrCategoryNameRequired), errors.Is(err, services.ErrCategoryCycle):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
@@ -0,0 +1,121 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/leandroalencar/banco-dados/services/s2-processor/internal/domain/repositories"
+	"github.com/leandroalencar/banco-dados/services/s2-processor/internal/domain/services"
+	"github.com/leandroalencar/banco-dados/services/s2-processor/internal/dto"
+)
+
+// createCategorizationRule adds a rule of the authenticated user
+func createCategorizationRule(categorizer *services.CategorizationService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var request dto.CategorizationRuleRequest
+		if err := c.ShouldBindJSON(&request); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		rule := request.ToModel()
+		if err := categorizer.CreateRule(c.Request.Context(), currentUserID(c), rule); err != nil {
+			respondRuleError(c, err)
+			return
+		}
+
+		c.JSON(http.StatusCreated, dto.NewCategorizationRule(rule))
+	}
+}
+
+// listCategorizationRules lists the authenticated user's rules in the order
+// they are tried
+func listCategorizationRules(categorizer *services.CategorizationService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		rules, err := categorizer.ListRules(c.Request.Context(), currentUserID(c))
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		response := make([]dto.CategorizationRule, len(rules))
+		for i := range rules {
+			response[i] = dto.NewCategorizationRule(&rules[i])
+		}
+		c.JSON(http.StatusOK, gin.H{"rules": response})
+	}
+}
+
+// replaceCategorizationRule replaces one of the authenticated user's rules
+func replaceCategorizationRule(categorizer *services.CategorizationService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, ok := idParam(c, "rule")
+		if !ok {
+			return
+		}
+
+		var request dto.CategorizationRuleRequest
+		if err := c.ShouldBindJSON(&request); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		rule := request.ToModel()
+		if err := categorizer.ReplaceRule(c.Request.Context(), currentUserID(c), id, rule); err != nil {
+			respondRuleError(c, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, dto.NewCategorizationRule(rule))
+	}
+}
+
+// deleteCategorizationRule removes one of the authenticated user's rules
+func deleteCategorizationRule(categorizer *services.CategorizationService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, ok := idParam(c, "rule")
+		if !ok {
+			return
+		}
+
+		if err := categorizer.DeleteRule(c.Request.Context(), currentUserID(c), id); err != nil {
+			respondRuleError(c, err)
+			return
+		}
+
+		c.Status(http.StatusNoContent)
+	}
+}
+
+// applyCategorizationRules runs the authenticated user's rules over their
+// existing transactions. With ?overwrite=true, transactions that already
+// have a category are recategorized too.
+func applyCategorizationRules(categorizer *services.CategorizationService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		overwrite := c.Query("overwrite") == "true"
+
+		changed, err := categorizer.Apply(c.Request.Context(), currentUserID(c), overwrite)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error(), "changed": changed})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"changed": changed})
+	}
+}
+
+// respondRuleError maps categorization service errors to HTTP statuses
+func respondRuleError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, repositories.ErrRuleNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, repositories.ErrCategoryNotFound),
+		errors.Is(err, repositories.ErrAccountNotFound),
+		errors.Is(err, services.ErrEmptyRule),
+		errors.Is(err, services.ErrInvalidRule):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"context"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/leandroalencar/banco-dados/services/s2-processor/internal/domain/models"
+	"github.com/leandroalencar/banco-dados/services/s2-processor/internal/domain/services"
+)
+
+// exportTransactions streams the authenticated user's transactions and
+// trades as a file download. format is csv (the default) or ofx; type,
+// category_id, start_date and end_date filter them. Dates are RFC 3339 or
+// YYYY-MM-DD, and a YYYY-MM-DD end_date includes that whole day.
+func exportTransactions(exportService *services.ExportService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		format := c.DefaultQuery("format", "csv")
+		var contentType string
+		var export func(context.Context, uint, map[string]interface{}, io.Writer) error
+		switch format {
+		case "csv":
+			contentType, export = "text/csv; charset=utf-8", exportService.CSV
+		case "ofx":
+			contentType, export = "application/x-ofx", exportService.OFX
+		default:
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid format, expected csv or ofx"})
+			return
+		}
+		filters, ok := exportFilters(c)
+		if !ok {
+			return
+		}
+
+		c.Header("Content-Type", contentType)
+		c.Header("Content-Disposition", `attachment; filename="transactions.`+format+`"`)
+		c.Status(http.StatusOK)
+
+		// The status is sent with the first write, so later errors can
+		// only cut the download short
+		if err := export(c.Request.Context(), currentUserID(c), filters, c.Writer); err != nil {
+			log.Printf("Error exporting transactions of user %d: %v", currentUserID(c), err)
+		}
+	}
+}
+
+// exportFilters parses the export filters, responding with 400 Bad Request
+// if one is invalid
+func exportFilters(c *gin.Context) (map[string]interface{}, bool) {
+	filters := make(map[string]interface{})
+
+	switch v := c.Query("type"); v {
+	case "":
+	case string(models.Income), string(models.Expense), string(models.Transfer), string(models.Exchange), services.TradeFilter:
+		filters["type"] = v
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid type, expected income, expense, transfer, exchange or trade"})
+		return nil, false
+	}
+
+	if v := c.Query("category_id"); v != "" {
+		id, err := strconv.ParseUint(v, 10, 64)
+		if err != nil || id == 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid category_id"})
+			return nil, false
+		}
+		filters["category_id"] = uint(id)
+	}
+
+	for _, key := range []string{"start_date", "end_date"} {
+		v := c.Query(key)
+		if v == "" {
+			continue
+		}
+		date, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			day, dayErr := time.Parse(time.DateOnly, v)
+			if dayErr != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "invalid " + key + ", expected RFC 3339 or YYYY-MM-DD"})
+				return nil, false
+			}
+			date = day
+			if key == "end_date" {
+				date = day.AddDate(0, 0, 1).Add(-time.Microsecond)
+			}
+		}
+		filters[key] = date
+	}
+
+	return filters, true
+}
@@ -2,13 +2,21 @@
 package main
 
 import (
+	"context"
+	"encoding/base64"
 	"log"
 	"net/http"
 	"os"
+	"strconv"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/joho/godotenv"
-	"github.com/leandroalencar/banco-dados/shared/models"
+	"github.com/leandroalencar/banco-dados/services/s2-processor/internal/domain/repositories"
+	"github.com/leandroalencar/banco-dados/services/s2-processor/internal/domain/services"
+	"github.com/leandroalencar/banco-dados/services/s2-processor/internal/dto"
+	"github.com/leandroalencar/banco-dados/services/s2-processor/internal/infra/database"
+	"github.com/leandroalencar/banco-dados/services/s2-processor/internal/infra/database/migrations"
 	"github.com/leandroalencar/banco-dados/shared/utils"
 )
 
@@ -25,6 +33,126 @@ func main() {
 		log.Fatalf("Failed to connect to RabbitMQ: %v", err)
 	}
 	defer rabbitmq.Close()
+	if err := rabbitmq.EnableCaptureFromEnv(); err != nil {
+		log.Fatalf("Failed to enable capture: %v", err)
+	}
+
+	// Initialize PostgreSQL
+	db, err := database.NewPostgresConnection()
+	if err != nil {
+		log.Fatalf("Failed to connect to PostgreSQL: %v", err)
+	}
+	migrator, err := migrations.NewMigrator(db)
+	if err != nil {
+		log.Fatalf("Failed to load migrations: %v", err)
+	}
+	if err := migrator.Check(context.Background()); err != nil {
+		log.Fatalf("Refusing to start: %v (run the migrate command)", err)
+	}
+
+	// Writes that must commit together run in a unit of work
+	uow := repositories.NewPostgresUnitOfWork(db)
+
+	// Relay events written to the outbox to RabbitMQ
+	outboxRepo := repositories.NewPostgresOutboxRepository(db)
+	relay := services.NewOutboxRelay(outboxRepo, rabbitmq, time.Second, 100)
+	go relay.Run(context.Background())
+
+	// Initialize MongoDB
+	mongoDB, err := database.ConnectMongoDB()
+	if err != nil {
+		log.Fatalf("Failed to connect to MongoDB: %v", err)
+	}
+	currencyRepo := repositories.NewMongoCurrencyRepository(mongoDB)
+	if err := currencyRepo.EnsureIndexes(context.Background()); err != nil {
+		log.Fatalf("Failed to create currency indexes: %v", err)
+	}
+
+	// Store quotations validated by s3
+	quotationProcessor := services.NewQuotationProcessor(currencyRepo, rabbitmq)
+	if err := rabbitmq.ConsumeWithAck("quotations.validated", quotationProcessor.Handle); err != nil {
+		log.Fatalf("Failed to consume quotations: %v", err)
+	}
+
+	// Initialize Cassandra
+	cassandra, err := database.NewCassandraSession()
+	if err != nil {
+		log.Fatalf("Failed to connect to Cassandra: %v", err)
+	}
+	defer cassandra.Close()
+	historyRepo := repositories.NewCassandraTransactionHistoryRepository(cassandra)
+
+	// Record committed trades in the transaction history
+	historyRecorder := services.NewHistoryRecorder(historyRepo)
+	if err := rabbitmq.ConsumeWithAck(services.TransactionHistoryQueue, historyRecorder.Handle); err != nil {
+		log.Fatalf("Failed to consume trade history: %v", err)
+	}
+
+	// Execute transaction requests validated by s3, once per message
+	userRepo := repositories.NewPostgresUserRepository(db)
+	accountRepo := repositories.NewPostgresAccountRepository(db)
+	tradeRepo := repositories.NewPostgresTradeRepository(db)
+	transactionProcessor := services.NewTransactionProcessor(
+		userRepo,
+		accountRepo,
+		currencyRepo,
+		tradeRepo,
+		outboxRepo,
+	)
+	inboxRepo := repositories.NewPostgresInboxRepository(db)
+	if err := rabbitmq.ConsumeWithAck("transactions.validated", services.Deduplicate(inboxRepo, transactionProcessor.Handle)); err != nil {
+		log.Fatalf("Failed to consume transactions: %v", err)
+	}
+
+	// Apply user lifecycle events from the generator, once per message
+	userEventProcessor := services.NewUserEventProcessor(userRepo)
+	if err := rabbitmq.ConsumeWithAck(services.UsersQueue, services.Deduplicate(inboxRepo, userEventProcessor.Handle)); err != nil {
+		log.Fatalf("Failed to consume user events: %v", err)
+	}
+
+	// Users authenticate with tokens issued by the user service
+	jwtSecret := os.Getenv("JWT_SECRET")
+	if jwtSecret == "" {
+		log.Fatal("JWT_SECRET must be set")
+	}
+	jwtExpiration := 24 * time.Hour
+	if v := os.Getenv("JWT_EXPIRATION"); v != "" {
+		if jwtExpiration, err = time.ParseDuration(v); err != nil {
+			log.Fatalf("Invalid JWT_EXPIRATION: %v", err)
+		}
+	}
+	userService := services.NewUserService(userRepo, jwtSecret, jwtExpiration)
+	accountService := services.NewAccountService(accountRepo)
+	transferRepo := repositories.NewPostgresTransferRepository(db)
+	transferService := services.NewTransferService(accountRepo, currencyRepo, transferRepo)
+	categoryRepo := repositories.NewPostgresCategoryRepository(db)
+	categoryService := services.NewCategoryService(categoryRepo)
+	transactionRepo := repositories.NewPostgresTransactionRepository(db)
+	budgetService := services.NewBudgetService(
+		repositories.NewPostgresBudgetRepository(db),
+		accountRepo,
+		categoryRepo,
+		transactionRepo,
+		outboxRepo,
+		uow,
+	)
+	categorizer := services.NewCategorizationService(
+		repositories.NewPostgresCategorizationRuleRepository(db),
+		categoryRepo,
+		accountRepo,
+		transactionRepo,
+		budgetService,
+		uow,
+	)
+	statementService := services.NewStatementService(accountRepo, transactionRepo, transferRepo, tradeRepo)
+	transactionService := services.NewTransactionService(accountRepo, categoryRepo, transactionRepo, categorizer, budgetService, uow)
+	exportService := services.NewExportService(accountRepo, transactionRepo, tradeRepo)
+
+	// Materialize recurring transactions and trades as they fall due
+	scheduleRepo := repositories.NewPostgresScheduleRepository(db)
+	scheduleService := services.NewScheduleService(scheduleRepo, accountRepo, categoryRepo)
+	scheduler := services.NewScheduler(uow, scheduleRepo, transactionRepo, outboxRepo, categorizer, budgetService, time.Minute, 100)
+	go scheduler.Run(context.Background())
 
 	// Initialize Gin router
 	r := gin.Default()
@@ -37,12 +165,59 @@ func main() {
 	})
 
 	// User endpoints
-	r.POST("/users", createUser)
-	r.GET("/users/:id", getUser)
+	r.POST("/users", createUser(userService))
+	r.POST("/login", login(userService))
+	r.GET("/users/:id", requireAuth(userService), getUser(userService))
+
+	// Account endpoints
+	accounts := r.Group("/accounts", requireAuth(userService))
+	accounts.POST("", createAccount(accountService))
+	accounts.GET("", listAccounts(accountService))
+	accounts.GET("/:id", getAccount(accountService))
+	accounts.PATCH("/:id", updateAccount(accountService))
+	accounts.DELETE("/:id", deleteAccount(accountService))
+	accounts.GET("/:id/statements", getStatement(statementService))
+
+	// Transfer endpoints
+	r.POST("/transfers", requireAuth(userService), createTransfer(transferService))
+
+	// Category endpoints
+	categories := r.Group("/categories", requireAuth(userService))
+	categories.POST("", createCategory(categoryService))
+	categories.GET("", listCategories(categoryService))
+	categories.GET("/:id", getCategory(categoryService))
+	categories.PATCH("/:id", updateCategory(categoryService))
+	categories.DELETE("/:id", deleteCategory(categoryService))
+
+	// Categorization rule endpoints
+	rules := r.Group("/categorization-rules", requireAuth(userService))
+	rules.POST("", createCategorizationRule(categorizer))
+	rules.GET("", listCategorizationRules(categorizer))
+	rules.PUT("/:id", replaceCategorizationRule(categorizer))
+	rules.DELETE("/:id", deleteCategorizationRule(categorizer))
+	rules.POST("/apply", applyCategorizationRules(categorizer))
 
 	// Transaction endpoints
-	r.POST("/transactions", createTransaction)
-	r.GET("/transactions/:id", getTransaction)
+	r.POST("/transactions", requireAuth(userService), createTransaction(transactionService))
+	r.GET("/transactions/export", requireAuth(userService), exportTransactions(exportService))
+	r.GET("/transactions/:id", requireAuth(userService), getTransaction(transactionService))
+	r.GET("/users/:id/transactions/history", requireAuth(userService), getTransactionHistory(historyRepo))
+
+	// Schedule endpoints
+	schedules := r.Group("/schedules", requireAuth(userService))
+	schedules.POST("", createSchedule(scheduleService))
+	schedules.GET("", listSchedules(scheduleService))
+	schedules.GET("/:id", getSchedule(scheduleService))
+	schedules.DELETE("/:id", deleteSchedule(scheduleService))
+
+	// Budget endpoints
+	budgets := r.Group("/budgets", requireAuth(userService))
+	budgets.POST("", createBudget(budgetService))
+	budgets.GET("", listBudgets(budgetService))
+	budgets.GET("/:id", getBudget(budgetService))
+	budgets.PATCH("/:id", updateBudget(budgetService))
+	budgets.DELETE("/:id", deleteBudget(budgetService))
+	budgets.GET("/:id/status", getBudgetStatus(budgetService))
 
 	// Quotation endpoints
 	r.GET("/quotations/latest", getLatestQuotation)
@@ -55,42 +230,71 @@ func main() {
 	r.Run(":" + port)
 }
 
-func createUser(c *gin.Context) {
-	var user models.User
-	if err := c.ShouldBindJSON(&user); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
-		return
-	}
+// maxHistoryPageSize bounds the page size of the transaction history, so a
+// single request can't make Cassandra read an unbounded page
+const maxHistoryPageSize = 500
 
-	// TODO: Implement user creation logic
+// getTransactionHistory lists the authenticated user's trades from
+// Cassandra, newest first. Other users behave as if they didn't exist.
+// Optional from/to (RFC 3339) bound the request time; page_size, up to
+// maxHistoryPageSize, limits the trades returned; page_state, returned as
+// next_page_state by the previous call, continues a listing.
+func getTransactionHistory(historyRepo repositories.TransactionHistoryRepository) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, ok := idParam(c, "user")
+		if !ok {
+			return
+		}
+		if userID != currentUserID(c) {
+			c.JSON(http.StatusNotFound, gin.H{"error": repositories.ErrUserNotFound.Error()})
+			return
+		}
 
-	c.JSON(http.StatusCreated, user)
-}
+		var (
+			from, to time.Time
+			err      error
+		)
+		if v := c.Query("from"); v != "" {
+			if from, err = time.Parse(time.RFC3339, v); err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "invalid from: " + err.Error()})
+				return
+			}
+		}
+		if v := c.Query("to"); v != "" {
+			if to, err = time.Parse(time.RFC3339, v); err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "invalid to: " + err.Error()})
+				return
+			}
+		}
 
-func getUser(c *gin.Context) {
-	id := c.Param("id")
-	// TODO: Implement user retrieval logic
+		pageSize := 50
+		if v := c.Query("page_size"); v != "" {
+			if pageSize, err = strconv.Atoi(v); err != nil || pageSize <= 0 || pageSize > maxHistoryPageSize {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "page_size must be between 1 and " + strconv.Itoa(maxHistoryPageSize)})
+				return
+			}
+		}
+		pageState, err := base64.URLEncoding.DecodeString(c.Query("page_state"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid page_state"})
+			return
+		}
 
-	c.JSON(http.StatusOK, gin.H{"id": id})
-}
+		trades, next, err := historyRepo.GetByUser(c.Request.Context(), userID, from, to, pageSize, pageState)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
 
-func createTransaction(c *gin.Context) {
-	var transaction models.Transaction
-	if err := c.ShouldBindJSON(&transaction); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
-		return
+		response := make([]dto.Trade, len(trades))
+		for i := range trades {
+			response[i] = dto.NewTrade(&trades[i])
+		}
+		c.JSON(http.StatusOK, gin.H{
+			"transactions":    response,
+			"next_page_state": base64.URLEncoding.EncodeToString(next),
+		})
 	}
-
-	// TODO: Implement transaction creation logic
-
-	c.JSON(http.StatusCreated, transaction)
-}
-
-func getTransaction(c *gin.Context) {
-	id := c.Param("id")
-	// TODO: Implement transaction retrieval logic
-
-	c.JSON(http.StatusOK, gin.H{"id": id})
 }
 
 func getLatestQuotation(c *gin.Context) {
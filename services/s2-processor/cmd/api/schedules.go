@@ -0,0 +1,100 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/leandroalencar/banco-dados/services/s2-processor/internal/domain/repositories"
+	"github.com/leandroalencar/banco-dados/services/s2-processor/internal/domain/services"
+	"github.com/leandroalencar/banco-dados/services/s2-processor/internal/dto"
+)
+
+// createSchedule adds a recurring transaction or trade of the authenticated
+// user
+func createSchedule(scheduleService *services.ScheduleService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var request dto.ScheduleRequest
+		if err := c.ShouldBindJSON(&request); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		schedule := request.ToModel()
+		if err := scheduleService.Create(c.Request.Context(), currentUserID(c), schedule); err != nil {
+			respondScheduleError(c, err)
+			return
+		}
+
+		c.JSON(http.StatusCreated, dto.NewSchedule(schedule))
+	}
+}
+
+// listSchedules lists the authenticated user's schedules
+func listSchedules(scheduleService *services.ScheduleService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		schedules, err := scheduleService.List(c.Request.Context(), currentUserID(c))
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		response := make([]dto.Schedule, len(schedules))
+		for i := range schedules {
+			response[i] = dto.NewSchedule(&schedules[i])
+		}
+		c.JSON(http.StatusOK, gin.H{"schedules": response})
+	}
+}
+
+// getSchedule returns one of the authenticated user's schedules
+func getSchedule(scheduleService *services.ScheduleService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, ok := idParam(c, "schedule")
+		if !ok {
+			return
+		}
+
+		schedule, err := scheduleService.Get(c.Request.Context(), currentUserID(c), id)
+		if err != nil {
+			respondScheduleError(c, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, dto.NewSchedule(schedule))
+	}
+}
+
+// deleteSchedule stops and removes one of the authenticated user's
+// schedules
+func deleteSchedule(scheduleService *services.ScheduleService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, ok := idParam(c, "schedule")
+		if !ok {
+			return
+		}
+
+		if err := scheduleService.Delete(c.Request.Context(), currentUserID(c), id); err != nil {
+			respondScheduleError(c, err)
+			return
+		}
+
+		c.Status(http.StatusNoContent)
+	}
+}
+
+// respondScheduleError maps schedule service errors to HTTP statuses
+func respondScheduleError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, repositories.ErrScheduleNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, repositories.ErrAccountNotFound),
+		errors.Is(err, repositories.ErrCategoryNotFound),
+		errors.Is(err, services.ErrInvalidSchedule),
+		errors.Is(err, services.ErrInvalidAmount),
+		errors.Is(err, services.ErrInvalidCurrency):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
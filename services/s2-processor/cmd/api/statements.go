@@ -0,0 +1,33 @@
+package main
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/leandroalencar/banco-dados/services/s2-processor/internal/domain/services"
+	"github.com/leandroalencar/banco-dados/services/s2-processor/internal/dto"
+)
+
+// getStatement returns the monthly statement of one of the authenticated
+// user's accounts. The optional month (YYYY-MM, UTC) defaults to the
+// current one.
+func getStatement(statementService *services.StatementService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, ok := idParam(c, "account")
+		if !ok {
+			return
+		}
+		month, ok := monthQuery(c)
+		if !ok {
+			return
+		}
+
+		statement, err := statementService.Monthly(c.Request.Context(), currentUserID(c), id, month)
+		if err != nil {
+			respondAccountError(c, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, dto.NewStatement(statement))
+	}
+}
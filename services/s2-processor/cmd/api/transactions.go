@@ -0,0 +1,57 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/leandroalencar/banco-dados/services/s2-processor/internal/domain/repositories"
+	"github.com/leandroalencar/banco-dados/services/s2-processor/internal/domain/services"
+	"github.com/leandroalencar/banco-dados/services/s2-processor/internal/dto"
+)
+
+// createTransaction records an income or expense on one of the
+// authenticated user's accounts
+func createTransaction(transactionService *services.TransactionService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var request dto.TransactionRequest
+		if err := c.ShouldBindJSON(&request); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		transaction := request.ToModel()
+		err := transactionService.Create(c.Request.Context(), currentUserID(c), transaction)
+		switch {
+		case err == nil:
+			c.JSON(http.StatusCreated, dto.NewTransaction(transaction))
+		case errors.Is(err, repositories.ErrAccountNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		case errors.Is(err, repositories.ErrCategoryNotFound), errors.Is(err, services.ErrInvalidAmount):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+	}
+}
+
+// getTransaction returns a transaction on one of the authenticated user's
+// accounts
+func getTransaction(transactionService *services.TransactionService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, ok := idParam(c, "transaction")
+		if !ok {
+			return
+		}
+
+		transaction, err := transactionService.Get(c.Request.Context(), currentUserID(c), id)
+		switch {
+		case err == nil:
+			c.JSON(http.StatusOK, dto.NewTransaction(transaction))
+		case errors.Is(err, repositories.ErrTransactionNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+	}
+}
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/leandroalencar/banco-dados/services/s2-processor/internal/domain/repositories"
+	"github.com/leandroalencar/banco-dados/services/s2-processor/internal/domain/services"
+	"github.com/leandroalencar/banco-dados/services/s2-processor/internal/dto"
+)
+
+// createUser registers a user and returns it with a token for the
+// authenticated endpoints
+func createUser(userService *services.UserService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var request dto.CreateUserRequest
+		if err := c.ShouldBindJSON(&request); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		user := request.ToModel()
+		output, err := userService.RegisterUser(c.Request.Context(), services.RegisterUserInput{
+			Email:    user.Email,
+			Password: user.Password,
+			Name:     user.Name(),
+		})
+		switch {
+		case err == nil:
+			c.JSON(http.StatusCreated, output)
+		case errors.Is(err, services.ErrInvalidEmail), errors.Is(err, services.ErrWeakPassword):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case errors.Is(err, services.ErrEmailTaken):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+	}
+}
+
+// login exchanges a user's email and password for a token
+func login(userService *services.UserService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var request dto.LoginRequest
+		if err := c.ShouldBindJSON(&request); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		token, err := userService.Login(c.Request.Context(), request.Email, request.Password)
+		switch {
+		case err == nil:
+			c.JSON(http.StatusOK, gin.H{"token": token})
+		case errors.Is(err, services.ErrInvalidCredentials):
+			c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+	}
+}
+
+// getUser returns the authenticated user. Other users behave as if they
+// didn't exist.
+func getUser(userService *services.UserService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, ok := idParam(c, "user")
+		if !ok {
+			return
+		}
+		if id != currentUserID(c) {
+			c.JSON(http.StatusNotFound, gin.H{"error": repositories.ErrUserNotFound.Error()})
+			return
+		}
+
+		user, err := userService.Get(c.Request.Context(), id)
+		switch {
+		case err == nil:
+			c.JSON(http.StatusOK, dto.NewUser(user))
+		case errors.Is(err, repositories.ErrUserNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+	}
+}
@@ -0,0 +1,160 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/leandroalencar/banco-dados/services/s2-processor/internal/domain/repositories/memory"
+	"github.com/leandroalencar/banco-dados/services/s2-processor/internal/domain/services"
+)
+
+const testPassword = "Secret#123"
+
+// testAPI serves the routes under test from in-memory repositories
+type testAPI struct {
+	router *gin.Engine
+}
+
+func newTestAPI() *testAPI {
+	gin.SetMode(gin.TestMode)
+	userService := services.NewUserService(memory.NewUserRepository(), "secret", time.Hour)
+	accountService := services.NewAccountService(memory.NewAccountRepository())
+
+	r := gin.New()
+	r.POST("/users", createUser(userService))
+	r.POST("/login", login(userService))
+	r.GET("/users/:id", requireAuth(userService), getUser(userService))
+	r.GET("/users/:id/transactions/history", requireAuth(userService), getTransactionHistory(memory.NewTransactionHistoryRepository()))
+	accounts := r.Group("/accounts", requireAuth(userService))
+	accounts.POST("", createAccount(accountService))
+	accounts.GET("/:id", getAccount(accountService))
+	return &testAPI{router: r}
+}
+
+// do sends a request with body encoded as JSON, authenticated with token if
+// set, and decodes the response into out if not nil
+func (a *testAPI) do(t *testing.T, method, path, token string, body, out interface{}) int {
+	t.Helper()
+	var buf bytes.Buffer
+	if body != nil {
+		if err := json.NewEncoder(&buf).Encode(body); err != nil {
+			t.Fatal(err)
+		}
+	}
+	req := httptest.NewRequest(method, path, &buf)
+	req.Header.Set("Content-Type", "application/json")
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	w := httptest.NewRecorder()
+	a.router.ServeHTTP(w, req)
+	if out != nil {
+		if err := json.Unmarshal(w.Body.Bytes(), out); err != nil {
+			t.Fatalf("%s %s: decoding %q: %v", method, path, w.Body.String(), err)
+		}
+	}
+	return w.Code
+}
+
+// register creates a user and returns their token
+func (a *testAPI) register(t *testing.T, email string) string {
+	t.Helper()
+	var created struct{ Token string }
+	body := gin.H{"email": email, "password": testPassword, "first_name": "Ana"}
+	if code := a.do(t, http.MethodPost, "/users", "", body, &created); code != http.StatusCreated {
+		t.Fatalf("registering %s: status %d", email, code)
+	}
+	return created.Token
+}
+
+func TestCreateUser(t *testing.T) {
+	tests := []struct {
+		name string
+		body gin.H
+		want int
+	}{
+		{name: "valid", body: gin.H{"email": "bia@example.com", "password": testPassword, "first_name": "Bia"}, want: http.StatusCreated},
+		{name: "email taken", body: gin.H{"email": "ana@example.com", "password": testPassword, "first_name": "Ana"}, want: http.StatusConflict},
+		{name: "weak password", body: gin.H{"email": "bia@example.com", "password": "password", "first_name": "Bia"}, want: http.StatusBadRequest},
+		{name: "missing name", body: gin.H{"email": "bia@example.com", "password": testPassword}, want: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			api := newTestAPI()
+			api.register(t, "ana@example.com")
+			if code := api.do(t, http.MethodPost, "/users", "", tt.body, nil); code != tt.want {
+				t.Errorf("status = %d, want %d", code, tt.want)
+			}
+		})
+	}
+}
+
+func TestLogin(t *testing.T) {
+	tests := []struct {
+		name     string
+		email    string
+		password string
+		want     int
+	}{
+		{name: "valid", email: "ana@example.com", password: testPassword, want: http.StatusOK},
+		{name: "wrong password", email: "ana@example.com", password: "Wrong#123", want: http.StatusUnauthorized},
+		{name: "unknown email", email: "bia@example.com", password: testPassword, want: http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			api := newTestAPI()
+			api.register(t, "ana@example.com")
+
+			var response struct{ Token string }
+			code := api.do(t, http.MethodPost, "/login", "", gin.H{"email": tt.email, "password": tt.password}, &response)
+			if code != tt.want {
+				t.Fatalf("status = %d, want %d", code, tt.want)
+			}
+			if code == http.StatusOK {
+				if code := api.do(t, http.MethodGet, "/users/1", response.Token, nil, nil); code != http.StatusOK {
+					t.Errorf("GET /users/1 with issued token: status %d", code)
+				}
+			}
+		})
+	}
+}
+
+func TestGetUser(t *testing.T) {
+	tests := []struct {
+		name  string
+		path  string
+		token bool
+		want  int
+	}{
+		{name: "self", path: "/users/1", token: true, want: http.StatusOK},
+		{name: "another user", path: "/users/2", token: true, want: http.StatusNotFound},
+		{name: "without token", path: "/users/1", want: http.StatusUnauthorized},
+		{name: "own history", path: "/users/1/transactions/history", token: true, want: http.StatusOK},
+		{name: "another user's history", path: "/users/2/transactions/history", token: true, want: http.StatusNotFound},
+		{name: "history without token", path: "/users/1/transactions/history", want: http.StatusUnauthorized},
+		{name: "largest history page", path: "/users/1/transactions/history?page_size=500", token: true, want: http.StatusOK},
+		{name: "history page too large", path: "/users/1/transactions/history?page_size=501", token: true, want: http.StatusBadRequest},
+		{name: "empty history page", path: "/users/1/transactions/history?page_size=0", token: true, want: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			api := newTestAPI()
+			token := api.register(t, "ana@example.com")
+			api.register(t, "bia@example.com")
+			if !tt.token {
+				token = ""
+			}
+			if code := api.do(t, http.MethodGet, tt.path, token, nil, nil); code != tt.want {
+				t.Errorf("status = %d, want %d", code, tt.want)
+			}
+		})
+	}
+}
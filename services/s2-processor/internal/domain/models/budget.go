@@ -0,0 +1,60 @@
+package models
+
+import (
+	"math"
+	"time"
+)
+
+// Budget limits a user's monthly spending in a category and its
+// subcategories, counting expenses on the user's accounts in Currency.
+// Thresholds are percentages of Limit, in ascending order; spending
+// reaching one raises an alert once per month.
+type Budget struct {
+	ID         uint
+	UserID     uint
+	CategoryID uint
+	Limit      float64
+	Currency   string
+	Thresholds []int
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+}
+
+// BudgetStatus is the spending against a budget in the month starting at
+// Month
+type BudgetStatus struct {
+	Budget Budget
+	Month  time.Time
+	Spent  float64
+}
+
+// Remaining returns how much can still be spent, negative once overspent
+func (s *BudgetStatus) Remaining() float64 {
+	return math.Round((s.Budget.Limit-s.Spent)*100) / 100
+}
+
+// Percent returns the spending as a percentage of the limit
+func (s *BudgetStatus) Percent() float64 {
+	if s.Budget.Limit <= 0 {
+		return 0
+	}
+	return math.Round(s.Spent/s.Budget.Limit*10000) / 100
+}
+
+// Reached returns the thresholds the spending has reached
+func (s *BudgetStatus) Reached() []int {
+	var reached []int
+	for _, threshold := range s.Budget.Thresholds {
+		if s.Spent >= s.Budget.Limit*float64(threshold)/100 {
+			reached = append(reached, threshold)
+		}
+	}
+	return reached
+}
+
+// MonthOf returns the start of the month containing t, in UTC. Budgets are
+// tracked in UTC months.
+func MonthOf(t time.Time) time.Time {
+	year, month, _ := t.UTC().Date()
+	return time.Date(year, month, 1, 0, 0, 0, 0, time.UTC)
+}
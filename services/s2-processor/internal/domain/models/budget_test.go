@@ -0,0 +1,64 @@
+package models
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestBudgetStatus(t *testing.T) {
+	tests := []struct {
+		name      string
+		limit     float64
+		spent     float64
+		remaining float64
+		percent   float64
+		reached   []int
+	}{
+		{name: "nothing spent", limit: 200, spent: 0, remaining: 200, percent: 0},
+		{name: "below every threshold", limit: 200, spent: 99, remaining: 101, percent: 49.5},
+		{name: "threshold reached exactly", limit: 200, spent: 100, remaining: 100, percent: 50, reached: []int{50}},
+		{name: "all thresholds", limit: 200, spent: 200, remaining: 0, percent: 100, reached: []int{50, 80, 100}},
+		{name: "overspent", limit: 200, spent: 250.5, remaining: -50.5, percent: 125.25, reached: []int{50, 80, 100}},
+		{name: "rounded to cents", limit: 0.3, spent: 0.1, remaining: 0.2, percent: 33.33},
+		{name: "zero limit", limit: 0, spent: 10, remaining: -10, percent: 0, reached: []int{50, 80, 100}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status := BudgetStatus{Budget: Budget{Limit: tt.limit, Thresholds: []int{50, 80, 100}}, Spent: tt.spent}
+			if got := status.Remaining(); got != tt.remaining {
+				t.Errorf("Remaining = %v, want %v", got, tt.remaining)
+			}
+			if got := status.Percent(); got != tt.percent {
+				t.Errorf("Percent = %v, want %v", got, tt.percent)
+			}
+			if got := status.Reached(); !reflect.DeepEqual(got, tt.reached) {
+				t.Errorf("Reached = %v, want %v", got, tt.reached)
+			}
+		})
+	}
+}
+
+func TestMonthOf(t *testing.T) {
+	saoPaulo := time.FixedZone("BRT", -3*60*60)
+
+	tests := []struct {
+		name string
+		t    time.Time
+		want time.Time
+	}{
+		{name: "mid month", t: time.Date(2026, 5, 17, 13, 0, 0, 0, time.UTC), want: time.Date(2026, 5, 1, 0, 0, 0, 0, time.UTC)},
+		{name: "first instant", t: time.Date(2026, 5, 1, 0, 0, 0, 0, time.UTC), want: time.Date(2026, 5, 1, 0, 0, 0, 0, time.UTC)},
+		{name: "local evening is next UTC month", t: time.Date(2026, 4, 30, 22, 0, 0, 0, saoPaulo), want: time.Date(2026, 5, 1, 0, 0, 0, 0, time.UTC)},
+		{name: "year end", t: time.Date(2026, 12, 31, 23, 59, 59, 0, time.UTC), want: time.Date(2026, 12, 1, 0, 0, 0, 0, time.UTC)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := MonthOf(tt.t); !got.Equal(tt.want) {
+				t.Errorf("MonthOf = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,65 @@
+package models
+
+import (
+	"regexp"
+	"strings"
+	"time"
+)
+
+type MatchType string
+
+const (
+	MatchContains MatchType = "contains"
+	MatchEquals   MatchType = "equals"
+	MatchRegex    MatchType = "regex"
+)
+
+// CategorizationRule assigns CategoryID to a user's transactions that match
+// all of its conditions. Empty conditions match everything; description
+// matching ignores case. Rules are tried by ascending Priority.
+type CategorizationRule struct {
+	ID                 uint
+	UserID             uint
+	CategoryID         uint
+	Priority           int
+	DescriptionPattern string
+	MatchType          MatchType
+	MinAmount          *float64
+	MaxAmount          *float64
+	AccountID          uint
+	CreatedAt          time.Time
+	UpdatedAt          time.Time
+}
+
+// Matches reports whether the rule applies to a transaction
+func (r *CategorizationRule) Matches(t *Transaction) bool {
+	if r.AccountID != 0 && t.AccountID != r.AccountID {
+		return false
+	}
+	if r.MinAmount != nil && t.Amount < *r.MinAmount {
+		return false
+	}
+	if r.MaxAmount != nil && t.Amount > *r.MaxAmount {
+		return false
+	}
+	if r.DescriptionPattern == "" {
+		return true
+	}
+
+	description := strings.ToLower(strings.TrimSpace(t.Description))
+	pattern := strings.ToLower(strings.TrimSpace(r.DescriptionPattern))
+	switch r.MatchType {
+	case MatchEquals:
+		return description == pattern
+	case MatchRegex:
+		re, err := r.Regexp()
+		return err == nil && re.MatchString(t.Description)
+	default:
+		return strings.Contains(description, pattern)
+	}
+}
+
+// Regexp compiles the description pattern of a regex rule
+func (r *CategorizationRule) Regexp() (*regexp.Regexp, error) {
+	return regexp.Compile("(?i)" + r.DescriptionPattern)
+}
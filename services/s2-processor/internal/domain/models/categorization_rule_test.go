@@ -0,0 +1,39 @@
+package models
+
+import "testing"
+
+func TestCategorizationRuleMatches(t *testing.T) {
+	amount := func(v float64) *float64 { return &v }
+	transaction := Transaction{AccountID: 1, Amount: 50, Description: "  Uber *Trip São Paulo "}
+
+	tests := []struct {
+		name string
+		rule CategorizationRule
+		want bool
+	}{
+		{name: "no conditions", rule: CategorizationRule{}, want: true},
+		{name: "contains ignores case and spaces", rule: CategorizationRule{DescriptionPattern: " uber ", MatchType: MatchContains}, want: true},
+		{name: "contains by default", rule: CategorizationRule{DescriptionPattern: "trip"}, want: true},
+		{name: "contains misses", rule: CategorizationRule{DescriptionPattern: "lyft"}, want: false},
+		{name: "equals", rule: CategorizationRule{DescriptionPattern: "UBER *TRIP SÃO PAULO", MatchType: MatchEquals}, want: true},
+		{name: "equals needs the whole description", rule: CategorizationRule{DescriptionPattern: "uber", MatchType: MatchEquals}, want: false},
+		{name: "regex ignores case", rule: CategorizationRule{DescriptionPattern: `^\s*uber\b`, MatchType: MatchRegex}, want: true},
+		{name: "regex misses", rule: CategorizationRule{DescriptionPattern: `^lyft`, MatchType: MatchRegex}, want: false},
+		{name: "invalid regex never matches", rule: CategorizationRule{DescriptionPattern: `(`, MatchType: MatchRegex}, want: false},
+		{name: "same account", rule: CategorizationRule{AccountID: 1}, want: true},
+		{name: "other account", rule: CategorizationRule{AccountID: 2}, want: false},
+		{name: "within amounts", rule: CategorizationRule{MinAmount: amount(10), MaxAmount: amount(100)}, want: true},
+		{name: "amount bounds are inclusive", rule: CategorizationRule{MinAmount: amount(50), MaxAmount: amount(50)}, want: true},
+		{name: "below min", rule: CategorizationRule{MinAmount: amount(50.01)}, want: false},
+		{name: "above max", rule: CategorizationRule{MaxAmount: amount(49.99)}, want: false},
+		{name: "every condition must match", rule: CategorizationRule{DescriptionPattern: "uber", AccountID: 2}, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.rule.Matches(&transaction); got != tt.want {
+				t.Errorf("Matches = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,27 @@
+package models
+
+import (
+	"time"
+)
+
+// Category classifies transactions. Categories without a user are system
+// defaults shared by everyone; users add their own, optionally nested under
+// any category they can see.
+type Category struct {
+	ID        uint
+	UserID    uint
+	ParentID  uint
+	Name      string
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// IsSystem reports whether the category is a system default
+func (c *Category) IsSystem() bool {
+	return c.UserID == 0
+}
+
+// VisibleTo reports whether userID may use the category
+func (c *Category) VisibleTo(userID uint) bool {
+	return c.IsSystem() || c.UserID == userID
+}
@@ -1,15 +1,23 @@
 package models
 
+import (
+	"time"
+)
+
+// Currency is a quotation snapshot in the format used by AwesomeAPI, where
+// prices are decimal strings
 type Currency struct {
-	Code       string `json:"code" bson:"code"`
-	Codein     string `json:"codein" bson:"codein"`
-	Name       string `json:"name" bson:"name"`
-	High       string `json:"high" bson:"high"`
-	Low        string `json:"low" bson:"low"`
-	VarBid     string `json:"varBid" bson:"var_bid"`
-	PctChange  string `json:"pctChange" bson:"pct_change"`
-	Bid        string `json:"bid" bson:"bid"`
-	Ask        string `json:"ask" bson:"ask"`
-	Timestamp  string `json:"timestamp" bson:"timestamp"`
-	CreateDate string `json:"create_date" bson:"create_date"`
+	ID         string
+	Code       string
+	Codein     string
+	Name       string
+	High       string
+	Low        string
+	VarBid     string
+	PctChange  string
+	Bid        string
+	Ask        string
+	Timestamp  string
+	CreateDate string
+	QuotedAt   time.Time
 }
@@ -0,0 +1,27 @@
+package models
+
+import (
+	"time"
+)
+
+type OutboxStatus string
+
+const (
+	OutboxPending OutboxStatus = "pending"
+	OutboxSent    OutboxStatus = "sent"
+)
+
+// OutboxMessage is an event waiting to be published to the message broker.
+// It is written in the same database transaction as the change it describes,
+// so the event exists if and only if the change was committed.
+type OutboxMessage struct {
+	ID        uint
+	MessageID string
+	Queue     string
+	Payload   string
+	Status    OutboxStatus
+	Attempts  int
+	LastError string
+	CreatedAt time.Time
+	SentAt    *time.Time
+}
@@ -0,0 +1,13 @@
+package models
+
+import (
+	"time"
+)
+
+// ProcessedMessage records that a consumed message was handled, so a
+// redelivery of the same message can be recognised and skipped.
+type ProcessedMessage struct {
+	MessageID   string
+	Queue       string
+	ProcessedAt time.Time
+}
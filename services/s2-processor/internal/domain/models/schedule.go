@@ -0,0 +1,110 @@
+package models
+
+import (
+	"time"
+)
+
+type Frequency string
+
+const (
+	Daily   Frequency = "daily"
+	Weekly  Frequency = "weekly"
+	Monthly Frequency = "monthly"
+)
+
+// Recurrence describes when a schedule repeats: every Interval days, weeks
+// or months from StartAt, until Until or for Count occurrences, whichever
+// ends first. A zero Count and nil Until repeat forever.
+type Recurrence struct {
+	Frequency Frequency
+	Interval  int
+	StartAt   time.Time
+	Until     *time.Time
+	Count     int
+}
+
+// Occurrence returns the time of the nth occurrence, counting from 0.
+// Monthly occurrences keep the day of StartAt, or the last day of shorter
+// months, so a schedule starting on the 31st runs on February 28th.
+func (r Recurrence) Occurrence(n int) time.Time {
+	steps := n * r.Interval
+	switch r.Frequency {
+	case Weekly:
+		return r.StartAt.AddDate(0, 0, 7*steps)
+	case Monthly:
+		return addMonths(r.StartAt, steps)
+	default:
+		return r.StartAt.AddDate(0, 0, steps)
+	}
+}
+
+// Includes reports whether the nth occurrence, at time at, is part of the
+// recurrence
+func (r Recurrence) Includes(n int, at time.Time) bool {
+	if r.Count > 0 && n >= r.Count {
+		return false
+	}
+	return r.Until == nil || !at.After(*r.Until)
+}
+
+// addMonths adds months to t, clamping the day to the end of the month
+func addMonths(t time.Time, months int) time.Time {
+	year, month, day := t.Date()
+	first := time.Date(year, month+time.Month(months), 1, t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), t.Location())
+	if last := first.AddDate(0, 1, -1).Day(); day > last {
+		day = last
+	}
+	return first.AddDate(0, 0, day-1)
+}
+
+type ScheduleKind string
+
+const (
+	// ScheduledTransaction posts an income or expense on an account
+	ScheduledTransaction ScheduleKind = "transaction"
+
+	// ScheduledTrade places a currency buy or sell order
+	ScheduledTrade ScheduleKind = "trade"
+)
+
+// Schedule materializes a transaction or a currency order at every
+// occurrence of its recurrence. AccountID, CategoryID, TransactionType and
+// Description describe scheduled transactions; TradeType and CurrencyPair
+// describe scheduled trades. Occurrences counts the occurrences
+// materialized so far and NextRunAt is the time of the next one, or nil
+// once the schedule has ended.
+type Schedule struct {
+	ID              uint
+	UserID          uint
+	Kind            ScheduleKind
+	Recurrence      Recurrence
+	Amount          float64
+	AccountID       uint
+	CategoryID      uint
+	TransactionType TransactionType
+	Description     string
+	TradeType       TradeType
+	CurrencyPair    string
+	Occurrences     int
+	NextRunAt       *time.Time
+	LastError       string
+	CreatedAt       time.Time
+	UpdatedAt       time.Time
+}
+
+// Advance records that the occurrence at NextRunAt was materialized and
+// moves NextRunAt to the following one, clearing any earlier error
+func (s *Schedule) Advance() {
+	s.Occurrences++
+	s.LastError = ""
+	s.NextRunAt = nil
+	if next := s.Recurrence.Occurrence(s.Occurrences); s.Recurrence.Includes(s.Occurrences, next) {
+		s.NextRunAt = &next
+	}
+}
+
+// End stops the schedule, recording why
+func (s *Schedule) End(reason string) {
+	s.NextRunAt = nil
+	s.LastError = reason
+}
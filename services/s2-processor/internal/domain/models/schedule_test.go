@@ -0,0 +1,103 @@
+package models
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRecurrenceOccurrence(t *testing.T) {
+	date := func(year int, month time.Month, day int) time.Time {
+		return time.Date(year, month, day, 9, 30, 0, 0, time.UTC)
+	}
+
+	tests := []struct {
+		name       string
+		recurrence Recurrence
+		n          int
+		want       time.Time
+	}{
+		{name: "first occurrence is the start", recurrence: Recurrence{Frequency: Monthly, Interval: 1, StartAt: date(2026, 1, 15)}, n: 0, want: date(2026, 1, 15)},
+		{name: "daily", recurrence: Recurrence{Frequency: Daily, Interval: 1, StartAt: date(2026, 1, 30)}, n: 3, want: date(2026, 2, 2)},
+		{name: "every other day", recurrence: Recurrence{Frequency: Daily, Interval: 2, StartAt: date(2026, 1, 1)}, n: 3, want: date(2026, 1, 7)},
+		{name: "weekly", recurrence: Recurrence{Frequency: Weekly, Interval: 1, StartAt: date(2026, 1, 1)}, n: 2, want: date(2026, 1, 15)},
+		{name: "fortnightly", recurrence: Recurrence{Frequency: Weekly, Interval: 2, StartAt: date(2026, 1, 1)}, n: 2, want: date(2026, 1, 29)},
+		{name: "monthly", recurrence: Recurrence{Frequency: Monthly, Interval: 1, StartAt: date(2026, 1, 15)}, n: 13, want: date(2027, 2, 15)},
+		{name: "quarterly", recurrence: Recurrence{Frequency: Monthly, Interval: 3, StartAt: date(2026, 11, 5)}, n: 1, want: date(2027, 2, 5)},
+		{name: "end of month clamps to February", recurrence: Recurrence{Frequency: Monthly, Interval: 1, StartAt: date(2026, 1, 31)}, n: 1, want: date(2026, 2, 28)},
+		{name: "end of month in a leap year", recurrence: Recurrence{Frequency: Monthly, Interval: 1, StartAt: date(2028, 1, 31)}, n: 1, want: date(2028, 2, 29)},
+		{name: "clamping doesn't stick", recurrence: Recurrence{Frequency: Monthly, Interval: 1, StartAt: date(2026, 1, 31)}, n: 2, want: date(2026, 3, 31)},
+		{name: "thirty-day month", recurrence: Recurrence{Frequency: Monthly, Interval: 1, StartAt: date(2026, 3, 31)}, n: 1, want: date(2026, 4, 30)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.recurrence.Occurrence(tt.n); !got.Equal(tt.want) {
+				t.Errorf("Occurrence(%d) = %v, want %v", tt.n, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRecurrenceIncludes(t *testing.T) {
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	until := start.AddDate(0, 0, 10)
+
+	tests := []struct {
+		name       string
+		recurrence Recurrence
+		n          int
+		at         time.Time
+		want       bool
+	}{
+		{name: "forever", recurrence: Recurrence{}, n: 1000, at: start.AddDate(5, 0, 0), want: true},
+		{name: "within count", recurrence: Recurrence{Count: 3}, n: 2, at: start, want: true},
+		{name: "past count", recurrence: Recurrence{Count: 3}, n: 3, at: start, want: false},
+		{name: "before until", recurrence: Recurrence{Until: &until}, n: 5, at: until.Add(-time.Second), want: true},
+		{name: "at until", recurrence: Recurrence{Until: &until}, n: 5, at: until, want: true},
+		{name: "after until", recurrence: Recurrence{Until: &until}, n: 5, at: until.Add(time.Second), want: false},
+		{name: "count ends first", recurrence: Recurrence{Count: 2, Until: &until}, n: 2, at: start, want: false},
+		{name: "until ends first", recurrence: Recurrence{Count: 20, Until: &until}, n: 11, at: until.AddDate(0, 0, 1), want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.recurrence.Includes(tt.n, tt.at); got != tt.want {
+				t.Errorf("Includes(%d, %v) = %v, want %v", tt.n, tt.at, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestScheduleAdvance(t *testing.T) {
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	until := start.AddDate(0, 0, 2)
+
+	tests := []struct {
+		name       string
+		recurrence Recurrence
+		// runs is how many occurrences are materialized before the
+		// schedule ends
+		runs int
+	}{
+		{name: "count", recurrence: Recurrence{Frequency: Daily, Interval: 1, StartAt: start, Count: 3}, runs: 3},
+		{name: "until is inclusive", recurrence: Recurrence{Frequency: Daily, Interval: 1, StartAt: start, Until: &until}, runs: 3},
+		{name: "single occurrence", recurrence: Recurrence{Frequency: Monthly, Interval: 1, StartAt: start, Count: 1}, runs: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			schedule := Schedule{Recurrence: tt.recurrence, NextRunAt: &start}
+			runs := 0
+			for schedule.NextRunAt != nil && runs <= tt.runs {
+				if want := tt.recurrence.Occurrence(runs); !schedule.NextRunAt.Equal(want) {
+					t.Fatalf("occurrence %d at %v, want %v", runs, schedule.NextRunAt, want)
+				}
+				schedule.Advance()
+				runs++
+			}
+			if runs != tt.runs || schedule.Occurrences != tt.runs {
+				t.Errorf("ran %d times with %d occurrences recorded, want %d", runs, schedule.Occurrences, tt.runs)
+			}
+		})
+	}
+}
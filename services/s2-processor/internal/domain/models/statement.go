@@ -0,0 +1,38 @@
+package models
+
+import (
+	"time"
+)
+
+// Statement lists the transactions of an account dated in [From, To), in
+// order, with the balance before the first and after each of them
+type Statement struct {
+	Account        Account
+	From           time.Time
+	To             time.Time
+	OpeningBalance float64
+	ClosingBalance float64
+	Lines          []StatementLine
+	Totals         StatementTotals
+}
+
+// StatementLine is a transaction on a statement with the account balance
+// after it. Transfer legs carry their transfer and exchange legs their
+// trade, whose exchange rate and quotation priced any currency conversion.
+type StatementLine struct {
+	Transaction Transaction
+	Balance     float64
+	Transfer    *AccountTransfer
+	Trade       *Trade
+}
+
+// StatementTotals sums the transactions of a statement by type. All totals
+// are positive.
+type StatementTotals struct {
+	Income       float64
+	Expense      float64
+	TransfersIn  float64
+	TransfersOut float64
+	TradesIn     float64
+	TradesOut    float64
+}
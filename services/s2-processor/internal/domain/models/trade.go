@@ -0,0 +1,69 @@
+package models
+
+import (
+	"time"
+)
+
+type TradeType string
+
+const (
+	TradeBuy  TradeType = "BUY"
+	TradeSell TradeType = "SELL"
+)
+
+type TradeStatus string
+
+const (
+	TradeCompleted TradeStatus = "COMPLETED"
+	TradeRejected  TradeStatus = "REJECTED"
+)
+
+// Trade is the outcome of a request to buy or sell currency. Rejected
+// requests are kept too, with the reason they were refused.
+//
+// A completed trade moves money between two accounts of the user:
+// BaseAccountID holds the pair's base currency and QuoteAccountID its quote
+// currency. A purchase pays TotalValue from the quote account and credits
+// Amount to the base account; a sale does the reverse.
+type Trade struct {
+	ID             uint
+	CorrelationID  string
+	UserID         uint
+	Type           TradeType
+	CurrencyPair   string
+	Amount         float64
+	ExchangeRate   float64
+	TotalValue     float64
+	QuotationID    string
+	Status         TradeStatus
+	Reason         string
+	BaseAccountID  uint
+	QuoteAccountID uint
+	RequestedAt    time.Time
+	CreatedAt      time.Time
+}
+
+// Legs returns the Exchange transactions a completed trade posts: debit
+// takes the paid amount from one account and credit adds the received
+// amount to the other. They are not linked to the trade yet.
+func (t *Trade) Legs() (debit, credit Transaction) {
+	debit = Transaction{
+		AccountID:   t.QuoteAccountID,
+		Amount:      -t.TotalValue,
+		Type:        Exchange,
+		Description: string(t.Type) + " " + t.CurrencyPair,
+		Date:        t.RequestedAt,
+	}
+	credit = Transaction{
+		AccountID:   t.BaseAccountID,
+		Amount:      t.Amount,
+		Type:        Exchange,
+		Description: debit.Description,
+		Date:        t.RequestedAt,
+	}
+	if t.Type == TradeSell {
+		debit.AccountID, credit.AccountID = t.BaseAccountID, t.QuoteAccountID
+		debit.Amount, credit.Amount = -t.Amount, t.TotalValue
+	}
+	return debit, credit
+}
@@ -10,16 +10,31 @@ const (
 	Income   TransactionType = "income"
 	Expense  TransactionType = "expense"
 	Transfer TransactionType = "transfer"
+	Exchange TransactionType = "exchange"
 )
 
+// Transaction is an entry in an account's ledger. Income and expense amounts
+// are positive. Transfer legs are signed and linked to their AccountTransfer
+// by TransferID; exchange legs are signed and linked to their Trade by
+// TradeID.
 type Transaction struct {
-	ID          uint            `json:"id" gorm:"primaryKey"`
-	AccountID   uint            `json:"account_id" gorm:"not null"`
-	CategoryID  uint            `json:"category_id"`
-	Amount      float64         `json:"amount" gorm:"not null"`
-	Type        TransactionType `json:"type" gorm:"not null"`
-	Description string          `json:"description"`
-	Date        time.Time       `json:"date" gorm:"not null"`
-	CreatedAt   time.Time       `json:"created_at"`
-	UpdatedAt   time.Time       `json:"updated_at"`
+	ID          uint
+	AccountID   uint
+	CategoryID  uint
+	TransferID  uint
+	TradeID     uint
+	Amount      float64
+	Type        TransactionType
+	Description string
+	Date        time.Time
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
+
+// BalanceDelta returns how the transaction changes its account's balance
+func (t *Transaction) BalanceDelta() float64 {
+	if t.Type == Expense {
+		return -t.Amount
+	}
+	return t.Amount
 }
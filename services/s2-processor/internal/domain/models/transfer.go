@@ -0,0 +1,24 @@
+package models
+
+import (
+	"time"
+)
+
+// AccountTransfer moves money from one account to another, possibly of
+// another user. When the currencies differ, Amount is converted at
+// ExchangeRate to ConvertedAmount. It is recorded as two Transfer
+// transactions, Debit on the source account with a negative amount and
+// Credit on the destination with a positive one.
+type AccountTransfer struct {
+	ID              uint
+	FromAccountID   uint
+	ToAccountID     uint
+	Amount          float64
+	ConvertedAmount float64
+	ExchangeRate    float64
+	QuotationID     string
+	Description     string
+	CreatedAt       time.Time
+	Debit           Transaction
+	Credit          Transaction
+}
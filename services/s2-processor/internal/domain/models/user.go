@@ -1,20 +1,37 @@
 package models
 
 import (
+	"strings"
 	"time"
 
 	"golang.org/x/crypto/bcrypt"
 )
 
+// User is a customer of the bank. Password holds the bcrypt hash once
+// HashPassword has been called. Users created by other services, such as
+// the generator, have an ExternalID: the ID they have there, which never
+// looks like one of ours.
 type User struct {
-	ID        uint      `json:"id" gorm:"primaryKey"`
-	Email     string    `json:"email" gorm:"uniqueIndex;not null"`
-	Password  string    `json:"-" gorm:"not null"`
-	FirstName string    `json:"first_name"`
-	LastName  string    `json:"last_name"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
-	Accounts  []Account `json:"accounts,omitempty" gorm:"foreignKey:UserID"`
+	ID         uint
+	ExternalID string
+	Email      string
+	Password   string
+	FirstName  string
+	LastName   string
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+}
+
+// Name returns the user's full name
+func (u *User) Name() string {
+	return strings.TrimSpace(u.FirstName + " " + u.LastName)
+}
+
+// SetName splits a full name into first and last name at the first space
+func (u *User) SetName(name string) {
+	first, last, _ := strings.Cut(strings.TrimSpace(name), " ")
+	u.FirstName = first
+	u.LastName = strings.TrimSpace(last)
 }
 
 func (u *User) HashPassword() error {
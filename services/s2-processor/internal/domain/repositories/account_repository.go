@@ -3,67 +3,116 @@ package repositories
 import (
 	"context"
 	"errors"
+	"time"
 
-	"github.com/leandroalencar/banco-dados/shared/models"
+	"github.com/leandroalencar/banco-dados/services/s2-processor/internal/domain/models"
 	"gorm.io/gorm"
 )
 
-// AccountRepository handles database operations for accounts
-type AccountRepository struct {
+var (
+	// ErrAccountNotFound is returned when no open account matches a lookup
+	ErrAccountNotFound = errors.New("account not found")
+
+	// ErrAccountNotEmpty is returned when closing an account that still
+	// holds money
+	ErrAccountNotEmpty = errors.New("account balance is not zero")
+)
+
+// PostgresAccountRepository handles database operations for accounts
+type PostgresAccountRepository struct {
 	db *gorm.DB
 }
 
-// NewAccountRepository creates a new account repository
-func NewAccountRepository(db *gorm.DB) *AccountRepository {
-	return &AccountRepository{db: db}
+// NewPostgresAccountRepository creates a new PostgreSQL-backed account repository
+func NewPostgresAccountRepository(db *gorm.DB) *PostgresAccountRepository {
+	return &PostgresAccountRepository{db: db}
 }
 
 // Create adds a new account to the database
-func (r *AccountRepository) Create(ctx context.Context, account *models.User) error {
-	return r.db.WithContext(ctx).Create(account).Error
+func (r *PostgresAccountRepository) Create(ctx context.Context, account *models.Account) error {
+	record := newAccountRecord(account)
+	if err := conn(ctx, r.db).Create(record).Error; err != nil {
+		return err
+	}
+	*account = *record.toModel()
+	return nil
 }
 
 // GetByID retrieves an account by ID
-func (r *AccountRepository) GetByID(ctx context.Context, id string) (*models.User, error) {
-	var account models.User
-	if err := r.db.WithContext(ctx).First(&account, "id = ?", id).Error; err != nil {
+func (r *PostgresAccountRepository) GetByID(ctx context.Context, id uint) (*models.Account, error) {
+	var record accountRecord
+	if err := conn(ctx, r.db).First(&record, id).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, errors.New("account not found")
+			return nil, ErrAccountNotFound
 		}
 		return nil, err
 	}
-	return &account, nil
+	return record.toModel(), nil
 }
 
 // GetAllByUserID retrieves all accounts for a user
-func (r *AccountRepository) GetAllByUserID(ctx context.Context, userID string) ([]models.User, error) {
-	var accounts []models.User
-	if err := r.db.WithContext(ctx).Where("user_id = ?", userID).Find(&accounts).Error; err != nil {
+func (r *PostgresAccountRepository) GetAllByUserID(ctx context.Context, userID uint) ([]models.Account, error) {
+	var records []accountRecord
+	if err := conn(ctx, r.db).Where("user_id = ?", userID).Order("id").Find(&records).Error; err != nil {
 		return nil, err
 	}
+	accounts := make([]models.Account, len(records))
+	for i := range records {
+		accounts[i] = *records[i].toModel()
+	}
 	return accounts, nil
 }
 
-// Update updates an existing account
-func (r *AccountRepository) Update(ctx context.Context, account *models.User) error {
-	return r.db.WithContext(ctx).Save(account).Error
-}
-
-// Delete removes an account from the database
-func (r *AccountRepository) Delete(ctx context.Context, id string) error {
-	return r.db.WithContext(ctx).Delete(&models.User{}, "id = ?", id).Error
+// Update updates an existing account. The balance is left alone, as it only
+// changes through UpdateBalance, and closed accounts can't be updated.
+func (r *PostgresAccountRepository) Update(ctx context.Context, account *models.Account) error {
+	record := newAccountRecord(account)
+	record.UpdatedAt = time.Now()
+	result := conn(ctx, r.db).Model(record).
+		Select("*").
+		Omit("id", "balance", "created_at", "deleted_at").
+		Updates(record)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrAccountNotFound
+	}
+	account.UpdatedAt = record.UpdatedAt
+	return nil
 }
 
-// UpdateBalance updates the balance of an account
-func (r *AccountRepository) UpdateBalance(ctx context.Context, id string, amount float64) error {
-	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
-		var account models.User
-		if err := tx.WithContext(ctx).First(&account, "id = ?", id).Error; err != nil {
+// Delete closes an account. The row is kept, marked as deleted, so past
+// transactions still reference it. Accounts with a non-zero balance are not
+// closed; the check and the update are one statement, so a concurrent
+// deposit can't slip in between.
+func (r *PostgresAccountRepository) Delete(ctx context.Context, id uint) error {
+	result := conn(ctx, r.db).Where("balance = 0").Delete(&accountRecord{}, id)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		if _, err := r.GetByID(ctx, id); err != nil {
 			return err
 		}
+		return ErrAccountNotEmpty
+	}
+	return nil
+}
 
-		account.Balance += amount
-
-		return tx.WithContext(ctx).Save(&account).Error
-	})
+// UpdateBalance adds amount to the balance of an account
+func (r *PostgresAccountRepository) UpdateBalance(ctx context.Context, id uint, amount float64) error {
+	result := conn(ctx, r.db).Model(&accountRecord{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"balance":    gorm.Expr("balance + ?", amount),
+			"updated_at": gorm.Expr("now()"),
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrAccountNotFound
+	}
+	return nil
 }
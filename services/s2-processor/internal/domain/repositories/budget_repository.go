@@ -0,0 +1,92 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/leandroalencar/banco-dados/services/s2-processor/internal/domain/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ErrBudgetNotFound is returned when no budget matches a lookup
+var ErrBudgetNotFound = errors.New("budget not found")
+
+// PostgresBudgetRepository handles database operations for budgets
+type PostgresBudgetRepository struct {
+	db *gorm.DB
+}
+
+// NewPostgresBudgetRepository creates a new PostgreSQL-backed budget repository
+func NewPostgresBudgetRepository(db *gorm.DB) *PostgresBudgetRepository {
+	return &PostgresBudgetRepository{db: db}
+}
+
+// Create adds a new budget to the database
+func (r *PostgresBudgetRepository) Create(ctx context.Context, budget *models.Budget) error {
+	record := newBudgetRecord(budget)
+	if err := conn(ctx, r.db).Create(record).Error; err != nil {
+		return err
+	}
+	*budget = *record.toModel()
+	return nil
+}
+
+// GetByID retrieves a budget by ID
+func (r *PostgresBudgetRepository) GetByID(ctx context.Context, id uint) (*models.Budget, error) {
+	var record budgetRecord
+	if err := conn(ctx, r.db).First(&record, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrBudgetNotFound
+		}
+		return nil, err
+	}
+	return record.toModel(), nil
+}
+
+// GetAllByUserID retrieves all budgets of a user
+func (r *PostgresBudgetRepository) GetAllByUserID(ctx context.Context, userID uint) ([]models.Budget, error) {
+	var records []budgetRecord
+	if err := conn(ctx, r.db).Where("user_id = ?", userID).Order("id").Find(&records).Error; err != nil {
+		return nil, err
+	}
+	budgets := make([]models.Budget, len(records))
+	for i := range records {
+		budgets[i] = *records[i].toModel()
+	}
+	return budgets, nil
+}
+
+// Update stores the limit and thresholds of an existing budget
+func (r *PostgresBudgetRepository) Update(ctx context.Context, budget *models.Budget) error {
+	record := newBudgetRecord(budget)
+	record.UpdatedAt = time.Now()
+	result := conn(ctx, r.db).Model(record).
+		Select("limit_amount", "thresholds", "updated_at").
+		Updates(record)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrBudgetNotFound
+	}
+	budget.UpdatedAt = record.UpdatedAt
+	return nil
+}
+
+// Delete removes a budget and its alerts from the database
+func (r *PostgresBudgetRepository) Delete(ctx context.Context, id uint) error {
+	return conn(ctx, r.db).Delete(&budgetRecord{}, id).Error
+}
+
+// RecordAlert records that a budget reached threshold in month. Concurrent
+// callers race on the primary key, so only one of them sees true.
+func (r *PostgresBudgetRepository) RecordAlert(ctx context.Context, budgetID uint, month time.Time, threshold int) (bool, error) {
+	result := conn(ctx, r.db).Clauses(clause.OnConflict{DoNothing: true}).Create(&budgetAlertRecord{
+		BudgetID:  budgetID,
+		Month:     month,
+		Threshold: threshold,
+	})
+	return result.RowsAffected > 0, result.Error
+}
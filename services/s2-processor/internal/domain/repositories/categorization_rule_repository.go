@@ -0,0 +1,82 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/leandroalencar/banco-dados/services/s2-processor/internal/domain/models"
+	"gorm.io/gorm"
+)
+
+// ErrRuleNotFound is returned when no categorization rule matches a lookup
+var ErrRuleNotFound = errors.New("categorization rule not found")
+
+// PostgresCategorizationRuleRepository handles database operations for
+// categorization rules
+type PostgresCategorizationRuleRepository struct {
+	db *gorm.DB
+}
+
+// NewPostgresCategorizationRuleRepository creates a new PostgreSQL-backed categorization rule repository
+func NewPostgresCategorizationRuleRepository(db *gorm.DB) *PostgresCategorizationRuleRepository {
+	return &PostgresCategorizationRuleRepository{db: db}
+}
+
+// Create adds a new rule to the database
+func (r *PostgresCategorizationRuleRepository) Create(ctx context.Context, rule *models.CategorizationRule) error {
+	record := newCategorizationRuleRecord(rule)
+	if err := conn(ctx, r.db).Create(record).Error; err != nil {
+		return err
+	}
+	*rule = *record.toModel()
+	return nil
+}
+
+// GetByID retrieves a rule by ID
+func (r *PostgresCategorizationRuleRepository) GetByID(ctx context.Context, id uint) (*models.CategorizationRule, error) {
+	var record categorizationRuleRecord
+	if err := conn(ctx, r.db).First(&record, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrRuleNotFound
+		}
+		return nil, err
+	}
+	return record.toModel(), nil
+}
+
+// GetAllByUserID retrieves the rules of a user in the order they are tried
+func (r *PostgresCategorizationRuleRepository) GetAllByUserID(ctx context.Context, userID uint) ([]models.CategorizationRule, error) {
+	var records []categorizationRuleRecord
+	if err := conn(ctx, r.db).Where("user_id = ?", userID).Order("priority, id").Find(&records).Error; err != nil {
+		return nil, err
+	}
+	rules := make([]models.CategorizationRule, len(records))
+	for i := range records {
+		rules[i] = *records[i].toModel()
+	}
+	return rules, nil
+}
+
+// Update replaces the conditions of an existing rule
+func (r *PostgresCategorizationRuleRepository) Update(ctx context.Context, rule *models.CategorizationRule) error {
+	record := newCategorizationRuleRecord(rule)
+	record.UpdatedAt = time.Now()
+	result := conn(ctx, r.db).Model(record).
+		Select("*").
+		Omit("id", "user_id", "created_at").
+		Updates(record)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrRuleNotFound
+	}
+	rule.UpdatedAt = record.UpdatedAt
+	return nil
+}
+
+// Delete removes a rule from the database
+func (r *PostgresCategorizationRuleRepository) Delete(ctx context.Context, id uint) error {
+	return conn(ctx, r.db).Delete(&categorizationRuleRecord{}, id).Error
+}
@@ -0,0 +1,100 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/leandroalencar/banco-dados/services/s2-processor/internal/domain/models"
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrCategoryNotFound is returned when no category matches a lookup
+	ErrCategoryNotFound = errors.New("category not found")
+
+	// ErrCategoryHasChildren is returned when deleting a category that
+	// still has subcategories
+	ErrCategoryHasChildren = errors.New("category has subcategories")
+)
+
+// PostgresCategoryRepository handles database operations for categories
+type PostgresCategoryRepository struct {
+	db *gorm.DB
+}
+
+// NewPostgresCategoryRepository creates a new PostgreSQL-backed category repository
+func NewPostgresCategoryRepository(db *gorm.DB) *PostgresCategoryRepository {
+	return &PostgresCategoryRepository{db: db}
+}
+
+// Create adds a new category to the database
+func (r *PostgresCategoryRepository) Create(ctx context.Context, category *models.Category) error {
+	record := newCategoryRecord(category)
+	if err := conn(ctx, r.db).Create(record).Error; err != nil {
+		return err
+	}
+	*category = *record.toModel()
+	return nil
+}
+
+// GetByID retrieves a category by ID
+func (r *PostgresCategoryRepository) GetByID(ctx context.Context, id uint) (*models.Category, error) {
+	var record categoryRecord
+	if err := conn(ctx, r.db).First(&record, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrCategoryNotFound
+		}
+		return nil, err
+	}
+	return record.toModel(), nil
+}
+
+// GetAllForUser retrieves the system categories and those of a user
+func (r *PostgresCategoryRepository) GetAllForUser(ctx context.Context, userID uint) ([]models.Category, error) {
+	var records []categoryRecord
+	if err := conn(ctx, r.db).
+		Where("user_id IS NULL OR user_id = ?", userID).
+		Order("id").
+		Find(&records).Error; err != nil {
+		return nil, err
+	}
+	categories := make([]models.Category, len(records))
+	for i := range records {
+		categories[i] = *records[i].toModel()
+	}
+	return categories, nil
+}
+
+// Update updates the name and parent of a category
+func (r *PostgresCategoryRepository) Update(ctx context.Context, category *models.Category) error {
+	category.UpdatedAt = time.Now()
+	result := conn(ctx, r.db).Model(&categoryRecord{ID: category.ID}).
+		Updates(map[string]interface{}{
+			"name":       category.Name,
+			"parent_id":  nullableID(category.ParentID),
+			"updated_at": category.UpdatedAt,
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrCategoryNotFound
+	}
+	return nil
+}
+
+// Delete removes a category without subcategories. Its transactions become
+// uncategorized.
+func (r *PostgresCategoryRepository) Delete(ctx context.Context, id uint) error {
+	return conn(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		var children int64
+		if err := tx.Model(&categoryRecord{}).Where("parent_id = ?", id).Count(&children).Error; err != nil {
+			return err
+		}
+		if children > 0 {
+			return ErrCategoryHasChildren
+		}
+		return tx.Delete(&categoryRecord{}, id).Error
+	})
+}
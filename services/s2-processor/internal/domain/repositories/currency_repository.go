@@ -2,22 +2,120 @@ package repositories
 
 import (
 	"context"
+	"errors"
+	"time"
 
 	"github.com/leandroalencar/banco-dados/services/s2-processor/internal/domain/models"
+	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
-type CurrencyRepository struct {
+// ErrDuplicateQuote is returned when inserting a quote whose ID is taken
+var ErrDuplicateQuote = errors.New("duplicate quote")
+
+// currencyDocument is how a quote is stored in MongoDB
+type currencyDocument struct {
+	ID         string    `bson:"_id,omitempty"`
+	Code       string    `bson:"code"`
+	Codein     string    `bson:"codein"`
+	Name       string    `bson:"name"`
+	High       string    `bson:"high"`
+	Low        string    `bson:"low"`
+	VarBid     string    `bson:"var_bid"`
+	PctChange  string    `bson:"pct_change"`
+	Bid        string    `bson:"bid"`
+	Ask        string    `bson:"ask"`
+	Timestamp  string    `bson:"timestamp"`
+	CreateDate string    `bson:"create_date"`
+	QuotedAt   time.Time `bson:"quoted_at"`
+}
+
+func newCurrencyDocument(c *models.Currency) *currencyDocument {
+	return &currencyDocument{
+		ID:         c.ID,
+		Code:       c.Code,
+		Codein:     c.Codein,
+		Name:       c.Name,
+		High:       c.High,
+		Low:        c.Low,
+		VarBid:     c.VarBid,
+		PctChange:  c.PctChange,
+		Bid:        c.Bid,
+		Ask:        c.Ask,
+		Timestamp:  c.Timestamp,
+		CreateDate: c.CreateDate,
+		QuotedAt:   c.QuotedAt,
+	}
+}
+
+func (d *currencyDocument) toModel() *models.Currency {
+	return &models.Currency{
+		ID:         d.ID,
+		Code:       d.Code,
+		Codein:     d.Codein,
+		Name:       d.Name,
+		High:       d.High,
+		Low:        d.Low,
+		VarBid:     d.VarBid,
+		PctChange:  d.PctChange,
+		Bid:        d.Bid,
+		Ask:        d.Ask,
+		Timestamp:  d.Timestamp,
+		CreateDate: d.CreateDate,
+		QuotedAt:   d.QuotedAt,
+	}
+}
+
+type MongoCurrencyRepository struct {
 	collection *mongo.Collection
 }
 
-func NewCurrencyRepository(db *mongo.Database) *CurrencyRepository {
-	return &CurrencyRepository{
+func NewMongoCurrencyRepository(db *mongo.Database) *MongoCurrencyRepository {
+	return &MongoCurrencyRepository{
 		collection: db.Collection("exchange_rates"),
 	}
 }
 
-func (r *CurrencyRepository) Insert(ctx context.Context, currency *models.Currency) error {
-	_, err := r.collection.InsertOne(ctx, currency)
+// EnsureIndexes creates the index used to look up the latest quote of a pair
+func (r *MongoCurrencyRepository) EnsureIndexes(ctx context.Context) error {
+	_, err := r.collection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{{Key: "code", Value: 1}, {Key: "codein", Value: 1}, {Key: "quoted_at", Value: -1}},
+	})
 	return err
 }
+
+// Insert stores a quote, failing with ErrDuplicateQuote if its ID is taken
+func (r *MongoCurrencyRepository) Insert(ctx context.Context, currency *models.Currency) error {
+	_, err := r.collection.InsertOne(ctx, newCurrencyDocument(currency))
+	if mongo.IsDuplicateKeyError(err) {
+		return ErrDuplicateQuote
+	}
+	return err
+}
+
+// GetByID retrieves a quote by ID
+func (r *MongoCurrencyRepository) GetByID(ctx context.Context, id string) (*models.Currency, error) {
+	var document currencyDocument
+	if err := r.collection.FindOne(ctx, bson.M{"_id": id}).Decode(&document); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, errors.New("currency not found")
+		}
+		return nil, err
+	}
+	return document.toModel(), nil
+}
+
+// GetLatest retrieves the most recent quote of code in codein. It returns
+// nil without error when the pair was never quoted.
+func (r *MongoCurrencyRepository) GetLatest(ctx context.Context, code, codein string) (*models.Currency, error) {
+	var document currencyDocument
+	opts := options.FindOne().SetSort(bson.D{{Key: "quoted_at", Value: -1}})
+	if err := r.collection.FindOne(ctx, bson.M{"code": code, "codein": codein}, opts).Decode(&document); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return document.toModel(), nil
+}
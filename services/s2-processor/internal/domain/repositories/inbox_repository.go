@@ -0,0 +1,54 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// PostgresInboxRepository handles database operations for processed messages
+type PostgresInboxRepository struct {
+	db *gorm.DB
+}
+
+// NewPostgresInboxRepository creates a new PostgreSQL-backed inbox repository
+func NewPostgresInboxRepository(db *gorm.DB) *PostgresInboxRepository {
+	return &PostgresInboxRepository{db: db}
+}
+
+// ProcessOnce runs fn in a transaction, which fn's context joins, unless
+// messageID was already processed from queue. The message is recorded in the
+// same transaction as fn's writes, so either both are committed or neither
+// is. It reports whether fn ran.
+func (r *PostgresInboxRepository) ProcessOnce(ctx context.Context, queue, messageID string, fn func(ctx context.Context) error) (bool, error) {
+	processed := false
+	err := conn(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		// A concurrent consumer holding the same ID blocks here until it
+		// commits, after which the insert conflicts and we skip.
+		result := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&processedMessageRecord{
+			MessageID:   messageID,
+			Queue:       queue,
+			ProcessedAt: time.Now(),
+		})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return nil
+		}
+
+		processed = true
+		return fn(withTx(ctx, tx))
+	})
+	if err != nil {
+		return false, err
+	}
+	return processed, nil
+}
+
+// DeleteOlderThan removes records processed before cutoff
+func (r *PostgresInboxRepository) DeleteOlderThan(ctx context.Context, cutoff time.Time) error {
+	return conn(ctx, r.db).Where("processed_at < ?", cutoff).Delete(&processedMessageRecord{}).Error
+}
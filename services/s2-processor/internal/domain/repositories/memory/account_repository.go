@@ -0,0 +1,126 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/leandroalencar/banco-dados/services/s2-processor/internal/domain/models"
+	"github.com/leandroalencar/banco-dados/services/s2-processor/internal/domain/repositories"
+)
+
+// AccountRepository keeps accounts in memory
+type AccountRepository struct {
+	mu       sync.RWMutex
+	accounts map[uint]models.Account
+	nextID   uint
+}
+
+// NewAccountRepository creates an empty in-memory account repository
+func NewAccountRepository() *AccountRepository {
+	return &AccountRepository{accounts: make(map[uint]models.Account), nextID: 1}
+}
+
+// Create adds a new account
+func (r *AccountRepository) Create(ctx context.Context, account *models.Account) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	account.ID = r.nextID
+	account.CreatedAt = now
+	account.UpdatedAt = now
+	if account.Currency == "" {
+		account.Currency = "BRL"
+	}
+	r.nextID++
+	r.accounts[account.ID] = *account
+	return nil
+}
+
+// GetByID retrieves an account by ID
+func (r *AccountRepository) GetByID(ctx context.Context, id uint) (*models.Account, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	account, ok := r.accounts[id]
+	if !ok {
+		return nil, repositories.ErrAccountNotFound
+	}
+	return &account, nil
+}
+
+// GetAllByUserID retrieves all accounts for a user
+func (r *AccountRepository) GetAllByUserID(ctx context.Context, userID uint) ([]models.Account, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	accounts := []models.Account{}
+	for _, account := range r.accounts {
+		if account.UserID == userID {
+			accounts = append(accounts, account)
+		}
+	}
+	sort.Slice(accounts, func(i, j int) bool {
+		return accounts[i].ID < accounts[j].ID
+	})
+	return accounts, nil
+}
+
+// Update replaces an existing account, keeping its balance
+func (r *AccountRepository) Update(ctx context.Context, account *models.Account) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	existing, ok := r.accounts[account.ID]
+	if !ok {
+		return repositories.ErrAccountNotFound
+	}
+	updated := *account
+	updated.Balance = existing.Balance
+	updated.CreatedAt = existing.CreatedAt
+	updated.UpdatedAt = time.Now()
+	r.accounts[account.ID] = updated
+	account.UpdatedAt = updated.UpdatedAt
+	return nil
+}
+
+// Delete removes an account unless its balance is not zero
+func (r *AccountRepository) Delete(ctx context.Context, id uint) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	account, ok := r.accounts[id]
+	if !ok {
+		return repositories.ErrAccountNotFound
+	}
+	if account.Balance != 0 {
+		return repositories.ErrAccountNotEmpty
+	}
+	delete(r.accounts, id)
+	return nil
+}
+
+// UpdateBalance adds amount to the balance of an account
+func (r *AccountRepository) UpdateBalance(ctx context.Context, id uint, amount float64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	account, ok := r.accounts[id]
+	if !ok {
+		return repositories.ErrAccountNotFound
+	}
+	account.Balance += amount
+	account.UpdatedAt = time.Now()
+	r.accounts[id] = account
+	return nil
+}
+
+// owner returns the user an account belongs to, or 0 if it doesn't exist
+func (r *AccountRepository) owner(id uint) uint {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.accounts[id].UserID
+}
@@ -0,0 +1,123 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/leandroalencar/banco-dados/services/s2-processor/internal/domain/models"
+	"github.com/leandroalencar/banco-dados/services/s2-processor/internal/domain/repositories"
+)
+
+// budgetAlert identifies an alert a budget raised
+type budgetAlert struct {
+	budgetID  uint
+	month     time.Time
+	threshold int
+}
+
+// BudgetRepository keeps budgets and their alerts in memory
+type BudgetRepository struct {
+	mu      sync.RWMutex
+	budgets map[uint]models.Budget
+	alerts  map[budgetAlert]bool
+	nextID  uint
+}
+
+// NewBudgetRepository creates an empty in-memory budget repository
+func NewBudgetRepository() *BudgetRepository {
+	return &BudgetRepository{
+		budgets: make(map[uint]models.Budget),
+		alerts:  make(map[budgetAlert]bool),
+		nextID:  1,
+	}
+}
+
+// Create adds a new budget
+func (r *BudgetRepository) Create(ctx context.Context, budget *models.Budget) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	budget.ID = r.nextID
+	budget.CreatedAt = now
+	budget.UpdatedAt = now
+	r.nextID++
+	r.budgets[budget.ID] = *budget
+	return nil
+}
+
+// GetByID retrieves a budget by ID
+func (r *BudgetRepository) GetByID(ctx context.Context, id uint) (*models.Budget, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	budget, ok := r.budgets[id]
+	if !ok {
+		return nil, repositories.ErrBudgetNotFound
+	}
+	return &budget, nil
+}
+
+// GetAllByUserID retrieves all budgets of a user
+func (r *BudgetRepository) GetAllByUserID(ctx context.Context, userID uint) ([]models.Budget, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	budgets := []models.Budget{}
+	for _, budget := range r.budgets {
+		if budget.UserID == userID {
+			budgets = append(budgets, budget)
+		}
+	}
+	sort.Slice(budgets, func(i, j int) bool {
+		return budgets[i].ID < budgets[j].ID
+	})
+	return budgets, nil
+}
+
+// Update stores the limit and thresholds of an existing budget
+func (r *BudgetRepository) Update(ctx context.Context, budget *models.Budget) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	existing, ok := r.budgets[budget.ID]
+	if !ok {
+		return repositories.ErrBudgetNotFound
+	}
+	existing.Limit = budget.Limit
+	existing.Thresholds = budget.Thresholds
+	existing.UpdatedAt = time.Now()
+	r.budgets[budget.ID] = existing
+	budget.UpdatedAt = existing.UpdatedAt
+	return nil
+}
+
+// Delete removes a budget and its alerts
+func (r *BudgetRepository) Delete(ctx context.Context, id uint) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.budgets, id)
+	for alert := range r.alerts {
+		if alert.budgetID == id {
+			delete(r.alerts, alert)
+		}
+	}
+	return nil
+}
+
+// RecordAlert records that a budget reached threshold in month, unless the
+// alert was already recorded
+func (r *BudgetRepository) RecordAlert(ctx context.Context, budgetID uint, month time.Time, threshold int) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	alert := budgetAlert{budgetID: budgetID, month: month, threshold: threshold}
+	if r.alerts[alert] {
+		return false, nil
+	}
+	r.alerts[alert] = true
+	return true, nil
+}
@@ -0,0 +1,96 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/leandroalencar/banco-dados/services/s2-processor/internal/domain/models"
+	"github.com/leandroalencar/banco-dados/services/s2-processor/internal/domain/repositories"
+)
+
+// CategorizationRuleRepository keeps categorization rules in memory
+type CategorizationRuleRepository struct {
+	mu     sync.RWMutex
+	rules  map[uint]models.CategorizationRule
+	nextID uint
+}
+
+// NewCategorizationRuleRepository creates an empty in-memory categorization rule repository
+func NewCategorizationRuleRepository() *CategorizationRuleRepository {
+	return &CategorizationRuleRepository{rules: make(map[uint]models.CategorizationRule), nextID: 1}
+}
+
+// Create adds a new rule
+func (r *CategorizationRuleRepository) Create(ctx context.Context, rule *models.CategorizationRule) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	rule.ID = r.nextID
+	rule.CreatedAt = now
+	rule.UpdatedAt = now
+	r.nextID++
+	r.rules[rule.ID] = *rule
+	return nil
+}
+
+// GetByID retrieves a rule by ID
+func (r *CategorizationRuleRepository) GetByID(ctx context.Context, id uint) (*models.CategorizationRule, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	rule, ok := r.rules[id]
+	if !ok {
+		return nil, repositories.ErrRuleNotFound
+	}
+	return &rule, nil
+}
+
+// GetAllByUserID retrieves the rules of a user in the order they are tried
+func (r *CategorizationRuleRepository) GetAllByUserID(ctx context.Context, userID uint) ([]models.CategorizationRule, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	rules := []models.CategorizationRule{}
+	for _, rule := range r.rules {
+		if rule.UserID == userID {
+			rules = append(rules, rule)
+		}
+	}
+	sort.Slice(rules, func(i, j int) bool {
+		if rules[i].Priority != rules[j].Priority {
+			return rules[i].Priority < rules[j].Priority
+		}
+		return rules[i].ID < rules[j].ID
+	})
+	return rules, nil
+}
+
+// Update replaces the conditions of an existing rule
+func (r *CategorizationRuleRepository) Update(ctx context.Context, rule *models.CategorizationRule) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	existing, ok := r.rules[rule.ID]
+	if !ok {
+		return repositories.ErrRuleNotFound
+	}
+	updated := *rule
+	updated.UserID = existing.UserID
+	updated.CreatedAt = existing.CreatedAt
+	updated.UpdatedAt = time.Now()
+	r.rules[rule.ID] = updated
+	rule.UpdatedAt = updated.UpdatedAt
+	return nil
+}
+
+// Delete removes a rule
+func (r *CategorizationRuleRepository) Delete(ctx context.Context, id uint) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.rules, id)
+	return nil
+}
@@ -0,0 +1,98 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/leandroalencar/banco-dados/services/s2-processor/internal/domain/models"
+	"github.com/leandroalencar/banco-dados/services/s2-processor/internal/domain/repositories"
+)
+
+// CategoryRepository keeps categories in memory. Unlike PostgreSQL it has
+// no system defaults until they are created with a zero UserID.
+type CategoryRepository struct {
+	mu         sync.RWMutex
+	categories map[uint]models.Category
+	nextID     uint
+}
+
+// NewCategoryRepository creates an empty in-memory category repository
+func NewCategoryRepository() *CategoryRepository {
+	return &CategoryRepository{categories: make(map[uint]models.Category), nextID: 1}
+}
+
+// Create adds a new category
+func (r *CategoryRepository) Create(ctx context.Context, category *models.Category) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	category.ID = r.nextID
+	category.CreatedAt = now
+	category.UpdatedAt = now
+	r.nextID++
+	r.categories[category.ID] = *category
+	return nil
+}
+
+// GetByID retrieves a category by ID
+func (r *CategoryRepository) GetByID(ctx context.Context, id uint) (*models.Category, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	category, ok := r.categories[id]
+	if !ok {
+		return nil, repositories.ErrCategoryNotFound
+	}
+	return &category, nil
+}
+
+// GetAllForUser retrieves the system categories and those of a user
+func (r *CategoryRepository) GetAllForUser(ctx context.Context, userID uint) ([]models.Category, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	categories := []models.Category{}
+	for _, category := range r.categories {
+		if category.VisibleTo(userID) {
+			categories = append(categories, category)
+		}
+	}
+	sort.Slice(categories, func(i, j int) bool {
+		return categories[i].ID < categories[j].ID
+	})
+	return categories, nil
+}
+
+// Update updates the name and parent of a category
+func (r *CategoryRepository) Update(ctx context.Context, category *models.Category) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	existing, ok := r.categories[category.ID]
+	if !ok {
+		return repositories.ErrCategoryNotFound
+	}
+	existing.Name = category.Name
+	existing.ParentID = category.ParentID
+	existing.UpdatedAt = time.Now()
+	r.categories[category.ID] = existing
+	category.UpdatedAt = existing.UpdatedAt
+	return nil
+}
+
+// Delete removes a category without subcategories
+func (r *CategoryRepository) Delete(ctx context.Context, id uint) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, category := range r.categories {
+		if category.ParentID == id {
+			return repositories.ErrCategoryHasChildren
+		}
+	}
+	delete(r.categories, id)
+	return nil
+}
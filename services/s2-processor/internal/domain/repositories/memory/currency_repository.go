@@ -0,0 +1,72 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"sync"
+
+	"github.com/leandroalencar/banco-dados/services/s2-processor/internal/domain/models"
+	"github.com/leandroalencar/banco-dados/services/s2-processor/internal/domain/repositories"
+)
+
+// CurrencyRepository keeps quotation snapshots in memory
+type CurrencyRepository struct {
+	mu         sync.RWMutex
+	currencies []models.Currency
+}
+
+// NewCurrencyRepository creates an empty in-memory currency repository
+func NewCurrencyRepository() *CurrencyRepository {
+	return &CurrencyRepository{}
+}
+
+// Insert stores a quote, assigning an ID when it has none. It fails with
+// ErrDuplicateQuote if the ID is taken.
+func (r *CurrencyRepository) Insert(ctx context.Context, currency *models.Currency) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if currency.ID == "" {
+		currency.ID = strconv.Itoa(len(r.currencies) + 1)
+	}
+	for _, existing := range r.currencies {
+		if existing.ID == currency.ID {
+			return repositories.ErrDuplicateQuote
+		}
+	}
+	r.currencies = append(r.currencies, *currency)
+	return nil
+}
+
+// GetByID retrieves a quote by ID
+func (r *CurrencyRepository) GetByID(ctx context.Context, id string) (*models.Currency, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, currency := range r.currencies {
+		if currency.ID == id {
+			return &currency, nil
+		}
+	}
+	return nil, errors.New("currency not found")
+}
+
+// GetLatest retrieves the most recent quote of code in codein. It returns
+// nil without error when the pair was never quoted.
+func (r *CurrencyRepository) GetLatest(ctx context.Context, code, codein string) (*models.Currency, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var latest *models.Currency
+	for i := range r.currencies {
+		currency := r.currencies[i]
+		if currency.Code != code || currency.Codein != codein {
+			continue
+		}
+		if latest == nil || currency.QuotedAt.After(latest.QuotedAt) {
+			latest = &currency
+		}
+	}
+	return latest, nil
+}
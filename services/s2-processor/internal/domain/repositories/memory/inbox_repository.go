@@ -0,0 +1,54 @@
+package memory
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// InboxRepository keeps processed message IDs in memory
+type InboxRepository struct {
+	mu        sync.Mutex
+	processed map[string]time.Time
+}
+
+// NewInboxRepository creates an empty in-memory inbox repository
+func NewInboxRepository() *InboxRepository {
+	return &InboxRepository{processed: make(map[string]time.Time)}
+}
+
+// ProcessOnce runs fn unless messageID was already processed from queue. A
+// failed fn leaves the message unrecorded so it can be retried. It reports
+// whether fn ran.
+func (r *InboxRepository) ProcessOnce(ctx context.Context, queue, messageID string, fn func(ctx context.Context) error) (bool, error) {
+	key := queue + "/" + messageID
+
+	r.mu.Lock()
+	if _, ok := r.processed[key]; ok {
+		r.mu.Unlock()
+		return false, nil
+	}
+	r.processed[key] = time.Now()
+	r.mu.Unlock()
+
+	if err := fn(ctx); err != nil {
+		r.mu.Lock()
+		delete(r.processed, key)
+		r.mu.Unlock()
+		return false, err
+	}
+	return true, nil
+}
+
+// DeleteOlderThan removes records processed before cutoff
+func (r *InboxRepository) DeleteOlderThan(ctx context.Context, cutoff time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for key, processedAt := range r.processed {
+		if processedAt.Before(cutoff) {
+			delete(r.processed, key)
+		}
+	}
+	return nil
+}
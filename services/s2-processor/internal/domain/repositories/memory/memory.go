@@ -0,0 +1,22 @@
+// Package memory provides in-memory repository implementations for testing
+// the service layer and HTTP handlers without running any database.
+package memory
+
+import "github.com/leandroalencar/banco-dados/services/s2-processor/internal/domain/repositories"
+
+var (
+	_ repositories.UnitOfWork                   = (*UnitOfWork)(nil)
+	_ repositories.UserRepository               = (*UserRepository)(nil)
+	_ repositories.AccountRepository            = (*AccountRepository)(nil)
+	_ repositories.TransactionRepository        = (*TransactionRepository)(nil)
+	_ repositories.TransferRepository           = (*TransferRepository)(nil)
+	_ repositories.CategoryRepository           = (*CategoryRepository)(nil)
+	_ repositories.CategorizationRuleRepository = (*CategorizationRuleRepository)(nil)
+	_ repositories.ScheduleRepository           = (*ScheduleRepository)(nil)
+	_ repositories.BudgetRepository             = (*BudgetRepository)(nil)
+	_ repositories.CurrencyRepository           = (*CurrencyRepository)(nil)
+	_ repositories.OutboxRepository             = (*OutboxRepository)(nil)
+	_ repositories.InboxRepository              = (*InboxRepository)(nil)
+	_ repositories.TradeRepository              = (*TradeRepository)(nil)
+	_ repositories.TransactionHistoryRepository = (*TransactionHistoryRepository)(nil)
+)
@@ -0,0 +1,103 @@
+package memory
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/leandroalencar/banco-dados/services/s2-processor/internal/domain/models"
+	"github.com/leandroalencar/banco-dados/shared/utils"
+)
+
+// OutboxRepository keeps outbox messages in memory
+type OutboxRepository struct {
+	mu       sync.Mutex
+	messages map[uint]models.OutboxMessage
+	nextID   uint
+}
+
+// NewOutboxRepository creates an empty in-memory outbox repository
+func NewOutboxRepository() *OutboxRepository {
+	return &OutboxRepository{messages: make(map[uint]models.OutboxMessage), nextID: 1}
+}
+
+// Enqueue stores a message for later publishing
+func (r *OutboxRepository) Enqueue(ctx context.Context, queue string, message interface{}) error {
+	payload, err := json.Marshal(message)
+	if err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.messages[r.nextID] = models.OutboxMessage{
+		ID:        r.nextID,
+		MessageID: utils.NewMessageID(),
+		Queue:     queue,
+		Payload:   string(payload),
+		Status:    models.OutboxPending,
+		CreatedAt: time.Now(),
+	}
+	r.nextID++
+	return nil
+}
+
+// ProcessPending passes up to limit pending messages, oldest first, to fn
+func (r *OutboxRepository) ProcessPending(ctx context.Context, limit int, fn func(ctx context.Context, messages []models.OutboxMessage) error) error {
+	messages := r.Pending()
+	if len(messages) > limit {
+		messages = messages[:limit]
+	}
+	if len(messages) == 0 {
+		return nil
+	}
+	return fn(ctx, messages)
+}
+
+// MarkSent flags a message as published
+func (r *OutboxRepository) MarkSent(ctx context.Context, id uint) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if message, ok := r.messages[id]; ok {
+		now := time.Now()
+		message.Status = models.OutboxSent
+		message.SentAt = &now
+		message.Attempts++
+		r.messages[id] = message
+	}
+	return nil
+}
+
+// MarkFailed records a failed publishing attempt, leaving the message pending
+func (r *OutboxRepository) MarkFailed(ctx context.Context, id uint, cause error) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if message, ok := r.messages[id]; ok {
+		message.Attempts++
+		message.LastError = cause.Error()
+		r.messages[id] = message
+	}
+	return nil
+}
+
+// Pending returns the messages not yet published, oldest first
+func (r *OutboxRepository) Pending() []models.OutboxMessage {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	messages := []models.OutboxMessage{}
+	for _, message := range r.messages {
+		if message.Status == models.OutboxPending {
+			messages = append(messages, message)
+		}
+	}
+	sort.Slice(messages, func(i, j int) bool {
+		return messages[i].ID < messages[j].ID
+	})
+	return messages
+}
@@ -0,0 +1,127 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/leandroalencar/banco-dados/services/s2-processor/internal/domain/models"
+	"github.com/leandroalencar/banco-dados/services/s2-processor/internal/domain/repositories"
+)
+
+// ScheduleRepository keeps schedules in memory
+type ScheduleRepository struct {
+	mu        sync.RWMutex
+	schedules map[uint]models.Schedule
+	nextID    uint
+
+	// processing serializes ProcessDue, as row locks do in PostgreSQL
+	processing sync.Mutex
+}
+
+// NewScheduleRepository creates an empty in-memory schedule repository
+func NewScheduleRepository() *ScheduleRepository {
+	return &ScheduleRepository{schedules: make(map[uint]models.Schedule), nextID: 1}
+}
+
+// Create adds a new schedule
+func (r *ScheduleRepository) Create(ctx context.Context, schedule *models.Schedule) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	schedule.ID = r.nextID
+	schedule.CreatedAt = now
+	schedule.UpdatedAt = now
+	r.nextID++
+	r.schedules[schedule.ID] = *schedule
+	return nil
+}
+
+// GetByID retrieves a schedule by ID
+func (r *ScheduleRepository) GetByID(ctx context.Context, id uint) (*models.Schedule, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	schedule, ok := r.schedules[id]
+	if !ok {
+		return nil, repositories.ErrScheduleNotFound
+	}
+	return &schedule, nil
+}
+
+// GetAllByUserID retrieves all schedules of a user
+func (r *ScheduleRepository) GetAllByUserID(ctx context.Context, userID uint) ([]models.Schedule, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	schedules := []models.Schedule{}
+	for _, schedule := range r.schedules {
+		if schedule.UserID == userID {
+			schedules = append(schedules, schedule)
+		}
+	}
+	sort.Slice(schedules, func(i, j int) bool {
+		return schedules[i].ID < schedules[j].ID
+	})
+	return schedules, nil
+}
+
+// Delete removes a schedule
+func (r *ScheduleRepository) Delete(ctx context.Context, id uint) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.schedules, id)
+	return nil
+}
+
+// ProcessDue passes up to limit due schedules, earliest first, to fn and
+// saves each one's progress. Progress made before a failing schedule is
+// kept; the failing schedule's is not.
+func (r *ScheduleRepository) ProcessDue(ctx context.Context, now time.Time, limit int, fn func(ctx context.Context, schedule *models.Schedule) error) error {
+	r.processing.Lock()
+	defer r.processing.Unlock()
+
+	for _, schedule := range r.due(now, limit) {
+		if err := fn(ctx, &schedule); err != nil {
+			return err
+		}
+
+		r.mu.Lock()
+		if existing, ok := r.schedules[schedule.ID]; ok {
+			existing.Occurrences = schedule.Occurrences
+			existing.NextRunAt = schedule.NextRunAt
+			existing.LastError = schedule.LastError
+			existing.UpdatedAt = time.Now()
+			r.schedules[schedule.ID] = existing
+		}
+		r.mu.Unlock()
+	}
+	return nil
+}
+
+// due returns up to limit schedules whose next run is at or before now,
+// earliest first
+func (r *ScheduleRepository) due(now time.Time, limit int) []models.Schedule {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	schedules := []models.Schedule{}
+	for _, schedule := range r.schedules {
+		if schedule.NextRunAt != nil && !schedule.NextRunAt.After(now) {
+			schedules = append(schedules, schedule)
+		}
+	}
+	sort.Slice(schedules, func(i, j int) bool {
+		if !schedules[i].NextRunAt.Equal(*schedules[j].NextRunAt) {
+			return schedules[i].NextRunAt.Before(*schedules[j].NextRunAt)
+		}
+		return schedules[i].ID < schedules[j].ID
+	})
+	if len(schedules) > limit {
+		schedules = schedules[:limit]
+	}
+	return schedules
+}
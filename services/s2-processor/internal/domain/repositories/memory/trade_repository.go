@@ -0,0 +1,171 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/leandroalencar/banco-dados/services/s2-processor/internal/domain/models"
+	"github.com/leandroalencar/banco-dados/services/s2-processor/internal/domain/repositories"
+)
+
+// TradeRepository keeps trades in memory and posts completed ones to
+// in-memory accounts, recording their legs in an in-memory transaction
+// repository
+type TradeRepository struct {
+	mu           sync.RWMutex
+	accounts     *AccountRepository
+	transactions *TransactionRepository
+	trades       map[uint]models.Trade
+	nextID       uint
+}
+
+// NewTradeRepository creates an empty in-memory trade repository
+func NewTradeRepository(accounts *AccountRepository, transactions *TransactionRepository) *TradeRepository {
+	return &TradeRepository{
+		accounts:     accounts,
+		transactions: transactions,
+		trades:       make(map[uint]models.Trade),
+		nextID:       1,
+	}
+}
+
+// Create adds a new trade, rejecting a repeated correlation ID, and posts a
+// completed one to its accounts
+func (r *TradeRepository) Create(ctx context.Context, trade *models.Trade) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, t := range r.trades {
+		if t.CorrelationID == trade.CorrelationID {
+			return errors.New("duplicate correlation id")
+		}
+	}
+
+	if trade.Status == models.TradeCompleted {
+		if err := r.post(ctx, trade); err != nil {
+			return err
+		}
+	}
+
+	trade.ID = r.nextID
+	trade.CreatedAt = time.Now()
+	r.nextID++
+	r.trades[trade.ID] = *trade
+	return nil
+}
+
+// post moves the money of a completed trade and records its legs, linked
+// to the ID the trade is about to get
+func (r *TradeRepository) post(ctx context.Context, trade *models.Trade) error {
+	r.accounts.mu.Lock()
+	defer r.accounts.mu.Unlock()
+
+	debit, credit := trade.Legs()
+	from, ok := r.accounts.accounts[debit.AccountID]
+	if !ok || from.UserID != trade.UserID {
+		return repositories.ErrAccountNotFound
+	}
+	to, ok := r.accounts.accounts[credit.AccountID]
+	if !ok || to.UserID != trade.UserID || to.ID == from.ID {
+		return repositories.ErrAccountNotFound
+	}
+	if from.Balance < -debit.Amount {
+		return repositories.ErrInsufficientFunds
+	}
+
+	now := time.Now()
+	from.Balance += debit.Amount
+	from.UpdatedAt = now
+	to.Balance += credit.Amount
+	to.UpdatedAt = now
+	r.accounts.accounts[from.ID] = from
+	r.accounts.accounts[to.ID] = to
+
+	debit.TradeID, credit.TradeID = r.nextID, r.nextID
+	if err := r.transactions.Create(ctx, &debit); err != nil {
+		return err
+	}
+	return r.transactions.Create(ctx, &credit)
+}
+
+// GetByID retrieves a trade by ID
+func (r *TradeRepository) GetByID(ctx context.Context, id uint) (*models.Trade, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	trade, ok := r.trades[id]
+	if !ok {
+		return nil, repositories.ErrTradeNotFound
+	}
+	return &trade, nil
+}
+
+// GetAllByUserID retrieves all trades of a user, newest first
+func (r *TradeRepository) GetAllByUserID(ctx context.Context, userID uint) ([]models.Trade, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	trades := []models.Trade{}
+	for _, t := range r.trades {
+		if t.UserID == userID {
+			trades = append(trades, t)
+		}
+	}
+	sort.Slice(trades, func(i, j int) bool {
+		return trades[i].RequestedAt.After(trades[j].RequestedAt)
+	})
+	return trades, nil
+}
+
+// GetByIDs retrieves the trades with the given IDs
+func (r *TradeRepository) GetByIDs(ctx context.Context, ids []uint) ([]models.Trade, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var trades []models.Trade
+	for _, id := range ids {
+		if trade, ok := r.trades[id]; ok {
+			trades = append(trades, trade)
+		}
+	}
+	sort.Slice(trades, func(i, j int) bool {
+		return trades[i].ID < trades[j].ID
+	})
+	return trades, nil
+}
+
+// EachCompletedByUserID calls fn with each completed trade of a user
+// requested in [from, to], ordered by currency pair and request time
+func (r *TradeRepository) EachCompletedByUserID(ctx context.Context, userID uint, from, to time.Time, fn func(trade *models.Trade) error) error {
+	r.mu.RLock()
+	trades := []models.Trade{}
+	for _, t := range r.trades {
+		switch {
+		case t.UserID != userID || t.Status != models.TradeCompleted:
+		case !from.IsZero() && t.RequestedAt.Before(from):
+		case !to.IsZero() && t.RequestedAt.After(to):
+		default:
+			trades = append(trades, t)
+		}
+	}
+	r.mu.RUnlock()
+
+	sort.Slice(trades, func(i, j int) bool {
+		if trades[i].CurrencyPair != trades[j].CurrencyPair {
+			return trades[i].CurrencyPair < trades[j].CurrencyPair
+		}
+		if !trades[i].RequestedAt.Equal(trades[j].RequestedAt) {
+			return trades[i].RequestedAt.Before(trades[j].RequestedAt)
+		}
+		return trades[i].ID < trades[j].ID
+	})
+	for i := range trades {
+		if err := fn(&trades[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
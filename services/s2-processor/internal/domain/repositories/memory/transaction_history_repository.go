@@ -0,0 +1,84 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/leandroalencar/banco-dados/services/s2-processor/internal/domain/models"
+)
+
+// TransactionHistoryRepository keeps the trade history of each user in
+// memory. Page states are offsets into the user's history.
+type TransactionHistoryRepository struct {
+	mu     sync.RWMutex
+	trades map[uint][]models.Trade
+}
+
+// NewTransactionHistoryRepository creates an empty in-memory history repository
+func NewTransactionHistoryRepository() *TransactionHistoryRepository {
+	return &TransactionHistoryRepository{trades: make(map[uint][]models.Trade)}
+}
+
+// Insert writes a trade to the history. Writing the same trade again
+// overwrites it, so replays are harmless.
+func (r *TransactionHistoryRepository) Insert(ctx context.Context, trade *models.Trade) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	trades := r.trades[trade.UserID]
+	for i, t := range trades {
+		if t.ID == trade.ID && t.RequestedAt.Equal(trade.RequestedAt) {
+			trades[i] = *trade
+			return nil
+		}
+	}
+	trades = append(trades, *trade)
+	sort.SliceStable(trades, func(i, j int) bool {
+		return trades[i].RequestedAt.After(trades[j].RequestedAt)
+	})
+	r.trades[trade.UserID] = trades
+	return nil
+}
+
+// GetByUser returns up to pageSize trades of a user requested within
+// [from, to], newest first. Zero times leave that end of the range open.
+// Pass the returned page state back to fetch the next page; it is empty
+// after the last page.
+func (r *TransactionHistoryRepository) GetByUser(ctx context.Context, userID uint, from, to time.Time, pageSize int, pageState []byte) ([]models.Trade, []byte, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	matching := []models.Trade{}
+	for _, t := range r.trades[userID] {
+		if (!from.IsZero() && t.RequestedAt.Before(from)) || (!to.IsZero() && t.RequestedAt.After(to)) {
+			continue
+		}
+		matching = append(matching, t)
+	}
+
+	offset := 0
+	if len(pageState) > 0 {
+		n, err := strconv.Atoi(string(pageState))
+		if err != nil {
+			return nil, nil, err
+		}
+		offset = n
+	}
+	if offset > len(matching) {
+		offset = len(matching)
+	}
+
+	end := len(matching)
+	if pageSize > 0 && offset+pageSize < end {
+		end = offset + pageSize
+	}
+
+	var nextPageState []byte
+	if end < len(matching) {
+		nextPageState = []byte(strconv.Itoa(end))
+	}
+	return matching[offset:end], nextPageState, nil
+}
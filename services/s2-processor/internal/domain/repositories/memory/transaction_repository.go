@@ -0,0 +1,183 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/leandroalencar/banco-dados/services/s2-processor/internal/domain/models"
+	"github.com/leandroalencar/banco-dados/services/s2-processor/internal/domain/repositories"
+)
+
+// TransactionRepository keeps transactions in memory. Queries by user look
+// up who owns each account in accounts.
+type TransactionRepository struct {
+	mu           sync.RWMutex
+	transactions map[uint]models.Transaction
+	accounts     *AccountRepository
+	nextID       uint
+}
+
+// NewTransactionRepository creates an empty in-memory transaction repository
+func NewTransactionRepository(accounts *AccountRepository) *TransactionRepository {
+	return &TransactionRepository{
+		transactions: make(map[uint]models.Transaction),
+		accounts:     accounts,
+		nextID:       1,
+	}
+}
+
+// Create adds a new transaction
+func (r *TransactionRepository) Create(ctx context.Context, transaction *models.Transaction) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	transaction.ID = r.nextID
+	transaction.CreatedAt = now
+	transaction.UpdatedAt = now
+	r.nextID++
+	r.transactions[transaction.ID] = *transaction
+	return nil
+}
+
+// Post adds an income or expense transaction and applies it to the account
+// balance
+func (r *TransactionRepository) Post(ctx context.Context, transaction *models.Transaction) error {
+	if transaction.Type != models.Income && transaction.Type != models.Expense {
+		return repositories.ErrNotPostable
+	}
+	if err := r.accounts.UpdateBalance(ctx, transaction.AccountID, transaction.BalanceDelta()); err != nil {
+		return err
+	}
+	return r.Create(ctx, transaction)
+}
+
+// GetByID retrieves a transaction by ID
+func (r *TransactionRepository) GetByID(ctx context.Context, id uint) (*models.Transaction, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	transaction, ok := r.transactions[id]
+	if !ok {
+		return nil, repositories.ErrTransactionNotFound
+	}
+	return &transaction, nil
+}
+
+// GetAllByAccountID retrieves all transactions for an account
+func (r *TransactionRepository) GetAllByAccountID(ctx context.Context, accountID uint) ([]models.Transaction, error) {
+	return r.find(func(t models.Transaction) bool {
+		return t.AccountID == accountID
+	}), nil
+}
+
+// GetAllByUserID retrieves all transactions for a user with optional filters
+func (r *TransactionRepository) GetAllByUserID(ctx context.Context, userID uint, filters map[string]interface{}) ([]models.Transaction, error) {
+	categoryID, _ := filters["category_id"].(uint)
+	transactionType, _ := filters["type"].(string)
+	startDate, hasStart := filters["start_date"].(time.Time)
+	endDate, hasEnd := filters["end_date"].(time.Time)
+
+	return r.find(func(t models.Transaction) bool {
+		switch {
+		case r.accounts.owner(t.AccountID) != userID:
+			return false
+		case categoryID > 0 && t.CategoryID != categoryID:
+			return false
+		case transactionType != "" && string(t.Type) != transactionType:
+			return false
+		case hasStart && t.Date.Before(startDate):
+			return false
+		case hasEnd && t.Date.After(endDate):
+			return false
+		}
+		return true
+	}), nil
+}
+
+// EachByUserID calls fn with each transaction GetAllByUserID would return,
+// ordered by account and date
+func (r *TransactionRepository) EachByUserID(ctx context.Context, userID uint, filters map[string]interface{}, fn func(transaction *models.Transaction) error) error {
+	transactions, err := r.GetAllByUserID(ctx, userID, filters)
+	if err != nil {
+		return err
+	}
+	sort.Slice(transactions, func(i, j int) bool {
+		a, b := transactions[i], transactions[j]
+		if a.AccountID != b.AccountID {
+			return a.AccountID < b.AccountID
+		}
+		if !a.Date.Equal(b.Date) {
+			return a.Date.Before(b.Date)
+		}
+		return a.ID < b.ID
+	})
+	for i := range transactions {
+		if err := fn(&transactions[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GetByDateRange retrieves transactions within a date range for a user
+func (r *TransactionRepository) GetByDateRange(ctx context.Context, userID uint, startDate, endDate time.Time) ([]models.Transaction, error) {
+	return r.find(func(t models.Transaction) bool {
+		return r.accounts.owner(t.AccountID) == userID && !t.Date.Before(startDate) && !t.Date.After(endDate)
+	}), nil
+}
+
+// GetRecentCategorized retrieves up to limit of a user's most recent
+// transactions that have a category
+func (r *TransactionRepository) GetRecentCategorized(ctx context.Context, userID uint, limit int) ([]models.Transaction, error) {
+	transactions := r.find(func(t models.Transaction) bool {
+		return t.CategoryID != 0 && r.accounts.owner(t.AccountID) == userID
+	})
+	if len(transactions) > limit {
+		transactions = transactions[:limit]
+	}
+	return transactions, nil
+}
+
+// Update replaces an existing transaction
+func (r *TransactionRepository) Update(ctx context.Context, transaction *models.Transaction) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	transaction.UpdatedAt = time.Now()
+	r.transactions[transaction.ID] = *transaction
+	return nil
+}
+
+// Delete removes a transaction
+func (r *TransactionRepository) Delete(ctx context.Context, id uint) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.transactions, id)
+	return nil
+}
+
+// find returns the transactions matching keep, newest first. keep runs
+// without holding the lock, as it may look up account owners.
+func (r *TransactionRepository) find(keep func(models.Transaction) bool) []models.Transaction {
+	r.mu.RLock()
+	all := make([]models.Transaction, 0, len(r.transactions))
+	for _, t := range r.transactions {
+		all = append(all, t)
+	}
+	r.mu.RUnlock()
+
+	transactions := []models.Transaction{}
+	for _, t := range all {
+		if keep(t) {
+			transactions = append(transactions, t)
+		}
+	}
+	sort.Slice(transactions, func(i, j int) bool {
+		return transactions[i].Date.After(transactions[j].Date)
+	})
+	return transactions
+}
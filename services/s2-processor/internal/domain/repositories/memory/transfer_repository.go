@@ -0,0 +1,110 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/leandroalencar/banco-dados/services/s2-processor/internal/domain/models"
+	"github.com/leandroalencar/banco-dados/services/s2-processor/internal/domain/repositories"
+)
+
+// TransferRepository executes transfers against in-memory accounts and
+// records their legs in an in-memory transaction repository
+type TransferRepository struct {
+	mu           sync.Mutex
+	accounts     *AccountRepository
+	transactions *TransactionRepository
+	transfers    map[uint]models.AccountTransfer
+	nextID       uint
+}
+
+// NewTransferRepository creates an in-memory transfer repository
+func NewTransferRepository(accounts *AccountRepository, transactions *TransactionRepository) *TransferRepository {
+	return &TransferRepository{
+		accounts:     accounts,
+		transactions: transactions,
+		transfers:    make(map[uint]models.AccountTransfer),
+		nextID:       1,
+	}
+}
+
+// Create moves the money and records the transfer and its two legs
+func (r *TransferRepository) Create(ctx context.Context, transfer *models.AccountTransfer) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.accounts.mu.Lock()
+	defer r.accounts.mu.Unlock()
+
+	from, ok := r.accounts.accounts[transfer.FromAccountID]
+	if !ok {
+		return repositories.ErrAccountNotFound
+	}
+	to, ok := r.accounts.accounts[transfer.ToAccountID]
+	if !ok {
+		return repositories.ErrAccountNotFound
+	}
+	if from.Balance < transfer.Amount {
+		return repositories.ErrInsufficientFunds
+	}
+
+	now := time.Now()
+	from.Balance -= transfer.Amount
+	from.UpdatedAt = now
+	to.Balance += transfer.ConvertedAmount
+	to.UpdatedAt = now
+	r.accounts.accounts[from.ID] = from
+	r.accounts.accounts[to.ID] = to
+
+	transfer.ID = r.nextID
+	transfer.CreatedAt = now
+	r.nextID++
+
+	transfer.Debit = models.Transaction{
+		AccountID:   from.ID,
+		TransferID:  transfer.ID,
+		Amount:      -transfer.Amount,
+		Type:        models.Transfer,
+		Description: transfer.Description,
+		Date:        now,
+	}
+	transfer.Credit = models.Transaction{
+		AccountID:   to.ID,
+		TransferID:  transfer.ID,
+		Amount:      transfer.ConvertedAmount,
+		Type:        models.Transfer,
+		Description: transfer.Description,
+		Date:        now,
+	}
+	if err := r.transactions.Create(ctx, &transfer.Debit); err != nil {
+		return err
+	}
+	if err := r.transactions.Create(ctx, &transfer.Credit); err != nil {
+		return err
+	}
+
+	stored := *transfer
+	stored.Debit = models.Transaction{}
+	stored.Credit = models.Transaction{}
+	r.transfers[transfer.ID] = stored
+	return nil
+}
+
+// GetByIDs retrieves the transfers with the given IDs, without their legs
+func (r *TransferRepository) GetByIDs(ctx context.Context, ids []uint) ([]models.AccountTransfer, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var transfers []models.AccountTransfer
+	for _, id := range ids {
+		if transfer, ok := r.transfers[id]; ok {
+			transfers = append(transfers, transfer)
+		}
+	}
+	sort.Slice(transfers, func(i, j int) bool {
+		return transfers[i].ID < transfers[j].ID
+	})
+	return transfers, nil
+}
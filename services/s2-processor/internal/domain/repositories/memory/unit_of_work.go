@@ -0,0 +1,32 @@
+package memory
+
+import (
+	"context"
+	"sync"
+)
+
+// unitKey is the context key marking code that runs in a UnitOfWork
+type unitKey struct{}
+
+// UnitOfWork runs units of work one at a time, as if each locked everything
+// it touches. Writes are applied as they are made, so a failed unit is not
+// rolled back.
+type UnitOfWork struct {
+	mu sync.Mutex
+}
+
+// NewUnitOfWork creates an in-memory unit of work
+func NewUnitOfWork() *UnitOfWork {
+	return &UnitOfWork{}
+}
+
+// Do runs fn, waiting for other units to finish first. A unit started inside
+// another one runs right away.
+func (u *UnitOfWork) Do(ctx context.Context, fn func(ctx context.Context) error) error {
+	if ctx.Value(unitKey{}) == u {
+		return fn(ctx)
+	}
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	return fn(context.WithValue(ctx, unitKey{}, u))
+}
@@ -0,0 +1,138 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/leandroalencar/banco-dados/services/s2-processor/internal/domain/models"
+	"github.com/leandroalencar/banco-dados/services/s2-processor/internal/domain/repositories"
+)
+
+// UserRepository keeps users in memory. Deleted users stay in users, marked
+// in deleted, so that an upsert can restore them.
+type UserRepository struct {
+	mu      sync.RWMutex
+	users   map[uint]models.User
+	deleted map[uint]bool
+	nextID  uint
+}
+
+// NewUserRepository creates an empty in-memory user repository
+func NewUserRepository() *UserRepository {
+	return &UserRepository{users: make(map[uint]models.User), deleted: make(map[uint]bool), nextID: 1}
+}
+
+// Create adds a new user
+func (r *UserRepository) Create(ctx context.Context, user *models.User) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, u := range r.users {
+		if u.Email == user.Email && !r.deleted[u.ID] {
+			return errors.New("duplicate email")
+		}
+	}
+
+	now := time.Now()
+	user.ID = r.nextID
+	user.CreatedAt = now
+	user.UpdatedAt = now
+	r.nextID++
+	r.users[user.ID] = *user
+	return nil
+}
+
+// GetByID retrieves a user by ID
+func (r *UserRepository) GetByID(ctx context.Context, id uint) (*models.User, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	user, ok := r.users[id]
+	if !ok || r.deleted[id] {
+		return nil, repositories.ErrUserNotFound
+	}
+	return &user, nil
+}
+
+// GetByEmail retrieves a user by email
+func (r *UserRepository) GetByEmail(ctx context.Context, email string) (*models.User, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, user := range r.users {
+		if user.Email == email && !r.deleted[user.ID] {
+			return &user, nil
+		}
+	}
+	return nil, repositories.ErrUserNotFound
+}
+
+// GetByExternalID retrieves a user created by another service by the ID it
+// has there
+func (r *UserRepository) GetByExternalID(ctx context.Context, externalID string) (*models.User, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, user := range r.users {
+		if externalID != "" && user.ExternalID == externalID && !r.deleted[user.ID] {
+			return &user, nil
+		}
+	}
+	return nil, repositories.ErrUserNotFound
+}
+
+// Update replaces an existing user
+func (r *UserRepository) Update(ctx context.Context, user *models.User) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	user.UpdatedAt = time.Now()
+	r.users[user.ID] = *user
+	return nil
+}
+
+// Upsert creates a user keyed on its external ID or updates its email and
+// name, restoring it if it was deleted, unless the stored user is newer
+func (r *UserRepository) Upsert(ctx context.Context, user *models.User) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if user.ExternalID == "" {
+		return errors.New("upserted users need an external id")
+	}
+	for id, existing := range r.users {
+		if existing.ExternalID != user.ExternalID {
+			continue
+		}
+		if existing.UpdatedAt.After(user.UpdatedAt) {
+			return nil
+		}
+		existing.Email = user.Email
+		existing.FirstName = user.FirstName
+		existing.LastName = user.LastName
+		existing.UpdatedAt = user.UpdatedAt
+		r.users[id] = existing
+		delete(r.deleted, id)
+		return nil
+	}
+
+	created := *user
+	created.ID = r.nextID
+	created.Password = ""
+	r.nextID++
+	r.users[created.ID] = created
+	return nil
+}
+
+// Delete soft-deletes a user
+func (r *UserRepository) Delete(ctx context.Context, id uint) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.users[id]; ok {
+		r.deleted[id] = true
+	}
+	return nil
+}
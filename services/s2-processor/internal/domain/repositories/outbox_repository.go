@@ -0,0 +1,85 @@
+package repositories
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/leandroalencar/banco-dados/services/s2-processor/internal/domain/models"
+	"github.com/leandroalencar/banco-dados/shared/utils"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// PostgresOutboxRepository handles database operations for outbox messages
+type PostgresOutboxRepository struct {
+	db *gorm.DB
+}
+
+// NewPostgresOutboxRepository creates a new PostgreSQL-backed outbox repository
+func NewPostgresOutboxRepository(db *gorm.DB) *PostgresOutboxRepository {
+	return &PostgresOutboxRepository{db: db}
+}
+
+// Enqueue stores a message for later publishing. It must run in the unit of
+// work that performs the business change so both are committed together.
+func (r *PostgresOutboxRepository) Enqueue(ctx context.Context, queue string, message interface{}) error {
+	payload, err := json.Marshal(message)
+	if err != nil {
+		return err
+	}
+
+	return conn(ctx, r.db).Create(&outboxMessageRecord{
+		MessageID: utils.NewMessageID(),
+		Queue:     queue,
+		Payload:   string(payload),
+		Status:    string(models.OutboxPending),
+	}).Error
+}
+
+// ProcessPending locks up to limit pending messages, oldest first, and passes
+// them to fn inside a transaction, which fn's context joins. Rows locked by
+// another relay are skipped, so several relays can run concurrently without
+// publishing the same row.
+func (r *PostgresOutboxRepository) ProcessPending(ctx context.Context, limit int, fn func(ctx context.Context, messages []models.OutboxMessage) error) error {
+	return conn(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		var records []outboxMessageRecord
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("status = ?", models.OutboxPending).
+			Order("id").
+			Limit(limit).
+			Find(&records).Error; err != nil {
+			return err
+		}
+		if len(records) == 0 {
+			return nil
+		}
+		messages := make([]models.OutboxMessage, len(records))
+		for i := range records {
+			messages[i] = *records[i].toModel()
+		}
+		return fn(withTx(ctx, tx), messages)
+	})
+}
+
+// MarkSent flags a message as published
+func (r *PostgresOutboxRepository) MarkSent(ctx context.Context, id uint) error {
+	now := time.Now()
+	return conn(ctx, r.db).Model(&outboxMessageRecord{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"status":   models.OutboxSent,
+			"sent_at":  &now,
+			"attempts": gorm.Expr("attempts + 1"),
+		}).Error
+}
+
+// MarkFailed records a failed publishing attempt, leaving the message pending
+func (r *PostgresOutboxRepository) MarkFailed(ctx context.Context, id uint, cause error) error {
+	return conn(ctx, r.db).Model(&outboxMessageRecord{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"attempts":   gorm.Expr("attempts + 1"),
+			"last_error": cause.Error(),
+		}).Error
+}
@@ -0,0 +1,541 @@
+package repositories
+
+import (
+	"time"
+
+	"github.com/leandroalencar/banco-dados/services/s2-processor/internal/domain/models"
+	"gorm.io/gorm"
+)
+
+// The records below are how domain models are stored in PostgreSQL. Their
+// columns follow the migrations in infra/database/migrations.
+
+type userRecord struct {
+	ID         uint `gorm:"primaryKey"`
+	ExternalID *string
+	Email      string
+	Password   string
+	FirstName  string
+	LastName   string
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+	DeletedAt  gorm.DeletedAt
+}
+
+func (userRecord) TableName() string {
+	return "users"
+}
+
+func newUserRecord(u *models.User) *userRecord {
+	record := &userRecord{
+		ID:        u.ID,
+		Email:     u.Email,
+		Password:  u.Password,
+		FirstName: u.FirstName,
+		LastName:  u.LastName,
+		CreatedAt: u.CreatedAt,
+		UpdatedAt: u.UpdatedAt,
+	}
+	if u.ExternalID != "" {
+		record.ExternalID = &u.ExternalID
+	}
+	return record
+}
+
+func (r *userRecord) toModel() *models.User {
+	user := &models.User{
+		ID:        r.ID,
+		Email:     r.Email,
+		Password:  r.Password,
+		FirstName: r.FirstName,
+		LastName:  r.LastName,
+		CreatedAt: r.CreatedAt,
+		UpdatedAt: r.UpdatedAt,
+	}
+	if r.ExternalID != nil {
+		user.ExternalID = *r.ExternalID
+	}
+	return user
+}
+
+type accountRecord struct {
+	ID          uint `gorm:"primaryKey"`
+	UserID      uint
+	Name        string
+	Type        string
+	Balance     float64
+	Currency    string
+	Description string
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+	DeletedAt   gorm.DeletedAt
+}
+
+func (accountRecord) TableName() string {
+	return "accounts"
+}
+
+func newAccountRecord(a *models.Account) *accountRecord {
+	return &accountRecord{
+		ID:          a.ID,
+		UserID:      a.UserID,
+		Name:        a.Name,
+		Type:        string(a.Type),
+		Balance:     a.Balance,
+		Currency:    a.Currency,
+		Description: a.Description,
+		CreatedAt:   a.CreatedAt,
+		UpdatedAt:   a.UpdatedAt,
+	}
+}
+
+func (r *accountRecord) toModel() *models.Account {
+	return &models.Account{
+		ID:          r.ID,
+		UserID:      r.UserID,
+		Name:        r.Name,
+		Type:        models.AccountType(r.Type),
+		Balance:     r.Balance,
+		Currency:    r.Currency,
+		Description: r.Description,
+		CreatedAt:   r.CreatedAt,
+		UpdatedAt:   r.UpdatedAt,
+	}
+}
+
+type transactionRecord struct {
+	ID          uint `gorm:"primaryKey"`
+	AccountID   uint
+	CategoryID  *uint
+	TransferID  *uint
+	TradeID     *uint
+	Amount      float64
+	Type        string
+	Description string
+	Date        time.Time
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
+
+func (transactionRecord) TableName() string {
+	return "transactions"
+}
+
+func newTransactionRecord(t *models.Transaction) *transactionRecord {
+	return &transactionRecord{
+		ID:          t.ID,
+		AccountID:   t.AccountID,
+		CategoryID:  nullableID(t.CategoryID),
+		TransferID:  nullableID(t.TransferID),
+		TradeID:     nullableID(t.TradeID),
+		Amount:      t.Amount,
+		Type:        string(t.Type),
+		Description: t.Description,
+		Date:        t.Date,
+		CreatedAt:   t.CreatedAt,
+		UpdatedAt:   t.UpdatedAt,
+	}
+}
+
+func (r *transactionRecord) toModel() *models.Transaction {
+	return &models.Transaction{
+		ID:          r.ID,
+		AccountID:   r.AccountID,
+		CategoryID:  idValue(r.CategoryID),
+		TransferID:  idValue(r.TransferID),
+		TradeID:     idValue(r.TradeID),
+		Amount:      r.Amount,
+		Type:        models.TransactionType(r.Type),
+		Description: r.Description,
+		Date:        r.Date,
+		CreatedAt:   r.CreatedAt,
+		UpdatedAt:   r.UpdatedAt,
+	}
+}
+
+func transactionModels(records []transactionRecord) []models.Transaction {
+	transactions := make([]models.Transaction, len(records))
+	for i := range records {
+		transactions[i] = *records[i].toModel()
+	}
+	return transactions
+}
+
+type categoryRecord struct {
+	ID        uint `gorm:"primaryKey"`
+	UserID    *uint
+	ParentID  *uint
+	Name      string
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+func (categoryRecord) TableName() string {
+	return "categories"
+}
+
+func newCategoryRecord(c *models.Category) *categoryRecord {
+	return &categoryRecord{
+		ID:        c.ID,
+		UserID:    nullableID(c.UserID),
+		ParentID:  nullableID(c.ParentID),
+		Name:      c.Name,
+		CreatedAt: c.CreatedAt,
+		UpdatedAt: c.UpdatedAt,
+	}
+}
+
+func (r *categoryRecord) toModel() *models.Category {
+	return &models.Category{
+		ID:        r.ID,
+		UserID:    idValue(r.UserID),
+		ParentID:  idValue(r.ParentID),
+		Name:      r.Name,
+		CreatedAt: r.CreatedAt,
+		UpdatedAt: r.UpdatedAt,
+	}
+}
+
+type categorizationRuleRecord struct {
+	ID                 uint `gorm:"primaryKey"`
+	UserID             uint
+	CategoryID         uint
+	Priority           int
+	DescriptionPattern string
+	MatchType          string
+	MinAmount          *float64
+	MaxAmount          *float64
+	AccountID          *uint
+	CreatedAt          time.Time
+	UpdatedAt          time.Time
+}
+
+func (categorizationRuleRecord) TableName() string {
+	return "categorization_rules"
+}
+
+func newCategorizationRuleRecord(r *models.CategorizationRule) *categorizationRuleRecord {
+	return &categorizationRuleRecord{
+		ID:                 r.ID,
+		UserID:             r.UserID,
+		CategoryID:         r.CategoryID,
+		Priority:           r.Priority,
+		DescriptionPattern: r.DescriptionPattern,
+		MatchType:          string(r.MatchType),
+		MinAmount:          r.MinAmount,
+		MaxAmount:          r.MaxAmount,
+		AccountID:          nullableID(r.AccountID),
+		CreatedAt:          r.CreatedAt,
+		UpdatedAt:          r.UpdatedAt,
+	}
+}
+
+func (r *categorizationRuleRecord) toModel() *models.CategorizationRule {
+	return &models.CategorizationRule{
+		ID:                 r.ID,
+		UserID:             r.UserID,
+		CategoryID:         r.CategoryID,
+		Priority:           r.Priority,
+		DescriptionPattern: r.DescriptionPattern,
+		MatchType:          models.MatchType(r.MatchType),
+		MinAmount:          r.MinAmount,
+		MaxAmount:          r.MaxAmount,
+		AccountID:          idValue(r.AccountID),
+		CreatedAt:          r.CreatedAt,
+		UpdatedAt:          r.UpdatedAt,
+	}
+}
+
+type transferRecord struct {
+	ID              uint `gorm:"primaryKey"`
+	FromAccountID   uint
+	ToAccountID     uint
+	Amount          float64
+	ConvertedAmount float64
+	ExchangeRate    float64
+	QuotationID     string
+	Description     string
+	CreatedAt       time.Time
+}
+
+func (transferRecord) TableName() string {
+	return "transfers"
+}
+
+func newTransferRecord(t *models.AccountTransfer) *transferRecord {
+	return &transferRecord{
+		ID:              t.ID,
+		FromAccountID:   t.FromAccountID,
+		ToAccountID:     t.ToAccountID,
+		Amount:          t.Amount,
+		ConvertedAmount: t.ConvertedAmount,
+		ExchangeRate:    t.ExchangeRate,
+		QuotationID:     t.QuotationID,
+		Description:     t.Description,
+		CreatedAt:       t.CreatedAt,
+	}
+}
+
+// toModel returns the transfer without its legs
+func (r *transferRecord) toModel() *models.AccountTransfer {
+	return &models.AccountTransfer{
+		ID:              r.ID,
+		FromAccountID:   r.FromAccountID,
+		ToAccountID:     r.ToAccountID,
+		Amount:          r.Amount,
+		ConvertedAmount: r.ConvertedAmount,
+		ExchangeRate:    r.ExchangeRate,
+		QuotationID:     r.QuotationID,
+		Description:     r.Description,
+		CreatedAt:       r.CreatedAt,
+	}
+}
+
+type scheduleRecord struct {
+	ID              uint `gorm:"primaryKey"`
+	UserID          uint
+	Kind            string
+	Frequency       string
+	Interval        int
+	StartAt         time.Time
+	Until           *time.Time
+	Count           int
+	Amount          float64
+	AccountID       *uint
+	CategoryID      *uint
+	TransactionType string
+	Description     string
+	TradeType       string
+	CurrencyPair    string
+	Occurrences     int
+	NextRunAt       *time.Time
+	LastError       string
+	CreatedAt       time.Time
+	UpdatedAt       time.Time
+}
+
+func (scheduleRecord) TableName() string {
+	return "schedules"
+}
+
+func newScheduleRecord(s *models.Schedule) *scheduleRecord {
+	return &scheduleRecord{
+		ID:              s.ID,
+		UserID:          s.UserID,
+		Kind:            string(s.Kind),
+		Frequency:       string(s.Recurrence.Frequency),
+		Interval:        s.Recurrence.Interval,
+		StartAt:         s.Recurrence.StartAt,
+		Until:           s.Recurrence.Until,
+		Count:           s.Recurrence.Count,
+		Amount:          s.Amount,
+		AccountID:       nullableID(s.AccountID),
+		CategoryID:      nullableID(s.CategoryID),
+		TransactionType: string(s.TransactionType),
+		Description:     s.Description,
+		TradeType:       string(s.TradeType),
+		CurrencyPair:    s.CurrencyPair,
+		Occurrences:     s.Occurrences,
+		NextRunAt:       s.NextRunAt,
+		LastError:       s.LastError,
+		CreatedAt:       s.CreatedAt,
+		UpdatedAt:       s.UpdatedAt,
+	}
+}
+
+func (r *scheduleRecord) toModel() *models.Schedule {
+	return &models.Schedule{
+		ID:     r.ID,
+		UserID: r.UserID,
+		Kind:   models.ScheduleKind(r.Kind),
+		Recurrence: models.Recurrence{
+			Frequency: models.Frequency(r.Frequency),
+			Interval:  r.Interval,
+			StartAt:   r.StartAt,
+			Until:     r.Until,
+			Count:     r.Count,
+		},
+		Amount:          r.Amount,
+		AccountID:       idValue(r.AccountID),
+		CategoryID:      idValue(r.CategoryID),
+		TransactionType: models.TransactionType(r.TransactionType),
+		Description:     r.Description,
+		TradeType:       models.TradeType(r.TradeType),
+		CurrencyPair:    r.CurrencyPair,
+		Occurrences:     r.Occurrences,
+		NextRunAt:       r.NextRunAt,
+		LastError:       r.LastError,
+		CreatedAt:       r.CreatedAt,
+		UpdatedAt:       r.UpdatedAt,
+	}
+}
+
+type budgetRecord struct {
+	ID          uint `gorm:"primaryKey"`
+	UserID      uint
+	CategoryID  uint
+	LimitAmount float64
+	Currency    string
+	Thresholds  []int `gorm:"serializer:json"`
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
+
+func (budgetRecord) TableName() string {
+	return "budgets"
+}
+
+func newBudgetRecord(b *models.Budget) *budgetRecord {
+	return &budgetRecord{
+		ID:          b.ID,
+		UserID:      b.UserID,
+		CategoryID:  b.CategoryID,
+		LimitAmount: b.Limit,
+		Currency:    b.Currency,
+		Thresholds:  b.Thresholds,
+		CreatedAt:   b.CreatedAt,
+		UpdatedAt:   b.UpdatedAt,
+	}
+}
+
+func (r *budgetRecord) toModel() *models.Budget {
+	return &models.Budget{
+		ID:         r.ID,
+		UserID:     r.UserID,
+		CategoryID: r.CategoryID,
+		Limit:      r.LimitAmount,
+		Currency:   r.Currency,
+		Thresholds: r.Thresholds,
+		CreatedAt:  r.CreatedAt,
+		UpdatedAt:  r.UpdatedAt,
+	}
+}
+
+type budgetAlertRecord struct {
+	BudgetID  uint      `gorm:"primaryKey"`
+	Month     time.Time `gorm:"primaryKey"`
+	Threshold int       `gorm:"primaryKey"`
+	CreatedAt time.Time
+}
+
+func (budgetAlertRecord) TableName() string {
+	return "budget_alerts"
+}
+
+type tradeRecord struct {
+	ID             uint `gorm:"primaryKey"`
+	CorrelationID  string
+	UserID         uint
+	Type           string
+	CurrencyPair   string
+	Amount         float64
+	ExchangeRate   float64
+	TotalValue     float64
+	QuotationID    string
+	Status         string
+	Reason         string
+	BaseAccountID  *uint
+	QuoteAccountID *uint
+	RequestedAt    time.Time
+	CreatedAt      time.Time
+}
+
+func (tradeRecord) TableName() string {
+	return "trades"
+}
+
+func newTradeRecord(t *models.Trade) *tradeRecord {
+	return &tradeRecord{
+		ID:             t.ID,
+		CorrelationID:  t.CorrelationID,
+		UserID:         t.UserID,
+		Type:           string(t.Type),
+		CurrencyPair:   t.CurrencyPair,
+		Amount:         t.Amount,
+		ExchangeRate:   t.ExchangeRate,
+		TotalValue:     t.TotalValue,
+		QuotationID:    t.QuotationID,
+		Status:         string(t.Status),
+		Reason:         t.Reason,
+		BaseAccountID:  nullableID(t.BaseAccountID),
+		QuoteAccountID: nullableID(t.QuoteAccountID),
+		RequestedAt:    t.RequestedAt,
+		CreatedAt:      t.CreatedAt,
+	}
+}
+
+func (r *tradeRecord) toModel() *models.Trade {
+	return &models.Trade{
+		ID:             r.ID,
+		CorrelationID:  r.CorrelationID,
+		UserID:         r.UserID,
+		Type:           models.TradeType(r.Type),
+		CurrencyPair:   r.CurrencyPair,
+		Amount:         r.Amount,
+		ExchangeRate:   r.ExchangeRate,
+		TotalValue:     r.TotalValue,
+		QuotationID:    r.QuotationID,
+		Status:         models.TradeStatus(r.Status),
+		Reason:         r.Reason,
+		BaseAccountID:  idValue(r.BaseAccountID),
+		QuoteAccountID: idValue(r.QuoteAccountID),
+		RequestedAt:    r.RequestedAt,
+		CreatedAt:      r.CreatedAt,
+	}
+}
+
+type outboxMessageRecord struct {
+	ID        uint `gorm:"primaryKey"`
+	MessageID string
+	Queue     string
+	Payload   string
+	Status    string
+	Attempts  int
+	LastError string
+	CreatedAt time.Time
+	SentAt    *time.Time
+}
+
+func (outboxMessageRecord) TableName() string {
+	return "outbox_messages"
+}
+
+func (r *outboxMessageRecord) toModel() *models.OutboxMessage {
+	return &models.OutboxMessage{
+		ID:        r.ID,
+		MessageID: r.MessageID,
+		Queue:     r.Queue,
+		Payload:   r.Payload,
+		Status:    models.OutboxStatus(r.Status),
+		Attempts:  r.Attempts,
+		LastError: r.LastError,
+		CreatedAt: r.CreatedAt,
+		SentAt:    r.SentAt,
+	}
+}
+
+type processedMessageRecord struct {
+	MessageID   string `gorm:"primaryKey"`
+	Queue       string `gorm:"primaryKey"`
+	ProcessedAt time.Time
+}
+
+func (processedMessageRecord) TableName() string {
+	return "processed_messages"
+}
+
+// nullableID maps the zero ID to NULL
+func nullableID(id uint) *uint {
+	if id == 0 {
+		return nil
+	}
+	return &id
+}
+
+// idValue maps NULL to the zero ID
+func idValue(id *uint) uint {
+	if id == nil {
+		return 0
+	}
+	return *id
+}
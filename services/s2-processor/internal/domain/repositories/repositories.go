@@ -0,0 +1,185 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/leandroalencar/banco-dados/services/s2-processor/internal/domain/models"
+)
+
+// Methods called with the context of a UnitOfWork run inside it.
+// Implementations without transactions apply their writes immediately.
+
+// UserRepository stores users. Deleted users are hidden from every lookup.
+type UserRepository interface {
+	Create(ctx context.Context, user *models.User) error
+	GetByID(ctx context.Context, id uint) (*models.User, error)
+	GetByEmail(ctx context.Context, email string) (*models.User, error)
+	// GetByExternalID returns the user another service created with the
+	// given external ID
+	GetByExternalID(ctx context.Context, externalID string) (*models.User, error)
+	Update(ctx context.Context, user *models.User) error
+	// Upsert creates or updates the user with user's external ID. A new
+	// user gets an ID of its own. Changes older than the stored user are
+	// ignored, the password is kept and a newer change restores a deleted
+	// user.
+	Upsert(ctx context.Context, user *models.User) error
+	// Delete hides a user, keeping its accounts and their history
+	Delete(ctx context.Context, id uint) error
+}
+
+// AccountRepository stores accounts. Deleted accounts are hidden from every
+// lookup.
+type AccountRepository interface {
+	Create(ctx context.Context, account *models.Account) error
+	GetByID(ctx context.Context, id uint) (*models.Account, error)
+	GetAllByUserID(ctx context.Context, userID uint) ([]models.Account, error)
+	Update(ctx context.Context, account *models.Account) error
+	// Delete fails with ErrAccountNotEmpty unless the balance is zero
+	Delete(ctx context.Context, id uint) error
+	UpdateBalance(ctx context.Context, id uint, amount float64) error
+}
+
+// TransactionRepository stores income, expense, transfer and exchange
+// transactions
+type TransactionRepository interface {
+	Create(ctx context.Context, transaction *models.Transaction) error
+	// Post creates an income or expense transaction and applies it to the
+	// account balance atomically
+	Post(ctx context.Context, transaction *models.Transaction) error
+	GetByID(ctx context.Context, id uint) (*models.Transaction, error)
+	GetAllByAccountID(ctx context.Context, accountID uint) ([]models.Transaction, error)
+	GetAllByUserID(ctx context.Context, userID uint, filters map[string]interface{}) ([]models.Transaction, error)
+	// EachByUserID passes the transactions GetAllByUserID would return to
+	// fn one at a time, ordered by account and date, stopping at the first
+	// error
+	EachByUserID(ctx context.Context, userID uint, filters map[string]interface{}, fn func(transaction *models.Transaction) error) error
+	GetByDateRange(ctx context.Context, userID uint, startDate, endDate time.Time) ([]models.Transaction, error)
+	// GetRecentCategorized returns up to limit of a user's most recent
+	// transactions that have a category
+	GetRecentCategorized(ctx context.Context, userID uint, limit int) ([]models.Transaction, error)
+	Update(ctx context.Context, transaction *models.Transaction) error
+	Delete(ctx context.Context, id uint) error
+}
+
+// TransferRepository stores transfers between accounts
+type TransferRepository interface {
+	// Create moves the money and records the transfer atomically. It fails
+	// with ErrInsufficientFunds if the source balance is below the amount.
+	Create(ctx context.Context, transfer *models.AccountTransfer) error
+	// GetByIDs returns the transfers with the given IDs, without their legs
+	GetByIDs(ctx context.Context, ids []uint) ([]models.AccountTransfer, error)
+}
+
+// CategoryRepository stores transaction categories
+type CategoryRepository interface {
+	Create(ctx context.Context, category *models.Category) error
+	GetByID(ctx context.Context, id uint) (*models.Category, error)
+	GetAllForUser(ctx context.Context, userID uint) ([]models.Category, error)
+	Update(ctx context.Context, category *models.Category) error
+	// Delete fails with ErrCategoryHasChildren if the category has
+	// subcategories
+	Delete(ctx context.Context, id uint) error
+}
+
+// CategorizationRuleRepository stores the rules that categorize
+// transactions automatically
+type CategorizationRuleRepository interface {
+	Create(ctx context.Context, rule *models.CategorizationRule) error
+	GetByID(ctx context.Context, id uint) (*models.CategorizationRule, error)
+	// GetAllByUserID returns the rules by ascending priority
+	GetAllByUserID(ctx context.Context, userID uint) ([]models.CategorizationRule, error)
+	Update(ctx context.Context, rule *models.CategorizationRule) error
+	Delete(ctx context.Context, id uint) error
+}
+
+// ScheduleRepository stores recurring transactions and trades
+type ScheduleRepository interface {
+	Create(ctx context.Context, schedule *models.Schedule) error
+	GetByID(ctx context.Context, id uint) (*models.Schedule, error)
+	GetAllByUserID(ctx context.Context, userID uint) ([]models.Schedule, error)
+	Delete(ctx context.Context, id uint) error
+	// ProcessDue passes up to limit schedules whose next run is at or
+	// before now to fn, one at a time, and stores the progress fn made on
+	// each in the unit of work fn's context joins. Each schedule gets its
+	// own unit of work: when fn fails, that schedule's writes are dropped,
+	// the progress made on earlier ones is kept and the error is returned.
+	// A schedule is never passed to two callers at once.
+	ProcessDue(ctx context.Context, now time.Time, limit int, fn func(ctx context.Context, schedule *models.Schedule) error) error
+}
+
+// BudgetRepository stores monthly budgets and the alerts they raised
+type BudgetRepository interface {
+	Create(ctx context.Context, budget *models.Budget) error
+	GetByID(ctx context.Context, id uint) (*models.Budget, error)
+	GetAllByUserID(ctx context.Context, userID uint) ([]models.Budget, error)
+	Update(ctx context.Context, budget *models.Budget) error
+	Delete(ctx context.Context, id uint) error
+	// RecordAlert records that a budget reached threshold in month. It does
+	// nothing and returns false if the alert was already recorded.
+	RecordAlert(ctx context.Context, budgetID uint, month time.Time, threshold int) (bool, error)
+}
+
+// CurrencyRepository stores quotation snapshots
+type CurrencyRepository interface {
+	// Insert fails with ErrDuplicateQuote if a quote with the same ID
+	// exists
+	Insert(ctx context.Context, currency *models.Currency) error
+	GetByID(ctx context.Context, id string) (*models.Currency, error)
+	GetLatest(ctx context.Context, code, codein string) (*models.Currency, error)
+}
+
+// OutboxRepository stores events waiting to be published
+type OutboxRepository interface {
+	Enqueue(ctx context.Context, queue string, message interface{}) error
+	ProcessPending(ctx context.Context, limit int, fn func(ctx context.Context, messages []models.OutboxMessage) error) error
+	MarkSent(ctx context.Context, id uint) error
+	MarkFailed(ctx context.Context, id uint, cause error) error
+}
+
+// InboxRepository records consumed messages so each is processed once
+type InboxRepository interface {
+	// ProcessOnce runs fn in a unit of work unless messageID was already
+	// processed from queue, and reports whether it ran
+	ProcessOnce(ctx context.Context, queue, messageID string, fn func(ctx context.Context) error) (bool, error)
+	DeleteOlderThan(ctx context.Context, cutoff time.Time) error
+}
+
+// TradeRepository stores currency trades
+type TradeRepository interface {
+	// Create records a trade and posts a completed one to its accounts
+	// atomically. It fails with ErrInsufficientFunds if the debited balance
+	// is below what the trade pays.
+	Create(ctx context.Context, trade *models.Trade) error
+	GetByID(ctx context.Context, id uint) (*models.Trade, error)
+	GetAllByUserID(ctx context.Context, userID uint) ([]models.Trade, error)
+	// GetByIDs returns the trades with the given IDs
+	GetByIDs(ctx context.Context, ids []uint) ([]models.Trade, error)
+	// EachCompletedByUserID passes the completed trades of a user requested
+	// in [from, to] to fn one at a time, ordered by currency pair and
+	// request time, stopping at the first error. Zero bounds are ignored.
+	EachCompletedByUserID(ctx context.Context, userID uint, from, to time.Time, fn func(trade *models.Trade) error) error
+}
+
+// TransactionHistoryRepository stores the trade history of each user
+type TransactionHistoryRepository interface {
+	Insert(ctx context.Context, trade *models.Trade) error
+	GetByUser(ctx context.Context, userID uint, from, to time.Time, pageSize int, pageState []byte) ([]models.Trade, []byte, error)
+}
+
+var (
+	_ UnitOfWork                   = (*PostgresUnitOfWork)(nil)
+	_ UserRepository               = (*PostgresUserRepository)(nil)
+	_ AccountRepository            = (*PostgresAccountRepository)(nil)
+	_ TransactionRepository        = (*PostgresTransactionRepository)(nil)
+	_ TransferRepository           = (*PostgresTransferRepository)(nil)
+	_ CategoryRepository           = (*PostgresCategoryRepository)(nil)
+	_ CategorizationRuleRepository = (*PostgresCategorizationRuleRepository)(nil)
+	_ ScheduleRepository           = (*PostgresScheduleRepository)(nil)
+	_ BudgetRepository             = (*PostgresBudgetRepository)(nil)
+	_ CurrencyRepository           = (*MongoCurrencyRepository)(nil)
+	_ OutboxRepository             = (*PostgresOutboxRepository)(nil)
+	_ InboxRepository              = (*PostgresInboxRepository)(nil)
+	_ TradeRepository              = (*PostgresTradeRepository)(nil)
+	_ TransactionHistoryRepository = (*CassandraTransactionHistoryRepository)(nil)
+)
@@ -0,0 +1,109 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/leandroalencar/banco-dados/services/s2-processor/internal/domain/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ErrScheduleNotFound is returned when no schedule matches a lookup
+var ErrScheduleNotFound = errors.New("schedule not found")
+
+// PostgresScheduleRepository handles database operations for schedules
+type PostgresScheduleRepository struct {
+	db *gorm.DB
+}
+
+// NewPostgresScheduleRepository creates a new PostgreSQL-backed schedule repository
+func NewPostgresScheduleRepository(db *gorm.DB) *PostgresScheduleRepository {
+	return &PostgresScheduleRepository{db: db}
+}
+
+// Create adds a new schedule to the database
+func (r *PostgresScheduleRepository) Create(ctx context.Context, schedule *models.Schedule) error {
+	record := newScheduleRecord(schedule)
+	if err := conn(ctx, r.db).Create(record).Error; err != nil {
+		return err
+	}
+	*schedule = *record.toModel()
+	return nil
+}
+
+// GetByID retrieves a schedule by ID
+func (r *PostgresScheduleRepository) GetByID(ctx context.Context, id uint) (*models.Schedule, error) {
+	var record scheduleRecord
+	if err := conn(ctx, r.db).First(&record, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrScheduleNotFound
+		}
+		return nil, err
+	}
+	return record.toModel(), nil
+}
+
+// GetAllByUserID retrieves all schedules of a user
+func (r *PostgresScheduleRepository) GetAllByUserID(ctx context.Context, userID uint) ([]models.Schedule, error) {
+	var records []scheduleRecord
+	if err := conn(ctx, r.db).Where("user_id = ?", userID).Order("id").Find(&records).Error; err != nil {
+		return nil, err
+	}
+	schedules := make([]models.Schedule, len(records))
+	for i := range records {
+		schedules[i] = *records[i].toModel()
+	}
+	return schedules, nil
+}
+
+// Delete removes a schedule from the database. Transactions it already
+// materialized are kept.
+func (r *PostgresScheduleRepository) Delete(ctx context.Context, id uint) error {
+	return conn(ctx, r.db).Delete(&scheduleRecord{}, id).Error
+}
+
+// ProcessDue locks up to limit due schedules, earliest first, and passes
+// them to fn inside a transaction. Each schedule runs in its own savepoint,
+// which fn's context joins and in which its progress is saved, so a failing
+// schedule is rolled back while the ones before it are committed.
+// Schedules locked by another scheduler are skipped, so replicas can run
+// concurrently without materializing the same occurrence twice.
+func (r *PostgresScheduleRepository) ProcessDue(ctx context.Context, now time.Time, limit int, fn func(ctx context.Context, schedule *models.Schedule) error) error {
+	var failed error
+	err := conn(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		var records []scheduleRecord
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("next_run_at <= ?", now).
+			Order("next_run_at, id").
+			Limit(limit).
+			Find(&records).Error; err != nil {
+			return err
+		}
+
+		for _, record := range records {
+			schedule := record.toModel()
+			failed = tx.Transaction(func(tx *gorm.DB) error {
+				if err := fn(withTx(ctx, tx), schedule); err != nil {
+					return err
+				}
+				return tx.Model(&scheduleRecord{ID: schedule.ID}).
+					Updates(map[string]interface{}{
+						"occurrences": schedule.Occurrences,
+						"next_run_at": schedule.NextRunAt,
+						"last_error":  schedule.LastError,
+						"updated_at":  time.Now(),
+					}).Error
+			})
+			if failed != nil {
+				return nil
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	return failed
+}
@@ -0,0 +1,146 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/leandroalencar/banco-dados/services/s2-processor/internal/domain/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ErrTradeNotFound is returned when no trade matches a lookup
+var ErrTradeNotFound = errors.New("trade not found")
+
+// PostgresTradeRepository handles database operations for currency trades
+type PostgresTradeRepository struct {
+	db *gorm.DB
+}
+
+// NewPostgresTradeRepository creates a new PostgreSQL-backed trade repository
+func NewPostgresTradeRepository(db *gorm.DB) *PostgresTradeRepository {
+	return &PostgresTradeRepository{db: db}
+}
+
+// Create records a trade in one database transaction. A completed trade is
+// posted too: its legs move the money between its two accounts and are
+// recorded as Exchange transactions. Both accounts are locked, in ID order
+// like transfers lock theirs, before the debited balance is checked.
+func (r *PostgresTradeRepository) Create(ctx context.Context, trade *models.Trade) error {
+	return conn(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		var debit, credit models.Transaction
+		if trade.Status == models.TradeCompleted {
+			debit, credit = trade.Legs()
+			var accounts []accountRecord
+			if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+				Where("id IN ? AND user_id = ?", []uint{debit.AccountID, credit.AccountID}, trade.UserID).
+				Order("id").
+				Find(&accounts).Error; err != nil {
+				return err
+			}
+			if len(accounts) != 2 {
+				return ErrAccountNotFound
+			}
+			for _, account := range accounts {
+				if account.ID == debit.AccountID && account.Balance < -debit.Amount {
+					return ErrInsufficientFunds
+				}
+			}
+
+			if err := addToBalance(tx, debit.AccountID, debit.Amount); err != nil {
+				return err
+			}
+			if err := addToBalance(tx, credit.AccountID, credit.Amount); err != nil {
+				return err
+			}
+		}
+
+		record := newTradeRecord(trade)
+		if err := tx.Create(record).Error; err != nil {
+			return err
+		}
+		if trade.Status == models.TradeCompleted {
+			debit.TradeID, credit.TradeID = record.ID, record.ID
+			legs := []*transactionRecord{newTransactionRecord(&debit), newTransactionRecord(&credit)}
+			if err := tx.Create(legs).Error; err != nil {
+				return err
+			}
+		}
+		*trade = *record.toModel()
+		return nil
+	})
+}
+
+// GetByID retrieves a trade by ID
+func (r *PostgresTradeRepository) GetByID(ctx context.Context, id uint) (*models.Trade, error) {
+	var record tradeRecord
+	if err := conn(ctx, r.db).First(&record, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrTradeNotFound
+		}
+		return nil, err
+	}
+	return record.toModel(), nil
+}
+
+// GetAllByUserID retrieves all trades of a user, newest first
+func (r *PostgresTradeRepository) GetAllByUserID(ctx context.Context, userID uint) ([]models.Trade, error) {
+	var records []tradeRecord
+	if err := conn(ctx, r.db).Where("user_id = ?", userID).Order("requested_at DESC").Find(&records).Error; err != nil {
+		return nil, err
+	}
+	trades := make([]models.Trade, len(records))
+	for i := range records {
+		trades[i] = *records[i].toModel()
+	}
+	return trades, nil
+}
+
+// GetByIDs retrieves the trades with the given IDs
+func (r *PostgresTradeRepository) GetByIDs(ctx context.Context, ids []uint) ([]models.Trade, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+	var records []tradeRecord
+	if err := conn(ctx, r.db).Where("id IN ?", ids).Order("id").Find(&records).Error; err != nil {
+		return nil, err
+	}
+	trades := make([]models.Trade, len(records))
+	for i := range records {
+		trades[i] = *records[i].toModel()
+	}
+	return trades, nil
+}
+
+// EachCompletedByUserID calls fn with each completed trade of a user
+// requested in [from, to], ordered by currency pair and request time,
+// reading them one row at a time
+func (r *PostgresTradeRepository) EachCompletedByUserID(ctx context.Context, userID uint, from, to time.Time, fn func(trade *models.Trade) error) error {
+	query := conn(ctx, r.db).
+		Model(&tradeRecord{}).
+		Where("user_id = ? AND status = ?", userID, models.TradeCompleted)
+	if !from.IsZero() {
+		query = query.Where("requested_at >= ?", from)
+	}
+	if !to.IsZero() {
+		query = query.Where("requested_at <= ?", to)
+	}
+
+	rows, err := query.Order("currency_pair, requested_at, id").Rows()
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var record tradeRecord
+		if err := r.db.ScanRows(rows, &record); err != nil {
+			return err
+		}
+		if err := fn(record.toModel()); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
@@ -0,0 +1,80 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/gocql/gocql"
+	"github.com/leandroalencar/banco-dados/services/s2-processor/internal/domain/models"
+)
+
+// CassandraTransactionHistoryRepository stores trades in Cassandra,
+// partitioned by user and clustered newest first, for fast history queries
+type CassandraTransactionHistoryRepository struct {
+	session *gocql.Session
+}
+
+// NewCassandraTransactionHistoryRepository creates a new Cassandra-backed transaction history repository
+func NewCassandraTransactionHistoryRepository(session *gocql.Session) *CassandraTransactionHistoryRepository {
+	return &CassandraTransactionHistoryRepository{session: session}
+}
+
+// Insert writes a trade to the history. Writing the same trade again
+// overwrites the row, so replays are harmless.
+func (r *CassandraTransactionHistoryRepository) Insert(ctx context.Context, trade *models.Trade) error {
+	return r.session.Query(`INSERT INTO transactions_by_user
+		(user_id, requested_at, trade_id, correlation_id, type, currency_pair, amount, exchange_rate, total_value, quotation_id, status, reason)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		int64(trade.UserID), trade.RequestedAt, int64(trade.ID), trade.CorrelationID, string(trade.Type), trade.CurrencyPair,
+		trade.Amount, trade.ExchangeRate, trade.TotalValue, trade.QuotationID, string(trade.Status), trade.Reason,
+	).WithContext(ctx).Exec()
+}
+
+// GetByUser returns up to pageSize trades of a user requested within
+// [from, to], newest first. Zero times leave that end of the range open.
+// Pass the returned page state back to fetch the next page; it is empty
+// after the last page.
+func (r *CassandraTransactionHistoryRepository) GetByUser(ctx context.Context, userID uint, from, to time.Time, pageSize int, pageState []byte) ([]models.Trade, []byte, error) {
+	stmt := `SELECT user_id, requested_at, trade_id, correlation_id, type, currency_pair, amount, exchange_rate, total_value, quotation_id, status, reason
+		FROM transactions_by_user WHERE user_id = ?`
+	args := []interface{}{int64(userID)}
+	if !from.IsZero() {
+		stmt += " AND requested_at >= ?"
+		args = append(args, from)
+	}
+	if !to.IsZero() {
+		stmt += " AND requested_at <= ?"
+		args = append(args, to)
+	}
+
+	iter := r.session.Query(stmt, args...).
+		WithContext(ctx).
+		PageSize(pageSize).
+		PageState(pageState).
+		Iter()
+	nextPageState := iter.PageState()
+
+	trades := []models.Trade{}
+	scanner := iter.Scanner()
+	for scanner.Next() {
+		var (
+			trade             models.Trade
+			user, id          int64
+			tradeType, status string
+		)
+		if err := scanner.Scan(&user, &trade.RequestedAt, &id, &trade.CorrelationID, &tradeType, &trade.CurrencyPair,
+			&trade.Amount, &trade.ExchangeRate, &trade.TotalValue, &trade.QuotationID, &status, &trade.Reason); err != nil {
+			return nil, nil, err
+		}
+		trade.UserID = uint(user)
+		trade.ID = uint(id)
+		trade.Type = models.TradeType(tradeType)
+		trade.Status = models.TradeStatus(status)
+		trades = append(trades, trade)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, nil, err
+	}
+
+	return trades, nextPageState, nil
+}
@@ -9,48 +9,124 @@ import (
 	"gorm.io/gorm"
 )
 
-// TransactionRepository handles database operations for transactions
-type TransactionRepository struct {
+// ErrTransactionNotFound is returned when no transaction matches a lookup
+var ErrTransactionNotFound = errors.New("transaction not found")
+
+// ErrNotPostable is returned when posting a transaction other than income
+// or expense; transfers are recorded by TransferRepository
+var ErrNotPostable = errors.New("only income and expense transactions can be posted")
+
+// PostgresTransactionRepository handles database operations for transactions
+type PostgresTransactionRepository struct {
 	db *gorm.DB
 }
 
-// NewTransactionRepository creates a new transaction repository
-func NewTransactionRepository(db *gorm.DB) *TransactionRepository {
-	return &TransactionRepository{db: db}
+// NewPostgresTransactionRepository creates a new PostgreSQL-backed transaction repository
+func NewPostgresTransactionRepository(db *gorm.DB) *PostgresTransactionRepository {
+	return &PostgresTransactionRepository{db: db}
 }
 
 // Create adds a new transaction to the database
-func (r *TransactionRepository) Create(ctx context.Context, transaction *models.Transaction) error {
-	return r.db.WithContext(ctx).Create(transaction).Error
+func (r *PostgresTransactionRepository) Create(ctx context.Context, transaction *models.Transaction) error {
+	record := newTransactionRecord(transaction)
+	if err := conn(ctx, r.db).Create(record).Error; err != nil {
+		return err
+	}
+	*transaction = *record.toModel()
+	return nil
+}
+
+// Post adds an income or expense transaction and applies it to the account
+// balance in the same database transaction
+func (r *PostgresTransactionRepository) Post(ctx context.Context, transaction *models.Transaction) error {
+	if transaction.Type != models.Income && transaction.Type != models.Expense {
+		return ErrNotPostable
+	}
+
+	return conn(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&accountRecord{}).
+			Where("id = ?", transaction.AccountID).
+			Updates(map[string]interface{}{
+				"balance":    gorm.Expr("balance + ?", transaction.BalanceDelta()),
+				"updated_at": gorm.Expr("now()"),
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrAccountNotFound
+		}
+
+		record := newTransactionRecord(transaction)
+		if err := tx.Create(record).Error; err != nil {
+			return err
+		}
+		*transaction = *record.toModel()
+		return nil
+	})
 }
 
 // GetByID retrieves a transaction by ID
-func (r *TransactionRepository) GetByID(ctx context.Context, id uint) (*models.Transaction, error) {
-	var transaction models.Transaction
-	if err := r.db.WithContext(ctx).First(&transaction, id).Error; err != nil {
+func (r *PostgresTransactionRepository) GetByID(ctx context.Context, id uint) (*models.Transaction, error) {
+	var record transactionRecord
+	if err := conn(ctx, r.db).First(&record, id).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, errors.New("transaction not found")
+			return nil, ErrTransactionNotFound
 		}
 		return nil, err
 	}
-	return &transaction, nil
+	return record.toModel(), nil
 }
 
 // GetAllByAccountID retrieves all transactions for an account
-func (r *TransactionRepository) GetAllByAccountID(ctx context.Context, accountID uint) ([]models.Transaction, error) {
-	var transactions []models.Transaction
-	if err := r.db.WithContext(ctx).Where("account_id = ?", accountID).Order("date DESC").Find(&transactions).Error; err != nil {
+func (r *PostgresTransactionRepository) GetAllByAccountID(ctx context.Context, accountID uint) ([]models.Transaction, error) {
+	var records []transactionRecord
+	if err := conn(ctx, r.db).Where("account_id = ?", accountID).Order("date DESC").Find(&records).Error; err != nil {
 		return nil, err
 	}
-	return transactions, nil
+	return transactionModels(records), nil
 }
 
 // GetAllByUserID retrieves all transactions for a user with optional filters
-func (r *TransactionRepository) GetAllByUserID(ctx context.Context, userID uint, filters map[string]interface{}) ([]models.Transaction, error) {
-	var transactions []models.Transaction
+func (r *PostgresTransactionRepository) GetAllByUserID(ctx context.Context, userID uint, filters map[string]interface{}) ([]models.Transaction, error) {
+	var records []transactionRecord
+
+	if err := r.byUser(ctx, userID, filters).Order("transactions.date DESC").Find(&records).Error; err != nil {
+		return nil, err
+	}
+
+	return transactionModels(records), nil
+}
 
-	query := r.db.WithContext(ctx).
-		Table("transactions").
+// EachByUserID calls fn with each transaction GetAllByUserID would return,
+// ordered by account and date, reading them one row at a time
+func (r *PostgresTransactionRepository) EachByUserID(ctx context.Context, userID uint, filters map[string]interface{}, fn func(transaction *models.Transaction) error) error {
+	rows, err := r.byUser(ctx, userID, filters).
+		Order("transactions.account_id, transactions.date, transactions.id").
+		Rows()
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var record transactionRecord
+		if err := r.db.ScanRows(rows, &record); err != nil {
+			return err
+		}
+		if err := fn(record.toModel()); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+// byUser selects the transactions of a user matching filters: category_id
+// (uint), type (string), start_date and end_date (time.Time)
+func (r *PostgresTransactionRepository) byUser(ctx context.Context, userID uint, filters map[string]interface{}) *gorm.DB {
+	query := conn(ctx, r.db).
+		Model(&transactionRecord{}).
+		Select("transactions.*").
 		Joins("JOIN accounts ON transactions.account_id = accounts.id").
 		Where("accounts.user_id = ?", userID)
 
@@ -71,35 +147,56 @@ func (r *TransactionRepository) GetAllByUserID(ctx context.Context, userID uint,
 		query = query.Where("transactions.date <= ?", endDate)
 	}
 
-	if err := query.Order("transactions.date DESC").Find(&transactions).Error; err != nil {
+	return query
+}
+
+// GetByDateRange retrieves transactions within a date range for a user
+func (r *PostgresTransactionRepository) GetByDateRange(ctx context.Context, userID uint, startDate, endDate time.Time) ([]models.Transaction, error) {
+	var records []transactionRecord
+
+	if err := conn(ctx, r.db).
+		Model(&transactionRecord{}).
+		Select("transactions.*").
+		Joins("JOIN accounts ON transactions.account_id = accounts.id").
+		Where("accounts.user_id = ? AND transactions.date BETWEEN ? AND ?", userID, startDate, endDate).
+		Order("transactions.date DESC").
+		Find(&records).Error; err != nil {
 		return nil, err
 	}
 
-	return transactions, nil
+	return transactionModels(records), nil
 }
 
-// GetByDateRange retrieves transactions within a date range for a user
-func (r *TransactionRepository) GetByDateRange(ctx context.Context, userID uint, startDate, endDate time.Time) ([]models.Transaction, error) {
-	var transactions []models.Transaction
+// GetRecentCategorized retrieves up to limit of a user's most recent
+// transactions that have a category
+func (r *PostgresTransactionRepository) GetRecentCategorized(ctx context.Context, userID uint, limit int) ([]models.Transaction, error) {
+	var records []transactionRecord
 
-	if err := r.db.WithContext(ctx).
-		Table("transactions").
+	if err := conn(ctx, r.db).
+		Model(&transactionRecord{}).
+		Select("transactions.*").
 		Joins("JOIN accounts ON transactions.account_id = accounts.id").
-		Where("accounts.user_id = ? AND transactions.date BETWEEN ? AND ?", userID, startDate, endDate).
+		Where("accounts.user_id = ? AND transactions.category_id IS NOT NULL", userID).
 		Order("transactions.date DESC").
-		Find(&transactions).Error; err != nil {
+		Limit(limit).
+		Find(&records).Error; err != nil {
 		return nil, err
 	}
 
-	return transactions, nil
+	return transactionModels(records), nil
 }
 
 // Update updates an existing transaction
-func (r *TransactionRepository) Update(ctx context.Context, transaction *models.Transaction) error {
-	return r.db.WithContext(ctx).Save(transaction).Error
+func (r *PostgresTransactionRepository) Update(ctx context.Context, transaction *models.Transaction) error {
+	record := newTransactionRecord(transaction)
+	if err := conn(ctx, r.db).Save(record).Error; err != nil {
+		return err
+	}
+	transaction.UpdatedAt = record.UpdatedAt
+	return nil
 }
 
 // Delete removes a transaction from the database
-func (r *TransactionRepository) Delete(ctx context.Context, id uint) error {
-	return r.db.WithContext(ctx).Delete(&models.Transaction{}, id).Error
+func (r *PostgresTransactionRepository) Delete(ctx context.Context, id uint) error {
+	return conn(ctx, r.db).Delete(&transactionRecord{}, id).Error
 }
@@ -0,0 +1,113 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/leandroalencar/banco-dados/services/s2-processor/internal/domain/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ErrInsufficientFunds is returned when an account's balance can't cover a
+// debit
+var ErrInsufficientFunds = errors.New("insufficient funds")
+
+// PostgresTransferRepository handles database operations for transfers
+type PostgresTransferRepository struct {
+	db *gorm.DB
+}
+
+// NewPostgresTransferRepository creates a new PostgreSQL-backed transfer repository
+func NewPostgresTransferRepository(db *gorm.DB) *PostgresTransferRepository {
+	return &PostgresTransferRepository{db: db}
+}
+
+// Create executes a transfer in one database transaction: it moves the
+// money between both accounts and records the transfer and its two legs,
+// which are filled in on success. Both accounts are locked, in ID order so
+// opposite transfers can't deadlock, before the source balance is checked.
+func (r *PostgresTransferRepository) Create(ctx context.Context, transfer *models.AccountTransfer) error {
+	return conn(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		var accounts []accountRecord
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id IN ?", []uint{transfer.FromAccountID, transfer.ToAccountID}).
+			Order("id").
+			Find(&accounts).Error; err != nil {
+			return err
+		}
+		if len(accounts) != 2 {
+			return ErrAccountNotFound
+		}
+		for _, account := range accounts {
+			if account.ID == transfer.FromAccountID && account.Balance < transfer.Amount {
+				return ErrInsufficientFunds
+			}
+		}
+
+		if err := addToBalance(tx, transfer.FromAccountID, -transfer.Amount); err != nil {
+			return err
+		}
+		if err := addToBalance(tx, transfer.ToAccountID, transfer.ConvertedAmount); err != nil {
+			return err
+		}
+
+		record := newTransferRecord(transfer)
+		record.CreatedAt = time.Now()
+		if err := tx.Create(record).Error; err != nil {
+			return err
+		}
+
+		debit := newTransactionRecord(&models.Transaction{
+			AccountID:   transfer.FromAccountID,
+			TransferID:  record.ID,
+			Amount:      -transfer.Amount,
+			Type:        models.Transfer,
+			Description: transfer.Description,
+			Date:        record.CreatedAt,
+		})
+		credit := newTransactionRecord(&models.Transaction{
+			AccountID:   transfer.ToAccountID,
+			TransferID:  record.ID,
+			Amount:      transfer.ConvertedAmount,
+			Type:        models.Transfer,
+			Description: transfer.Description,
+			Date:        record.CreatedAt,
+		})
+		if err := tx.Create([]*transactionRecord{debit, credit}).Error; err != nil {
+			return err
+		}
+
+		transfer.ID = record.ID
+		transfer.CreatedAt = record.CreatedAt
+		transfer.Debit = *debit.toModel()
+		transfer.Credit = *credit.toModel()
+		return nil
+	})
+}
+
+// GetByIDs retrieves the transfers with the given IDs, without their legs
+func (r *PostgresTransferRepository) GetByIDs(ctx context.Context, ids []uint) ([]models.AccountTransfer, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+	var records []transferRecord
+	if err := conn(ctx, r.db).Where("id IN ?", ids).Order("id").Find(&records).Error; err != nil {
+		return nil, err
+	}
+	transfers := make([]models.AccountTransfer, len(records))
+	for i := range records {
+		transfers[i] = *records[i].toModel()
+	}
+	return transfers, nil
+}
+
+func addToBalance(tx *gorm.DB, accountID uint, amount float64) error {
+	return tx.Model(&accountRecord{}).
+		Where("id = ?", accountID).
+		Updates(map[string]interface{}{
+			"balance":    gorm.Expr("balance + ?", amount),
+			"updated_at": gorm.Expr("now()"),
+		}).Error
+}
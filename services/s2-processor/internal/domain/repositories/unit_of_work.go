@@ -0,0 +1,50 @@
+package repositories
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+)
+
+// UnitOfWork runs a function atomically. Repository methods called with the
+// context passed to fn join the unit of work, so their writes are committed
+// together or not at all.
+type UnitOfWork interface {
+	Do(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
+// txKey is the context key under which a PostgresUnitOfWork stores its
+// transaction
+type txKey struct{}
+
+// PostgresUnitOfWork runs units of work in PostgreSQL transactions. A unit
+// started inside another one runs in a savepoint of the outer transaction.
+type PostgresUnitOfWork struct {
+	db *gorm.DB
+}
+
+// NewPostgresUnitOfWork creates a unit of work over db
+func NewPostgresUnitOfWork(db *gorm.DB) *PostgresUnitOfWork {
+	return &PostgresUnitOfWork{db: db}
+}
+
+// Do runs fn in a transaction, committing it if fn returns nil
+func (u *PostgresUnitOfWork) Do(ctx context.Context, fn func(ctx context.Context) error) error {
+	return conn(ctx, u.db).Transaction(func(tx *gorm.DB) error {
+		return fn(withTx(ctx, tx))
+	})
+}
+
+// conn returns the transaction of the unit of work ctx belongs to, or db
+// outside of one
+func conn(ctx context.Context, db *gorm.DB) *gorm.DB {
+	if tx, ok := ctx.Value(txKey{}).(*gorm.DB); ok {
+		return tx.WithContext(ctx)
+	}
+	return db.WithContext(ctx)
+}
+
+// withTx returns a context whose repository calls run in tx
+func withTx(ctx context.Context, tx *gorm.DB) context.Context {
+	return context.WithValue(ctx, txKey{}, tx)
+}
@@ -3,56 +3,115 @@ package repositories
 import (
 	"context"
 	"errors"
+	"time"
 
-	"github.com/leandroalencar/banco-dados/shared/models"
+	"github.com/leandroalencar/banco-dados/services/s2-processor/internal/domain/models"
 	"gorm.io/gorm"
 )
 
-// UserRepository handles database operations for users
-type UserRepository struct {
+// ErrUserNotFound is returned when no user matches a lookup
+var ErrUserNotFound = errors.New("user not found")
+
+// PostgresUserRepository handles database operations for users
+type PostgresUserRepository struct {
 	db *gorm.DB
 }
 
-// NewUserRepository creates a new user repository
-func NewUserRepository(db *gorm.DB) *UserRepository {
-	return &UserRepository{db: db}
+// NewPostgresUserRepository creates a new PostgreSQL-backed user repository
+func NewPostgresUserRepository(db *gorm.DB) *PostgresUserRepository {
+	return &PostgresUserRepository{db: db}
 }
 
 // Create adds a new user to the database
-func (r *UserRepository) Create(ctx context.Context, user *models.User) error {
-	return r.db.WithContext(ctx).Create(user).Error
+func (r *PostgresUserRepository) Create(ctx context.Context, user *models.User) error {
+	record := newUserRecord(user)
+	if err := conn(ctx, r.db).Create(record).Error; err != nil {
+		return err
+	}
+	*user = *record.toModel()
+	return nil
 }
 
 // GetByID retrieves a user by ID
-func (r *UserRepository) GetByID(ctx context.Context, id uint) (*models.User, error) {
-	var user models.User
-	if err := r.db.WithContext(ctx).First(&user, id).Error; err != nil {
+func (r *PostgresUserRepository) GetByID(ctx context.Context, id uint) (*models.User, error) {
+	var record userRecord
+	if err := conn(ctx, r.db).First(&record, id).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, errors.New("user not found")
+			return nil, ErrUserNotFound
 		}
 		return nil, err
 	}
-	return &user, nil
+	return record.toModel(), nil
 }
 
 // GetByEmail retrieves a user by email
-func (r *UserRepository) GetByEmail(ctx context.Context, email string) (*models.User, error) {
-	var user models.User
-	if err := r.db.WithContext(ctx).Where("email = ?", email).First(&user).Error; err != nil {
+func (r *PostgresUserRepository) GetByEmail(ctx context.Context, email string) (*models.User, error) {
+	var record userRecord
+	if err := conn(ctx, r.db).Where("email = ?", email).First(&record).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, errors.New("user not found")
+			return nil, ErrUserNotFound
 		}
 		return nil, err
 	}
-	return &user, nil
+	return record.toModel(), nil
+}
+
+// GetByExternalID retrieves a user created by another service by the ID it
+// has there
+func (r *PostgresUserRepository) GetByExternalID(ctx context.Context, externalID string) (*models.User, error) {
+	if externalID == "" {
+		return nil, ErrUserNotFound
+	}
+	var record userRecord
+	if err := conn(ctx, r.db).Where("external_id = ?", externalID).First(&record).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrUserNotFound
+		}
+		return nil, err
+	}
+	return record.toModel(), nil
 }
 
 // Update updates an existing user
-func (r *UserRepository) Update(ctx context.Context, user *models.User) error {
-	return r.db.WithContext(ctx).Save(user).Error
+func (r *PostgresUserRepository) Update(ctx context.Context, user *models.User) error {
+	record := newUserRecord(user)
+	if err := conn(ctx, r.db).Save(record).Error; err != nil {
+		return err
+	}
+	user.UpdatedAt = record.UpdatedAt
+	return nil
+}
+
+// Upsert creates a user keyed on its external ID or updates its email and
+// name, restoring it if it was deleted. Users created this way get IDs from
+// the same sequence as registered ones and have no password until they set
+// one.
+func (r *PostgresUserRepository) Upsert(ctx context.Context, user *models.User) error {
+	now := time.Now()
+	if user.CreatedAt.IsZero() {
+		user.CreatedAt = now
+	}
+	if user.UpdatedAt.IsZero() {
+		user.UpdatedAt = now
+	}
+	if user.ExternalID == "" {
+		return errors.New("upserted users need an external id")
+	}
+	return conn(ctx, r.db).Exec(`
+		INSERT INTO users (external_id, email, password, first_name, last_name, created_at, updated_at)
+		VALUES (?, ?, '', ?, ?, ?, ?)
+		ON CONFLICT (external_id) DO UPDATE SET
+			email = EXCLUDED.email,
+			first_name = EXCLUDED.first_name,
+			last_name = EXCLUDED.last_name,
+			updated_at = EXCLUDED.updated_at,
+			deleted_at = NULL
+		WHERE users.updated_at <= EXCLUDED.updated_at`,
+		user.ExternalID, user.Email, user.FirstName, user.LastName, user.CreatedAt, user.UpdatedAt).Error
 }
 
-// Delete removes a user from the database
-func (r *UserRepository) Delete(ctx context.Context, id uint) error {
-	return r.db.WithContext(ctx).Delete(&models.User{}, id).Error
+// Delete soft-deletes a user. Its accounts, transactions and trades are
+// kept, as transfers from other users' accounts may reference them.
+func (r *PostgresUserRepository) Delete(ctx context.Context, id uint) error {
+	return conn(ctx, r.db).Delete(&userRecord{}, id).Error
 }
@@ -0,0 +1,126 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"strings"
+
+	"github.com/leandroalencar/banco-dados/services/s2-processor/internal/domain/models"
+	"github.com/leandroalencar/banco-dados/services/s2-processor/internal/domain/repositories"
+)
+
+var (
+	// ErrAccountNameRequired is returned for an account without a name
+	ErrAccountNameRequired = errors.New("account name is required")
+
+	// ErrInvalidAccountType is returned for an unknown account type
+	ErrInvalidAccountType = errors.New("invalid account type")
+
+	// ErrInvalidCurrency is returned for a currency that is not a three
+	// letter ISO 4217 code
+	ErrInvalidCurrency = errors.New("invalid currency")
+)
+
+var currencyCode = regexp.MustCompile(`^[A-Z]{3}$`)
+
+// AccountService handles business logic related to accounts. Every
+// operation acts on behalf of a user, and accounts of other users behave
+// as if they didn't exist.
+type AccountService struct {
+	accountRepo repositories.AccountRepository
+}
+
+// NewAccountService creates a new account service
+func NewAccountService(accountRepo repositories.AccountRepository) *AccountService {
+	return &AccountService{accountRepo: accountRepo}
+}
+
+// AccountChanges lists the fields of an account to update. Nil fields are
+// left as they are.
+type AccountChanges struct {
+	Name        *string
+	Type        *models.AccountType
+	Description *string
+}
+
+// Open creates an empty account for userID. The currency defaults to BRL.
+func (s *AccountService) Open(ctx context.Context, userID uint, account *models.Account) error {
+	account.UserID = userID
+	account.Balance = 0
+	account.Currency = strings.ToUpper(account.Currency)
+	if account.Currency == "" {
+		account.Currency = "BRL"
+	}
+	if err := validateAccount(account); err != nil {
+		return err
+	}
+	return s.accountRepo.Create(ctx, account)
+}
+
+// List returns the open accounts of a user
+func (s *AccountService) List(ctx context.Context, userID uint) ([]models.Account, error) {
+	return s.accountRepo.GetAllByUserID(ctx, userID)
+}
+
+// Get returns an account of userID
+func (s *AccountService) Get(ctx context.Context, userID, id uint) (*models.Account, error) {
+	account, err := s.accountRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if account.UserID != userID {
+		return nil, repositories.ErrAccountNotFound
+	}
+	return account, nil
+}
+
+// Update applies changes to an account of userID
+func (s *AccountService) Update(ctx context.Context, userID, id uint, changes AccountChanges) (*models.Account, error) {
+	account, err := s.Get(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if changes.Name != nil {
+		account.Name = *changes.Name
+	}
+	if changes.Type != nil {
+		account.Type = *changes.Type
+	}
+	if changes.Description != nil {
+		account.Description = *changes.Description
+	}
+	if err := validateAccount(account); err != nil {
+		return nil, err
+	}
+
+	if err := s.accountRepo.Update(ctx, account); err != nil {
+		return nil, err
+	}
+	return account, nil
+}
+
+// Close closes an account of userID. Only accounts with a zero balance can
+// be closed; the money has to be moved out first.
+func (s *AccountService) Close(ctx context.Context, userID, id uint) error {
+	if _, err := s.Get(ctx, userID, id); err != nil {
+		return err
+	}
+	return s.accountRepo.Delete(ctx, id)
+}
+
+func validateAccount(account *models.Account) error {
+	if strings.TrimSpace(account.Name) == "" {
+		return ErrAccountNameRequired
+	}
+	switch account.Type {
+	case models.Checking, models.Savings, models.Credit, models.Investment:
+	default:
+		return ErrInvalidAccountType
+	}
+	if !currencyCode.MatchString(account.Currency) {
+		return ErrInvalidCurrency
+	}
+	return nil
+}
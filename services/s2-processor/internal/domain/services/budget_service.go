@@ -0,0 +1,286 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/leandroalencar/banco-dados/services/s2-processor/internal/domain/models"
+	"github.com/leandroalencar/banco-dados/services/s2-processor/internal/domain/repositories"
+	"github.com/leandroalencar/banco-dados/services/s2-processor/internal/dto"
+)
+
+// BudgetAlertsQueue receives an alert when spending reaches a budget
+// threshold
+const BudgetAlertsQueue = "budgets.alerts"
+
+// ErrInvalidThreshold is returned for a budget threshold outside 1-1000%
+var ErrInvalidThreshold = errors.New("thresholds must be percentages between 1 and 1000")
+
+// defaultThresholds are the alert thresholds of budgets that set none
+var defaultThresholds = []int{80, 100}
+
+// BudgetService manages monthly budgets per category and alerts users when
+// their spending reaches a budget's thresholds
+type BudgetService struct {
+	budgetRepo      repositories.BudgetRepository
+	accountRepo     repositories.AccountRepository
+	categoryRepo    repositories.CategoryRepository
+	transactionRepo repositories.TransactionRepository
+	outboxRepo      repositories.OutboxRepository
+	uow             repositories.UnitOfWork
+}
+
+// NewBudgetService creates a new budget service
+func NewBudgetService(budgetRepo repositories.BudgetRepository, accountRepo repositories.AccountRepository, categoryRepo repositories.CategoryRepository, transactionRepo repositories.TransactionRepository, outboxRepo repositories.OutboxRepository, uow repositories.UnitOfWork) *BudgetService {
+	return &BudgetService{
+		budgetRepo:      budgetRepo,
+		accountRepo:     accountRepo,
+		categoryRepo:    categoryRepo,
+		transactionRepo: transactionRepo,
+		outboxRepo:      outboxRepo,
+		uow:             uow,
+	}
+}
+
+// BudgetChanges lists the fields of a budget to update. Nil fields are left
+// as they are.
+type BudgetChanges struct {
+	Limit      *float64
+	Thresholds []int
+}
+
+// Create adds a budget of userID. The currency defaults to BRL and the
+// thresholds to 80% and 100%.
+func (s *BudgetService) Create(ctx context.Context, userID uint, budget *models.Budget) error {
+	budget.UserID = userID
+	budget.Currency = strings.ToUpper(budget.Currency)
+	if budget.Currency == "" {
+		budget.Currency = "BRL"
+	}
+	if !currencyCode.MatchString(budget.Currency) {
+		return ErrInvalidCurrency
+	}
+	if budget.Limit <= 0 {
+		return ErrInvalidAmount
+	}
+	thresholds, err := normalizeThresholds(budget.Thresholds)
+	if err != nil {
+		return err
+	}
+	budget.Thresholds = thresholds
+
+	category, err := s.categoryRepo.GetByID(ctx, budget.CategoryID)
+	if err != nil {
+		return err
+	}
+	if !category.VisibleTo(userID) {
+		return repositories.ErrCategoryNotFound
+	}
+	return s.budgetRepo.Create(ctx, budget)
+}
+
+// List returns the budgets of userID
+func (s *BudgetService) List(ctx context.Context, userID uint) ([]models.Budget, error) {
+	return s.budgetRepo.GetAllByUserID(ctx, userID)
+}
+
+// Get returns a budget of userID
+func (s *BudgetService) Get(ctx context.Context, userID, id uint) (*models.Budget, error) {
+	budget, err := s.budgetRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if budget.UserID != userID {
+		return nil, repositories.ErrBudgetNotFound
+	}
+	return budget, nil
+}
+
+// Update changes the limit or thresholds of a budget of userID. Alerts
+// already sent this month are not sent again.
+func (s *BudgetService) Update(ctx context.Context, userID, id uint, changes BudgetChanges) (*models.Budget, error) {
+	budget, err := s.Get(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+	if changes.Limit != nil {
+		if *changes.Limit <= 0 {
+			return nil, ErrInvalidAmount
+		}
+		budget.Limit = *changes.Limit
+	}
+	if changes.Thresholds != nil {
+		if budget.Thresholds, err = normalizeThresholds(changes.Thresholds); err != nil {
+			return nil, err
+		}
+	}
+	if err := s.budgetRepo.Update(ctx, budget); err != nil {
+		return nil, err
+	}
+	return budget, nil
+}
+
+// Delete removes a budget of userID
+func (s *BudgetService) Delete(ctx context.Context, userID, id uint) error {
+	if _, err := s.Get(ctx, userID, id); err != nil {
+		return err
+	}
+	return s.budgetRepo.Delete(ctx, id)
+}
+
+// Status returns the spending against a budget of userID in the month
+// containing month
+func (s *BudgetService) Status(ctx context.Context, userID, id uint, month time.Time) (*models.BudgetStatus, error) {
+	budget, err := s.Get(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+	categories, err := s.categoryTree(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return s.status(ctx, budget, categories, models.MonthOf(month))
+}
+
+// Track checks the budgets covering an expense that was just recorded or
+// recategorized and queues an alert for every threshold its month's
+// spending reached for the first time. Other transactions are ignored.
+// Callers run it in the unit of work that writes the expense, so the
+// alerts are committed with it.
+func (s *BudgetService) Track(ctx context.Context, transaction *models.Transaction) error {
+	if transaction.Type != models.Expense || transaction.CategoryID == 0 {
+		return nil
+	}
+	account, err := s.accountRepo.GetByID(ctx, transaction.AccountID)
+	if err != nil {
+		return err
+	}
+	budgets, err := s.budgetRepo.GetAllByUserID(ctx, account.UserID)
+	if err != nil || len(budgets) == 0 {
+		return err
+	}
+	categories, err := s.categoryTree(ctx, account.UserID)
+	if err != nil {
+		return err
+	}
+
+	month := models.MonthOf(transaction.Date)
+	for i := range budgets {
+		budget := &budgets[i]
+		if budget.Currency != account.Currency || !categories.within(transaction.CategoryID, budget.CategoryID) {
+			continue
+		}
+		status, err := s.status(ctx, budget, categories, month)
+		if err != nil {
+			return err
+		}
+		for _, threshold := range status.Reached() {
+			if err := s.alert(ctx, status, month, threshold); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// alert queues an alert for a threshold of status reached in month, unless
+// one was already sent
+func (s *BudgetService) alert(ctx context.Context, status *models.BudgetStatus, month time.Time, threshold int) error {
+	return s.uow.Do(ctx, func(ctx context.Context) error {
+		recorded, err := s.budgetRepo.RecordAlert(ctx, status.Budget.ID, month, threshold)
+		if err != nil || !recorded {
+			return err
+		}
+		return s.outboxRepo.Enqueue(ctx, BudgetAlertsQueue, dto.BudgetAlertToMessage(status, threshold))
+	})
+}
+
+// status sums the expenses counted by budget in the month starting at month
+func (s *BudgetService) status(ctx context.Context, budget *models.Budget, categories categoryTree, month time.Time) (*models.BudgetStatus, error) {
+	accounts, err := s.accountRepo.GetAllByUserID(ctx, budget.UserID)
+	if err != nil {
+		return nil, err
+	}
+	inCurrency := make(map[uint]bool)
+	for _, account := range accounts {
+		if account.Currency == budget.Currency {
+			inCurrency[account.ID] = true
+		}
+	}
+
+	// Timestamps are stored with microsecond precision, so this is the
+	// last instant of the month
+	end := month.AddDate(0, 1, 0).Add(-time.Microsecond)
+	transactions, err := s.transactionRepo.GetAllByUserID(ctx, budget.UserID, map[string]interface{}{
+		"type":       string(models.Expense),
+		"start_date": month,
+		"end_date":   end,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	spent := 0.0
+	for _, t := range transactions {
+		if inCurrency[t.AccountID] && categories.within(t.CategoryID, budget.CategoryID) {
+			spent += t.Amount
+		}
+	}
+	return &models.BudgetStatus{
+		Budget: *budget,
+		Month:  month,
+		Spent:  roundCents(spent),
+	}, nil
+}
+
+// categoryTree maps each category to its parent
+type categoryTree map[uint]uint
+
+// categoryTree returns the categories userID can use
+func (s *BudgetService) categoryTree(ctx context.Context, userID uint) (categoryTree, error) {
+	categories, err := s.categoryRepo.GetAllForUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	tree := make(categoryTree, len(categories))
+	for _, category := range categories {
+		tree[category.ID] = category.ParentID
+	}
+	return tree, nil
+}
+
+// within reports whether id is ancestor or one of its descendants
+func (t categoryTree) within(id, ancestor uint) bool {
+	for seen := 0; id != 0 && seen <= len(t); seen++ {
+		if id == ancestor {
+			return true
+		}
+		id = t[id]
+	}
+	return false
+}
+
+// normalizeThresholds sorts and deduplicates thresholds, defaulting to
+// defaultThresholds when there are none
+func normalizeThresholds(thresholds []int) ([]int, error) {
+	if len(thresholds) == 0 {
+		return append([]int(nil), defaultThresholds...), nil
+	}
+	seen := make(map[int]bool)
+	var normalized []int
+	for _, threshold := range thresholds {
+		if threshold < 1 || threshold > 1000 {
+			return nil, fmt.Errorf("%w: got %d", ErrInvalidThreshold, threshold)
+		}
+		if !seen[threshold] {
+			seen[threshold] = true
+			normalized = append(normalized, threshold)
+		}
+	}
+	sort.Ints(normalized)
+	return normalized, nil
+}
@@ -0,0 +1,147 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/leandroalencar/banco-dados/services/s2-processor/internal/domain/models"
+	"github.com/leandroalencar/banco-dados/services/s2-processor/internal/domain/repositories/memory"
+)
+
+func TestBudgetServiceAlertsOncePerThreshold(t *testing.T) {
+	tests := []struct {
+		name     string
+		expenses []float64
+		// recategorize records the expenses uncategorized and then applies
+		// a rule that puts them in the budget's category
+		recategorize bool
+		alerts       int
+	}{
+		{name: "below every threshold", expenses: []float64{10, 20}, alerts: 0},
+		{name: "first threshold", expenses: []float64{30, 30}, alerts: 1},
+		{name: "first threshold twice", expenses: []float64{60, 10}, alerts: 1},
+		{name: "both thresholds at once", expenses: []float64{150}, alerts: 2},
+		{name: "both thresholds in turn", expenses: []float64{60, 50, 10}, alerts: 2},
+		{name: "recategorized below every threshold", expenses: []float64{10, 20}, recategorize: true, alerts: 0},
+		{name: "recategorized past both thresholds", expenses: []float64{60, 50}, recategorize: true, alerts: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			accountRepo := memory.NewAccountRepository()
+			categoryRepo := memory.NewCategoryRepository()
+			transactionRepo := memory.NewTransactionRepository(accountRepo)
+			outboxRepo := memory.NewOutboxRepository()
+			uow := memory.NewUnitOfWork()
+			budgets := NewBudgetService(memory.NewBudgetRepository(), accountRepo, categoryRepo, transactionRepo, outboxRepo, uow)
+			categorizer := NewCategorizationService(memory.NewCategorizationRuleRepository(), categoryRepo, accountRepo, transactionRepo, budgets, uow)
+			transactions := NewTransactionService(accountRepo, categoryRepo, transactionRepo, categorizer, budgets, uow)
+
+			account := &models.Account{UserID: 1, Name: "Checking", Type: models.Checking, Currency: "BRL"}
+			if err := accountRepo.Create(ctx, account); err != nil {
+				t.Fatal(err)
+			}
+			category := &models.Category{UserID: 1, Name: "Food"}
+			if err := categoryRepo.Create(ctx, category); err != nil {
+				t.Fatal(err)
+			}
+			budget := &models.Budget{CategoryID: category.ID, Limit: 100, Thresholds: []int{50, 100}}
+			if err := budgets.Create(ctx, 1, budget); err != nil {
+				t.Fatal(err)
+			}
+
+			for _, amount := range tt.expenses {
+				expense := &models.Transaction{AccountID: account.ID, Amount: amount, Type: models.Expense, Description: "Groceries", Date: time.Now()}
+				if !tt.recategorize {
+					expense.CategoryID = category.ID
+				}
+				if err := transactions.Create(ctx, 1, expense); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if tt.recategorize {
+				rule := &models.CategorizationRule{DescriptionPattern: "groceries", CategoryID: category.ID}
+				if err := categorizer.CreateRule(ctx, 1, rule); err != nil {
+					t.Fatal(err)
+				}
+				changed, err := categorizer.Apply(ctx, 1, false)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if changed != len(tt.expenses) {
+					t.Fatalf("recategorized %d transactions, want %d", changed, len(tt.expenses))
+				}
+			}
+
+			alerts := 0
+			for _, msg := range outboxRepo.Pending() {
+				if msg.Queue == BudgetAlertsQueue {
+					alerts++
+				}
+			}
+			if alerts != tt.alerts {
+				t.Errorf("sent %d alerts, want %d", alerts, tt.alerts)
+			}
+		})
+	}
+}
+
+func TestCategoryTreeWithin(t *testing.T) {
+	// 1 Food > 2 Restaurants > 3 Delivery; 4 Transport; 5 and 6 form a cycle
+	tree := categoryTree{1: 0, 2: 1, 3: 2, 4: 0, 5: 6, 6: 5}
+
+	tests := []struct {
+		name         string
+		id, ancestor uint
+		want         bool
+	}{
+		{name: "itself", id: 1, ancestor: 1, want: true},
+		{name: "child", id: 2, ancestor: 1, want: true},
+		{name: "grandchild", id: 3, ancestor: 1, want: true},
+		{name: "parent of the budget's category", id: 1, ancestor: 2, want: false},
+		{name: "sibling tree", id: 3, ancestor: 4, want: false},
+		{name: "uncategorized", id: 0, ancestor: 1, want: false},
+		{name: "unknown category", id: 9, ancestor: 1, want: false},
+		{name: "cycle terminates", id: 5, ancestor: 1, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tree.within(tt.id, tt.ancestor); got != tt.want {
+				t.Errorf("within(%d, %d) = %v, want %v", tt.id, tt.ancestor, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNormalizeThresholds(t *testing.T) {
+	tests := []struct {
+		name       string
+		thresholds []int
+		want       []int
+		err        error
+	}{
+		{name: "defaults", thresholds: nil, want: defaultThresholds},
+		{name: "sorted", thresholds: []int{100, 50, 80}, want: []int{50, 80, 100}},
+		{name: "deduplicated", thresholds: []int{80, 80, 100}, want: []int{80, 100}},
+		{name: "bounds", thresholds: []int{1000, 1}, want: []int{1, 1000}},
+		{name: "zero", thresholds: []int{0, 50}, err: ErrInvalidThreshold},
+		{name: "too high", thresholds: []int{1001}, err: ErrInvalidThreshold},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := normalizeThresholds(tt.thresholds)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("err = %v, want %v", err, tt.err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("thresholds = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,267 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/leandroalencar/banco-dados/services/s2-processor/internal/domain/models"
+	"github.com/leandroalencar/banco-dados/services/s2-processor/internal/domain/repositories"
+)
+
+const (
+	// learningSample is how many of a user's recent categorized
+	// transactions the fallback learns from
+	learningSample = 500
+
+	// minSimilarity is how alike two descriptions must be, as the Jaccard
+	// index of their words, for one to suggest a category for the other
+	minSimilarity = 0.5
+)
+
+var (
+	// ErrEmptyRule is returned for a rule without any condition
+	ErrEmptyRule = errors.New("rule needs a description pattern, an amount range or an account")
+
+	// ErrInvalidRule is returned for a rule whose conditions can't match
+	ErrInvalidRule = errors.New("invalid rule")
+)
+
+// CategorizationService categorizes transactions automatically. A user's
+// rules are tried first, by priority; when none matches, the category the
+// user most often chose for similar descriptions is used.
+type CategorizationService struct {
+	ruleRepo        repositories.CategorizationRuleRepository
+	categoryRepo    repositories.CategoryRepository
+	accountRepo     repositories.AccountRepository
+	transactionRepo repositories.TransactionRepository
+	budgets         *BudgetService
+	uow             repositories.UnitOfWork
+}
+
+// NewCategorizationService creates a new categorization service
+func NewCategorizationService(ruleRepo repositories.CategorizationRuleRepository, categoryRepo repositories.CategoryRepository, accountRepo repositories.AccountRepository, transactionRepo repositories.TransactionRepository, budgets *BudgetService, uow repositories.UnitOfWork) *CategorizationService {
+	return &CategorizationService{
+		ruleRepo:        ruleRepo,
+		categoryRepo:    categoryRepo,
+		accountRepo:     accountRepo,
+		transactionRepo: transactionRepo,
+		budgets:         budgets,
+		uow:             uow,
+	}
+}
+
+// CreateRule adds a rule of userID
+func (s *CategorizationService) CreateRule(ctx context.Context, userID uint, rule *models.CategorizationRule) error {
+	rule.UserID = userID
+	if err := s.validateRule(ctx, rule); err != nil {
+		return err
+	}
+	return s.ruleRepo.Create(ctx, rule)
+}
+
+// ListRules returns the rules of userID in the order they are tried
+func (s *CategorizationService) ListRules(ctx context.Context, userID uint) ([]models.CategorizationRule, error) {
+	return s.ruleRepo.GetAllByUserID(ctx, userID)
+}
+
+// ReplaceRule replaces the conditions and category of a rule of userID
+func (s *CategorizationService) ReplaceRule(ctx context.Context, userID, id uint, rule *models.CategorizationRule) error {
+	existing, err := s.rule(ctx, userID, id)
+	if err != nil {
+		return err
+	}
+	rule.ID = existing.ID
+	rule.UserID = userID
+	rule.CreatedAt = existing.CreatedAt
+	if err := s.validateRule(ctx, rule); err != nil {
+		return err
+	}
+	return s.ruleRepo.Update(ctx, rule)
+}
+
+// DeleteRule removes a rule of userID
+func (s *CategorizationService) DeleteRule(ctx context.Context, userID, id uint) error {
+	if _, err := s.rule(ctx, userID, id); err != nil {
+		return err
+	}
+	return s.ruleRepo.Delete(ctx, id)
+}
+
+// Categorize returns the category for a new transaction of userID, or 0 if
+// neither a rule nor past categorizations suggest one
+func (s *CategorizationService) Categorize(ctx context.Context, userID uint, transaction *models.Transaction) (uint, error) {
+	rules, err := s.ruleRepo.GetAllByUserID(ctx, userID)
+	if err != nil {
+		return 0, err
+	}
+	if categoryID := firstMatch(rules, transaction); categoryID != 0 {
+		return categoryID, nil
+	}
+	return s.learned(ctx, userID, transaction.Description)
+}
+
+// Apply runs the rules of userID over the user's existing income and
+// expense transactions. Only uncategorized transactions are changed unless
+// overwrite is set. Budgets covering a recategorized expense are checked
+// in the same unit of work that changes it. It returns how many
+// transactions were changed.
+func (s *CategorizationService) Apply(ctx context.Context, userID uint, overwrite bool) (int, error) {
+	rules, err := s.ruleRepo.GetAllByUserID(ctx, userID)
+	if err != nil {
+		return 0, err
+	}
+	transactions, err := s.transactionRepo.GetAllByUserID(ctx, userID, nil)
+	if err != nil {
+		return 0, err
+	}
+
+	changed := 0
+	for i := range transactions {
+		transaction := &transactions[i]
+		if transaction.Type == models.Transfer || transaction.Type == models.Exchange || (transaction.CategoryID != 0 && !overwrite) {
+			continue
+		}
+		categoryID := firstMatch(rules, transaction)
+		if categoryID == 0 || categoryID == transaction.CategoryID {
+			continue
+		}
+		transaction.CategoryID = categoryID
+		err := s.uow.Do(ctx, func(ctx context.Context) error {
+			if err := s.transactionRepo.Update(ctx, transaction); err != nil {
+				return err
+			}
+			return s.budgets.Track(ctx, transaction)
+		})
+		if err != nil {
+			return changed, err
+		}
+		changed++
+	}
+	return changed, nil
+}
+
+// rule returns a rule of userID
+func (s *CategorizationService) rule(ctx context.Context, userID, id uint) (*models.CategorizationRule, error) {
+	rule, err := s.ruleRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if rule.UserID != userID {
+		return nil, repositories.ErrRuleNotFound
+	}
+	return rule, nil
+}
+
+// validateRule checks that a rule has conditions that can match and that
+// it only refers to the category and account its user may use
+func (s *CategorizationService) validateRule(ctx context.Context, rule *models.CategorizationRule) error {
+	rule.DescriptionPattern = strings.TrimSpace(rule.DescriptionPattern)
+	if rule.MatchType == "" {
+		rule.MatchType = models.MatchContains
+	}
+	if rule.DescriptionPattern == "" && rule.MinAmount == nil && rule.MaxAmount == nil && rule.AccountID == 0 {
+		return ErrEmptyRule
+	}
+	switch rule.MatchType {
+	case models.MatchContains, models.MatchEquals:
+	case models.MatchRegex:
+		if _, err := rule.Regexp(); err != nil {
+			return fmt.Errorf("%w: %v", ErrInvalidRule, err)
+		}
+	default:
+		return fmt.Errorf("%w: unknown match type %q", ErrInvalidRule, rule.MatchType)
+	}
+	if rule.MinAmount != nil && rule.MaxAmount != nil && *rule.MinAmount > *rule.MaxAmount {
+		return fmt.Errorf("%w: min_amount is above max_amount", ErrInvalidRule)
+	}
+
+	category, err := s.categoryRepo.GetByID(ctx, rule.CategoryID)
+	if err != nil {
+		return err
+	}
+	if !category.VisibleTo(rule.UserID) {
+		return repositories.ErrCategoryNotFound
+	}
+	if rule.AccountID != 0 {
+		account, err := s.accountRepo.GetByID(ctx, rule.AccountID)
+		if err != nil {
+			return err
+		}
+		if account.UserID != rule.UserID {
+			return repositories.ErrAccountNotFound
+		}
+	}
+	return nil
+}
+
+// learned suggests the category the user gave most often to transactions
+// with a similar description, weighting each by its similarity
+func (s *CategorizationService) learned(ctx context.Context, userID uint, description string) (uint, error) {
+	words := descriptionWords(description)
+	if len(words) == 0 {
+		return 0, nil
+	}
+
+	past, err := s.transactionRepo.GetRecentCategorized(ctx, userID, learningSample)
+	if err != nil {
+		return 0, err
+	}
+
+	votes := make(map[uint]float64)
+	for _, t := range past {
+		if similarity := jaccard(words, descriptionWords(t.Description)); similarity >= minSimilarity {
+			votes[t.CategoryID] += similarity
+		}
+	}
+
+	best, bestVotes := uint(0), 0.0
+	for categoryID, v := range votes {
+		if v > bestVotes || (v == bestVotes && categoryID < best) {
+			best, bestVotes = categoryID, v
+		}
+	}
+	return best, nil
+}
+
+// firstMatch returns the category of the first rule matching transaction,
+// or 0 if none does
+func firstMatch(rules []models.CategorizationRule, transaction *models.Transaction) uint {
+	for i := range rules {
+		if rules[i].Matches(transaction) {
+			return rules[i].CategoryID
+		}
+	}
+	return 0
+}
+
+// descriptionWords returns the distinct lower-cased words of a description.
+// Numbers and single letters are dropped, as they tend to be dates,
+// amounts and reference codes that differ between otherwise equal entries.
+func descriptionWords(description string) map[string]bool {
+	words := make(map[string]bool)
+	for _, word := range strings.FieldsFunc(strings.ToLower(description), func(r rune) bool {
+		return !unicode.IsLetter(r)
+	}) {
+		if len([]rune(word)) > 1 {
+			words[word] = true
+		}
+	}
+	return words
+}
+
+// jaccard returns the Jaccard index of two word sets
+func jaccard(a, b map[string]bool) float64 {
+	if len(a) == 0 || len(b) == 0 {
+		return 0
+	}
+	common := 0
+	for word := range a {
+		if b[word] {
+			common++
+		}
+	}
+	return float64(common) / float64(len(a)+len(b)-common)
+}
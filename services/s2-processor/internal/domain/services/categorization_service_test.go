@@ -0,0 +1,137 @@
+package services
+
+import (
+	"context"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/leandroalencar/banco-dados/services/s2-processor/internal/domain/models"
+	"github.com/leandroalencar/banco-dados/services/s2-processor/internal/domain/repositories/memory"
+)
+
+func TestJaccard(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b string
+		want float64
+	}{
+		{name: "same words", a: "Padaria Pão Quente", b: "padaria pão quente", want: 1},
+		{name: "order and punctuation don't matter", a: "Uber *Trip", b: "trip, uber", want: 1},
+		{name: "numbers and single letters are dropped", a: "Netflix 12/05 x", b: "NETFLIX 13/06 y", want: 1},
+		{name: "half the words", a: "supermercado extra", b: "supermercado pao", want: 1.0 / 3},
+		{name: "nothing in common", a: "posto shell", b: "farmacia drogasil", want: 0},
+		{name: "no words", a: "12345", b: "12345", want: 0},
+		{name: "one side empty", a: "", b: "ifood", want: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := jaccard(descriptionWords(tt.a), descriptionWords(tt.b)); math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("jaccard(%q, %q) = %v, want %v", tt.a, tt.b, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCategorizationServiceCategorize(t *testing.T) {
+	type categorized struct {
+		description string
+		category    string
+	}
+
+	tests := []struct {
+		name string
+		// rules maps description patterns to categories, in priority order
+		rules       []categorized
+		past        []categorized
+		description string
+		want        string
+	}{
+		{name: "nothing to go by", description: "Uber trip", want: ""},
+		{
+			name:        "first rule by priority",
+			rules:       []categorized{{"uber", "Transport"}, {"trip", "Travel"}},
+			description: "Uber trip",
+			want:        "Transport",
+		},
+		{
+			name:        "rule wins over history",
+			rules:       []categorized{{"uber", "Transport"}},
+			past:        []categorized{{"Uber trip", "Travel"}},
+			description: "Uber trip",
+			want:        "Transport",
+		},
+		{
+			name:        "learned from similar descriptions",
+			rules:       []categorized{{"ifood", "Food"}},
+			past:        []categorized{{"Uber trip 12/05", "Transport"}},
+			description: "UBER TRIP 13/06",
+			want:        "Transport",
+		},
+		{
+			name:        "most similar votes win",
+			past:        []categorized{{"Posto Shell Centro", "Car"}, {"Posto Ipiranga Centro", "Car"}, {"Shell Select Centro", "Food"}},
+			description: "Posto Shell Centro",
+			want:        "Car",
+		},
+		{
+			name:        "too different to learn from",
+			past:        []categorized{{"Posto Shell Centro", "Car"}},
+			description: "Farmacia Centro",
+			want:        "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			accountRepo := memory.NewAccountRepository()
+			categoryRepo := memory.NewCategoryRepository()
+			transactionRepo := memory.NewTransactionRepository(accountRepo)
+			uow := memory.NewUnitOfWork()
+			budgets := NewBudgetService(memory.NewBudgetRepository(), accountRepo, categoryRepo, transactionRepo, memory.NewOutboxRepository(), uow)
+			categorizer := NewCategorizationService(memory.NewCategorizationRuleRepository(), categoryRepo, accountRepo, transactionRepo, budgets, uow)
+
+			account := &models.Account{UserID: 1, Name: "Checking", Type: models.Checking, Currency: "BRL"}
+			if err := accountRepo.Create(ctx, account); err != nil {
+				t.Fatal(err)
+			}
+			names := map[uint]string{}
+			ids := map[string]uint{}
+			category := func(name string) uint {
+				if id, ok := ids[name]; ok {
+					return id
+				}
+				c := &models.Category{UserID: 1, Name: name}
+				if err := categoryRepo.Create(ctx, c); err != nil {
+					t.Fatal(err)
+				}
+				ids[name], names[c.ID] = c.ID, name
+				return c.ID
+			}
+
+			for priority, r := range tt.rules {
+				rule := &models.CategorizationRule{Priority: priority, DescriptionPattern: r.description, CategoryID: category(r.category)}
+				if err := categorizer.CreateRule(ctx, 1, rule); err != nil {
+					t.Fatal(err)
+				}
+			}
+			for _, p := range tt.past {
+				past := &models.Transaction{AccountID: account.ID, CategoryID: category(p.category), Amount: 10, Type: models.Expense, Description: p.description, Date: time.Now()}
+				if err := transactionRepo.Post(ctx, past); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			transaction := &models.Transaction{AccountID: account.ID, Amount: 10, Type: models.Expense, Description: tt.description}
+			got, err := categorizer.Categorize(ctx, 1, transaction)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if names[got] != tt.want {
+				t.Errorf("categorized as %q, want %q", names[got], tt.want)
+			}
+		})
+	}
+}
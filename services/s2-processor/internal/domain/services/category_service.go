@@ -0,0 +1,136 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/leandroalencar/banco-dados/services/s2-processor/internal/domain/models"
+	"github.com/leandroalencar/banco-dados/services/s2-processor/internal/domain/repositories"
+)
+
+var (
+	// ErrCategoryNameRequired is returned for a category without a name
+	ErrCategoryNameRequired = errors.New("category name is required")
+
+	// ErrCategoryReadOnly is returned when changing a system category
+	ErrCategoryReadOnly = errors.New("system categories cannot be changed")
+
+	// ErrCategoryCycle is returned when a category would become its own
+	// ancestor
+	ErrCategoryCycle = errors.New("category cannot be nested under itself")
+)
+
+// CategoryService handles business logic related to categories. Users see
+// the system categories and their own; categories of other users behave as
+// if they didn't exist.
+type CategoryService struct {
+	categoryRepo repositories.CategoryRepository
+}
+
+// NewCategoryService creates a new category service
+func NewCategoryService(categoryRepo repositories.CategoryRepository) *CategoryService {
+	return &CategoryService{categoryRepo: categoryRepo}
+}
+
+// CategoryChanges lists the fields of a category to update. Nil fields are
+// left as they are; a zero ParentID moves the category to the top level.
+type CategoryChanges struct {
+	Name     *string
+	ParentID *uint
+}
+
+// Create adds a category of userID
+func (s *CategoryService) Create(ctx context.Context, userID uint, category *models.Category) error {
+	category.UserID = userID
+	category.Name = strings.TrimSpace(category.Name)
+	if category.Name == "" {
+		return ErrCategoryNameRequired
+	}
+	if category.ParentID != 0 {
+		if _, err := s.Get(ctx, userID, category.ParentID); err != nil {
+			return err
+		}
+	}
+	return s.categoryRepo.Create(ctx, category)
+}
+
+// List returns the categories userID can use
+func (s *CategoryService) List(ctx context.Context, userID uint) ([]models.Category, error) {
+	return s.categoryRepo.GetAllForUser(ctx, userID)
+}
+
+// Get returns a category userID can use
+func (s *CategoryService) Get(ctx context.Context, userID, id uint) (*models.Category, error) {
+	category, err := s.categoryRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if !category.VisibleTo(userID) {
+		return nil, repositories.ErrCategoryNotFound
+	}
+	return category, nil
+}
+
+// Update renames or moves a category of userID
+func (s *CategoryService) Update(ctx context.Context, userID, id uint, changes CategoryChanges) (*models.Category, error) {
+	category, err := s.owned(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if changes.Name != nil {
+		category.Name = strings.TrimSpace(*changes.Name)
+		if category.Name == "" {
+			return nil, ErrCategoryNameRequired
+		}
+	}
+	if changes.ParentID != nil {
+		if err := s.checkParent(ctx, userID, id, *changes.ParentID); err != nil {
+			return nil, err
+		}
+		category.ParentID = *changes.ParentID
+	}
+
+	if err := s.categoryRepo.Update(ctx, category); err != nil {
+		return nil, err
+	}
+	return category, nil
+}
+
+// Delete removes a category of userID that has no subcategories
+func (s *CategoryService) Delete(ctx context.Context, userID, id uint) error {
+	if _, err := s.owned(ctx, userID, id); err != nil {
+		return err
+	}
+	return s.categoryRepo.Delete(ctx, id)
+}
+
+// owned returns a category userID may change
+func (s *CategoryService) owned(ctx context.Context, userID, id uint) (*models.Category, error) {
+	category, err := s.Get(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+	if category.IsSystem() {
+		return nil, ErrCategoryReadOnly
+	}
+	return category, nil
+}
+
+// checkParent verifies that category id can be nested under parentID: the
+// parent must be visible to userID and must not be id or one of its
+// descendants
+func (s *CategoryService) checkParent(ctx context.Context, userID, id, parentID uint) error {
+	for ancestor := parentID; ancestor != 0; {
+		if ancestor == id {
+			return ErrCategoryCycle
+		}
+		category, err := s.Get(ctx, userID, ancestor)
+		if err != nil {
+			return err
+		}
+		ancestor = category.ParentID
+	}
+	return nil
+}
@@ -0,0 +1,258 @@
+package services
+
+import (
+	"bufio"
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/leandroalencar/banco-dados/services/s2-processor/internal/domain/models"
+)
+
+const (
+	// ofxBankID identifies this service as the bank in OFX exports
+	ofxBankID = "000000000"
+
+	// ofxNoCurrency is the ISO 4217 code for transactions without a
+	// currency, used for accounts that were closed
+	ofxNoCurrency = "XXX"
+
+	// ofxNameLength is the longest payee name OFX allows
+	ofxNameLength = 32
+)
+
+// OFX writes the transactions of userID as an OFX 2.2 document with a bank
+// statement per account. Trades follow in a statement per currency pair,
+// denominated in the pair's quote currency and with the traded base amount
+// as the original currency.
+func (s *ExportService) OFX(ctx context.Context, userID uint, filters map[string]interface{}, w io.Writer) error {
+	accounts, err := s.accounts(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	from, ok := filters["start_date"].(time.Time)
+	if !ok {
+		from = time.Unix(0, 0)
+	}
+	to, ok := filters["end_date"].(time.Time)
+	if !ok {
+		to = now
+	}
+	out := &ofxWriter{w: bufio.NewWriter(w), from: from, to: to, now: now}
+	out.begin()
+
+	err = s.each(ctx, userID, filters,
+		func(t *models.Transaction) error {
+			if id := formatUint(t.AccountID); id != out.statement {
+				account, ok := accounts[t.AccountID]
+				if !ok {
+					out.openStatement(id, "CHECKING", ofxNoCurrency, nil)
+				} else {
+					out.openStatement(id, ofxAccountType(account.Type), account.Currency, &account.Balance)
+				}
+			}
+
+			trnType := "XFER"
+			switch t.Type {
+			case models.Income:
+				trnType = "CREDIT"
+			case models.Expense:
+				trnType = "DEBIT"
+			}
+			name := t.Description
+			if name == "" {
+				name = string(t.Type)
+			}
+			out.transaction(trnType, t.Date, t.BalanceDelta(), "T"+formatUint(t.ID), name, "", nil)
+			return out.err
+		},
+		func(t *models.Trade) error {
+			base, quote, _ := strings.Cut(t.CurrencyPair, "/")
+			if id := "FX-" + t.CurrencyPair; id != out.statement {
+				out.openStatement(id, "MONEYMRKT", quote, nil)
+			}
+
+			trnType, amount := "DEBIT", -t.TotalValue
+			if t.Type == models.TradeSell {
+				trnType, amount = "CREDIT", t.TotalValue
+			}
+			memo := fmt.Sprintf("%s %s at %s", formatMoney(t.Amount), base, strconv.FormatFloat(t.ExchangeRate, 'f', -1, 64))
+			if t.QuotationID != "" {
+				memo += ", quotation " + t.QuotationID
+			}
+			out.transaction(trnType, t.RequestedAt, amount, "FX"+formatUint(t.ID), string(t.Type)+" "+t.CurrencyPair, memo, &ofxCurrency{rate: t.ExchangeRate, symbol: base})
+			return out.err
+		},
+	)
+	if err != nil {
+		return err
+	}
+
+	out.end()
+	if out.err != nil {
+		return out.err
+	}
+	return out.w.Flush()
+}
+
+// ofxAccountType maps an account type to the OFX account type
+func ofxAccountType(accountType models.AccountType) string {
+	switch accountType {
+	case models.Savings:
+		return "SAVINGS"
+	case models.Credit:
+		return "CREDITLINE"
+	case models.Investment:
+		return "MONEYMRKT"
+	default:
+		return "CHECKING"
+	}
+}
+
+// ofxCurrency is the original currency of an OFX transaction
+type ofxCurrency struct {
+	rate   float64
+	symbol string
+}
+
+// ofxWriter writes an OFX document one statement at a time. The first write
+// error is kept in err and makes every later write a no-op.
+type ofxWriter struct {
+	w        *bufio.Writer
+	err      error
+	from, to time.Time
+	now      time.Time
+
+	// statements counts the statements opened so far; statement is the ID
+	// of the open one
+	statements int
+	statement  string
+
+	// balance is the ledger balance of the open statement, if known, and
+	// sum the total of its transactions
+	balance *float64
+	sum     float64
+}
+
+func (o *ofxWriter) begin() {
+	o.print(`<?xml version="1.0" encoding="UTF-8" standalone="no"?>` + "\n")
+	o.print(`<?OFX OFXHEADER="200" VERSION="220" SECURITY="NONE" OLDFILEUID="NONE" NEWFILEUID="NONE"?>` + "\n")
+	o.print("<OFX>\n<SIGNONMSGSRSV1><SONRS>")
+	o.status()
+	o.element("DTSERVER", ofxDate(o.now))
+	o.element("LANGUAGE", "POR")
+	o.print("</SONRS></SIGNONMSGSRSV1>\n")
+}
+
+func (o *ofxWriter) end() {
+	o.closeStatement()
+	if o.statements > 0 {
+		o.print("</BANKMSGSRSV1>\n")
+	}
+	o.print("</OFX>\n")
+}
+
+// openStatement closes the open statement, if any, and starts one for
+// account id. A nil balance reports the sum of the transactions instead.
+func (o *ofxWriter) openStatement(id, accountType, currency string, balance *float64) {
+	o.closeStatement()
+	if o.statements == 0 {
+		o.print("<BANKMSGSRSV1>\n")
+	}
+	o.statements++
+	o.statement = id
+	o.balance = balance
+	o.sum = 0
+
+	o.print("<STMTTRNRS>")
+	o.element("TRNUID", strconv.Itoa(o.statements))
+	o.status()
+	o.print("<STMTRS>")
+	o.element("CURDEF", currency)
+	o.print("<BANKACCTFROM>")
+	o.element("BANKID", ofxBankID)
+	o.element("ACCTID", id)
+	o.element("ACCTTYPE", accountType)
+	o.print("</BANKACCTFROM>\n<BANKTRANLIST>")
+	o.element("DTSTART", ofxDate(o.from))
+	o.element("DTEND", ofxDate(o.to))
+	o.print("\n")
+}
+
+func (o *ofxWriter) closeStatement() {
+	if o.statement == "" {
+		return
+	}
+	balance := o.sum
+	if o.balance != nil {
+		balance = *o.balance
+	}
+	o.print("</BANKTRANLIST>\n<LEDGERBAL>")
+	o.element("BALAMT", formatMoney(balance))
+	o.element("DTASOF", ofxDate(o.now))
+	o.print("</LEDGERBAL></STMTRS></STMTTRNRS>\n")
+	o.statement = ""
+}
+
+// transaction writes a transaction of the open statement. amount is signed,
+// as it changes the balance. A name too long for OFX is cut, and kept whole
+// in the memo if there is none.
+func (o *ofxWriter) transaction(trnType string, date time.Time, amount float64, fitID, name, memo string, original *ofxCurrency) {
+	o.sum += amount
+	if runes := []rune(name); len(runes) > ofxNameLength {
+		if memo == "" {
+			memo = name
+		}
+		name = string(runes[:ofxNameLength])
+	}
+
+	o.print("<STMTTRN>")
+	o.element("TRNTYPE", trnType)
+	o.element("DTPOSTED", ofxDate(date))
+	o.element("TRNAMT", formatMoney(amount))
+	o.element("FITID", fitID)
+	o.element("NAME", name)
+	if memo != "" {
+		o.element("MEMO", memo)
+	}
+	if original != nil {
+		o.print("<ORIGCURRENCY>")
+		o.element("CURRATE", strconv.FormatFloat(original.rate, 'f', -1, 64))
+		o.element("CURSYM", original.symbol)
+		o.print("</ORIGCURRENCY>")
+	}
+	o.print("</STMTTRN>\n")
+}
+
+func (o *ofxWriter) status() {
+	o.print("<STATUS>")
+	o.element("CODE", "0")
+	o.element("SEVERITY", "INFO")
+	o.print("</STATUS>")
+}
+
+// element writes <name>value</name>, escaping value
+func (o *ofxWriter) element(name, value string) {
+	o.print("<" + name + ">")
+	if o.err == nil {
+		o.err = xml.EscapeText(o.w, []byte(value))
+	}
+	o.print("</" + name + ">")
+}
+
+func (o *ofxWriter) print(s string) {
+	if o.err == nil {
+		_, o.err = o.w.WriteString(s)
+	}
+}
+
+// ofxDate formats a time as an OFX date in GMT
+func ofxDate(t time.Time) string {
+	return t.UTC().Format("20060102150405") + ".000[0:GMT]"
+}
@@ -0,0 +1,158 @@
+package services
+
+import (
+	"context"
+	"encoding/csv"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/leandroalencar/banco-dados/services/s2-processor/internal/domain/models"
+	"github.com/leandroalencar/banco-dados/services/s2-processor/internal/domain/repositories"
+)
+
+// TradeFilter is the type filter value that exports only currency trades
+const TradeFilter = "trade"
+
+// csvHeader names the columns of a CSV export. Ledger transactions fill the
+// account columns and trades the currency columns. amount is signed for
+// transactions, as applied to the balance, and in the base currency for
+// trades; total_value is in the quote currency.
+var csvHeader = []string{
+	"kind", "id", "date", "type", "account_id", "category_id", "transfer_id",
+	"description", "amount", "currency", "currency_pair", "exchange_rate",
+	"total_value", "quotation_id",
+}
+
+// ExportService writes a user's transactions and completed currency trades
+// in formats accounting tools import. Records are streamed from the
+// database to the writer, so exports of any size use constant memory.
+//
+// Filters are those of TransactionRepository.GetAllByUserID. The date range
+// applies to trades too; a type other than TradeFilter or a category
+// exclude trades, and TradeFilter exports only trades.
+type ExportService struct {
+	accountRepo     repositories.AccountRepository
+	transactionRepo repositories.TransactionRepository
+	tradeRepo       repositories.TradeRepository
+}
+
+// NewExportService creates a new export service
+func NewExportService(accountRepo repositories.AccountRepository, transactionRepo repositories.TransactionRepository, tradeRepo repositories.TradeRepository) *ExportService {
+	return &ExportService{
+		accountRepo:     accountRepo,
+		transactionRepo: transactionRepo,
+		tradeRepo:       tradeRepo,
+	}
+}
+
+// CSV writes the transactions of userID, then their trades, as CSV
+func (s *ExportService) CSV(ctx context.Context, userID uint, filters map[string]interface{}, w io.Writer) error {
+	accounts, err := s.accounts(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	out := csv.NewWriter(w)
+	if err := out.Write(csvHeader); err != nil {
+		return err
+	}
+
+	err = s.each(ctx, userID, filters,
+		func(t *models.Transaction) error {
+			return out.Write([]string{
+				"transaction",
+				formatUint(t.ID),
+				t.Date.UTC().Format(time.RFC3339),
+				string(t.Type),
+				formatUint(t.AccountID),
+				formatUint(t.CategoryID),
+				formatUint(t.TransferID),
+				csvText(t.Description),
+				formatMoney(t.BalanceDelta()),
+				csvText(accounts[t.AccountID].Currency),
+				"", "", "", "",
+			})
+		},
+		func(t *models.Trade) error {
+			base, _, _ := strings.Cut(t.CurrencyPair, "/")
+			return out.Write([]string{
+				"trade",
+				formatUint(t.ID),
+				t.RequestedAt.UTC().Format(time.RFC3339),
+				string(t.Type),
+				"", "", "", "",
+				formatMoney(t.Amount),
+				csvText(base),
+				csvText(t.CurrencyPair),
+				strconv.FormatFloat(t.ExchangeRate, 'f', -1, 64),
+				formatMoney(t.TotalValue),
+				csvText(t.QuotationID),
+			})
+		},
+	)
+	if err != nil {
+		return err
+	}
+
+	out.Flush()
+	return out.Error()
+}
+
+// each streams the transactions of userID matching filters to onTransaction
+// and then their trades to onTrade
+func (s *ExportService) each(ctx context.Context, userID uint, filters map[string]interface{}, onTransaction func(*models.Transaction) error, onTrade func(*models.Trade) error) error {
+	transactionType, _ := filters["type"].(string)
+	categoryID, _ := filters["category_id"].(uint)
+
+	if transactionType != TradeFilter {
+		if err := s.transactionRepo.EachByUserID(ctx, userID, filters, onTransaction); err != nil {
+			return err
+		}
+	}
+	if (transactionType != "" && transactionType != TradeFilter) || categoryID != 0 {
+		return nil
+	}
+
+	from, _ := filters["start_date"].(time.Time)
+	to, _ := filters["end_date"].(time.Time)
+	return s.tradeRepo.EachCompletedByUserID(ctx, userID, from, to, onTrade)
+}
+
+// accounts returns the open accounts of userID by ID. Transactions of
+// closed accounts are still exported, without the account's details.
+func (s *ExportService) accounts(ctx context.Context, userID uint) (map[uint]models.Account, error) {
+	accounts, err := s.accountRepo.GetAllByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[uint]models.Account, len(accounts))
+	for _, account := range accounts {
+		byID[account.ID] = account
+	}
+	return byID, nil
+}
+
+// formatUint formats an ID, leaving the zero ID empty
+func formatUint(id uint) string {
+	if id == 0 {
+		return ""
+	}
+	return strconv.FormatUint(uint64(id), 10)
+}
+
+// csvText escapes a free-text cell that spreadsheets would run as a
+// formula by prefixing it with an apostrophe. Numbers are written by the
+// export itself and left as they are.
+func csvText(cell string) string {
+	if cell != "" && strings.ContainsRune("=+-@\t\r", rune(cell[0])) {
+		return "'" + cell
+	}
+	return cell
+}
+
+// formatMoney formats an amount with two decimals
+func formatMoney(amount float64) string {
+	return strconv.FormatFloat(amount, 'f', 2, 64)
+}
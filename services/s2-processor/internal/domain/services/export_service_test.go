@@ -0,0 +1,212 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/xml"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/leandroalencar/banco-dados/services/s2-processor/internal/domain/models"
+	"github.com/leandroalencar/banco-dados/services/s2-processor/internal/domain/repositories/memory"
+)
+
+func TestExportServiceCSVEscapesFormulas(t *testing.T) {
+	tests := []struct {
+		name        string
+		description string
+		want        string
+	}{
+		{name: "plain text", description: "Groceries", want: "Groceries"},
+		{name: "empty", description: "", want: ""},
+		{name: "equals", description: "=HYPERLINK(\"http://example.com\")", want: "'=HYPERLINK(\"http://example.com\")"},
+		{name: "plus", description: "+1+1", want: "'+1+1"},
+		{name: "minus", description: "-2+3", want: "'-2+3"},
+		{name: "at", description: "@SUM(A1:A2)", want: "'@SUM(A1:A2)"},
+		{name: "tab", description: "\t=1", want: "'\t=1"},
+		{name: "formula later on", description: "Lunch =1+1", want: "Lunch =1+1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			accountRepo := memory.NewAccountRepository()
+			transactionRepo := memory.NewTransactionRepository(accountRepo)
+			exports := NewExportService(accountRepo, transactionRepo, memory.NewTradeRepository(accountRepo, transactionRepo))
+
+			account := &models.Account{UserID: 1, Name: "Checking", Type: models.Checking, Currency: "BRL"}
+			if err := accountRepo.Create(ctx, account); err != nil {
+				t.Fatal(err)
+			}
+			expense := &models.Transaction{AccountID: account.ID, Amount: 12.5, Type: models.Expense, Description: tt.description, Date: time.Now()}
+			if err := transactionRepo.Post(ctx, expense); err != nil {
+				t.Fatal(err)
+			}
+
+			var buf bytes.Buffer
+			if err := exports.CSV(ctx, 1, nil, &buf); err != nil {
+				t.Fatal(err)
+			}
+			rows, err := csv.NewReader(&buf).ReadAll()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(rows) != 2 {
+				t.Fatalf("exported %d rows, want a header and one transaction", len(rows))
+			}
+			if got := rows[1][7]; got != tt.want {
+				t.Errorf("description = %q, want %q", got, tt.want)
+			}
+			if got := rows[1][8]; got != "-12.50" {
+				t.Errorf("amount = %q, want -12.50", got)
+			}
+		})
+	}
+}
+
+// exportFixture creates an export service over a user with an income and
+// a categorized expense on a BRL account and a completed trade per
+// currency pair, posted between it and a USD and a EUR account, plus a
+// rejected trade that is never exported
+func exportFixture(t *testing.T) (*ExportService, uint) {
+	t.Helper()
+	ctx := context.Background()
+	accountRepo := memory.NewAccountRepository()
+	transactionRepo := memory.NewTransactionRepository(accountRepo)
+	tradeRepo := memory.NewTradeRepository(accountRepo, transactionRepo)
+
+	account := &models.Account{UserID: 1, Name: "Checking", Type: models.Savings, Currency: "BRL"}
+	usd := &models.Account{UserID: 1, Name: "Dollars", Type: models.Checking, Currency: "USD"}
+	eur := &models.Account{UserID: 1, Name: "Euros", Type: models.Checking, Currency: "EUR", Balance: 10}
+	for _, a := range []*models.Account{account, usd, eur} {
+		if err := accountRepo.Create(ctx, a); err != nil {
+			t.Fatal(err)
+		}
+	}
+	day := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	for _, transaction := range []*models.Transaction{
+		{Type: models.Income, Amount: 1000, Description: "Salary", Date: day},
+		{Type: models.Expense, Amount: 45.9, CategoryID: 7, Description: "Supermercado Pão de Açúcar & Cia - Unidade Centro", Date: day.AddDate(0, 0, 1)},
+	} {
+		transaction.AccountID = account.ID
+		if err := transactionRepo.Post(ctx, transaction); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, trade := range []*models.Trade{
+		{CorrelationID: "a", UserID: 1, Type: models.TradeBuy, CurrencyPair: "USD/BRL", Amount: 100, ExchangeRate: 5, TotalValue: 500, QuotationID: "q1", Status: models.TradeCompleted, BaseAccountID: usd.ID, QuoteAccountID: account.ID, RequestedAt: day},
+		{CorrelationID: "b", UserID: 1, Type: models.TradeSell, CurrencyPair: "EUR/BRL", Amount: 10, ExchangeRate: 5.5, TotalValue: 55, Status: models.TradeCompleted, BaseAccountID: eur.ID, QuoteAccountID: account.ID, RequestedAt: day},
+		{CorrelationID: "c", UserID: 1, Type: models.TradeBuy, CurrencyPair: "USD/BRL", Amount: 1e6, Status: models.TradeRejected, RequestedAt: day},
+	} {
+		if err := tradeRepo.Create(ctx, trade); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return NewExportService(accountRepo, transactionRepo, tradeRepo), account.ID
+}
+
+func TestExportServiceCSVFilters(t *testing.T) {
+	tests := []struct {
+		name    string
+		filters map[string]interface{}
+		// rows lists the kind and type of each exported row
+		rows []string
+	}{
+		{
+			name: "everything",
+			rows: []string{
+				"transaction income", "transaction exchange", "transaction exchange", "transaction expense",
+				"transaction exchange", "transaction exchange", "trade SELL", "trade BUY",
+			},
+		},
+		{name: "trade legs", filters: map[string]interface{}{"type": "exchange"}, rows: []string{"transaction exchange", "transaction exchange", "transaction exchange", "transaction exchange"}},
+		{name: "expenses", filters: map[string]interface{}{"type": "expense"}, rows: []string{"transaction expense"}},
+		{name: "trades only", filters: map[string]interface{}{"type": TradeFilter}, rows: []string{"trade SELL", "trade BUY"}},
+		{name: "category excludes trades", filters: map[string]interface{}{"category_id": uint(7)}, rows: []string{"transaction expense"}},
+		{
+			name:    "date range applies to trades",
+			filters: map[string]interface{}{"start_date": time.Date(2026, 3, 11, 0, 0, 0, 0, time.UTC)},
+			rows:    []string{"transaction expense"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			exports, _ := exportFixture(t)
+			var buf bytes.Buffer
+			if err := exports.CSV(context.Background(), 1, tt.filters, &buf); err != nil {
+				t.Fatal(err)
+			}
+			records, err := csv.NewReader(&buf).ReadAll()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(records[0], csvHeader) {
+				t.Errorf("header = %v, want %v", records[0], csvHeader)
+			}
+			var rows []string
+			for _, record := range records[1:] {
+				if len(record) != len(csvHeader) {
+					t.Errorf("row %v has %d columns, want %d", record, len(record), len(csvHeader))
+				}
+				rows = append(rows, record[0]+" "+record[3])
+			}
+			if !reflect.DeepEqual(rows, tt.rows) {
+				t.Errorf("rows = %v, want %v", rows, tt.rows)
+			}
+		})
+	}
+}
+
+func TestExportServiceOFX(t *testing.T) {
+	exports, accountID := exportFixture(t)
+	var buf bytes.Buffer
+	if err := exports.OFX(context.Background(), 1, nil, &buf); err != nil {
+		t.Fatal(err)
+	}
+	document := buf.String()
+
+	// The document must be well-formed XML
+	decoder := xml.NewDecoder(strings.NewReader(document))
+	for {
+		if _, err := decoder.Token(); err == io.EOF {
+			break
+		} else if err != nil {
+			t.Fatalf("invalid XML: %v", err)
+		}
+	}
+
+	tests := []struct {
+		name string
+		want string
+	}{
+		{name: "account statement", want: "<ACCTID>" + formatUint(accountID) + "</ACCTID><ACCTTYPE>SAVINGS</ACCTTYPE>"},
+		{name: "account balance", want: "<BALAMT>509.10</BALAMT>"},
+		{name: "income credits", want: "<TRNTYPE>CREDIT</TRNTYPE><DTPOSTED>20260310120000.000[0:GMT]</DTPOSTED><TRNAMT>1000.00</TRNAMT>"},
+		{name: "expense debits", want: "<TRNTYPE>DEBIT</TRNTYPE><DTPOSTED>20260311120000.000[0:GMT]</DTPOSTED><TRNAMT>-45.90</TRNAMT>"},
+		{name: "long names are cut and escaped", want: "<NAME>Supermercado Pão de Açúcar &amp; Cia</NAME><MEMO>Supermercado Pão de Açúcar &amp; Cia - Unidade Centro</MEMO>"},
+		{name: "trade statement per pair", want: "<CURDEF>BRL</CURDEF><BANKACCTFROM><BANKID>000000000</BANKID><ACCTID>FX-USD/BRL</ACCTID><ACCTTYPE>MONEYMRKT</ACCTTYPE>"},
+		{name: "buy debits the quote currency", want: "<TRNAMT>-500.00</TRNAMT><FITID>FX1</FITID><NAME>BUY USD/BRL</NAME><MEMO>100.00 USD at 5, quotation q1</MEMO><ORIGCURRENCY><CURRATE>5</CURRATE><CURSYM>USD</CURSYM></ORIGCURRENCY>"},
+		{name: "sell credits the quote currency", want: "<TRNTYPE>CREDIT</TRNTYPE><DTPOSTED>20260310120000.000[0:GMT]</DTPOSTED><TRNAMT>55.00</TRNAMT>"},
+		{name: "trade balance sums the trades", want: "<BALAMT>-500.00</BALAMT>"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if !strings.Contains(document, tt.want) {
+				t.Errorf("document lacks %s:\n%s", tt.want, document)
+			}
+		})
+	}
+
+	if n := strings.Count(document, "<STMTTRNRS>"); n != 5 {
+		t.Errorf("%d statements, want one per account and one per currency pair", n)
+	}
+	if n := strings.Count(document, "<STMTTRN>"); n != 8 {
+		t.Errorf("%d transactions, want the two ledger entries, the four trade legs and the two completed trades", n)
+	}
+}
@@ -0,0 +1,33 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+
+	"github.com/leandroalencar/banco-dados/services/s2-processor/internal/domain/repositories"
+	"github.com/leandroalencar/banco-dados/services/s2-processor/internal/dto"
+	"github.com/leandroalencar/banco-dados/shared/utils"
+)
+
+// HistoryRecorder copies trades recorded in PostgreSQL to the Cassandra
+// transaction history. Trades arrive through the outbox, so every committed
+// trade is eventually written; Cassandra upserts make duplicates harmless.
+type HistoryRecorder struct {
+	historyRepo repositories.TransactionHistoryRepository
+}
+
+// NewHistoryRecorder creates a new history recorder
+func NewHistoryRecorder(historyRepo repositories.TransactionHistoryRepository) *HistoryRecorder {
+	return &HistoryRecorder{historyRepo: historyRepo}
+}
+
+// Handle writes one trade message to the history
+func (h *HistoryRecorder) Handle(msg utils.Message) error {
+	var trade dto.Trade
+	if err := json.Unmarshal(msg.Body, &trade); err != nil {
+		log.Printf("Discarding malformed trade: %v", err)
+		return nil
+	}
+	return h.historyRepo.Insert(context.Background(), trade.ToModel())
+}
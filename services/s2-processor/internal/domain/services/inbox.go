@@ -0,0 +1,39 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"log"
+
+	"github.com/leandroalencar/banco-dados/services/s2-processor/internal/domain/repositories"
+	"github.com/leandroalencar/banco-dados/shared/utils"
+)
+
+// MessageHandler handles a consumed message. Its writes made with ctx commit
+// together with the inbox record.
+type MessageHandler func(ctx context.Context, msg utils.Message) error
+
+// ErrMissingMessageID is returned for a message without a broker message ID,
+// which can't be told apart from a redelivery
+var ErrMissingMessageID = errors.New("message has no ID")
+
+// Deduplicate wraps handler so that each message is applied at most once.
+// Messages without a broker message ID are refused with ErrMissingMessageID.
+func Deduplicate(inboxRepo repositories.InboxRepository, handler MessageHandler) func(utils.Message) error {
+	return func(msg utils.Message) error {
+		if msg.ID == "" {
+			return ErrMissingMessageID
+		}
+		ctx := context.Background()
+		processed, err := inboxRepo.ProcessOnce(ctx, msg.Queue, msg.ID, func(ctx context.Context) error {
+			return handler(ctx, msg)
+		})
+		if err != nil {
+			return err
+		}
+		if !processed {
+			log.Printf("Skipping already processed message %s from %s", msg.ID, msg.Queue)
+		}
+		return nil
+	}
+}
@@ -0,0 +1,43 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/leandroalencar/banco-dados/services/s2-processor/internal/domain/repositories/memory"
+	"github.com/leandroalencar/banco-dados/shared/utils"
+)
+
+func TestDeduplicate(t *testing.T) {
+	tests := []struct {
+		name     string
+		ids      []string
+		failures int
+		calls    int
+	}{
+		{name: "distinct messages", ids: []string{"a", "b"}, calls: 2},
+		{name: "redelivered message", ids: []string{"a", "a", "a"}, calls: 1},
+		{name: "retried after failure", ids: []string{"a", "a", "a"}, failures: 1, calls: 2},
+		{name: "missing id", ids: []string{"", ""}, calls: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls := 0
+			handle := Deduplicate(memory.NewInboxRepository(), func(ctx context.Context, msg utils.Message) error {
+				calls++
+				if calls <= tt.failures {
+					return errors.New("failed")
+				}
+				return nil
+			})
+			for _, id := range tt.ids {
+				handle(utils.Message{ID: id, Queue: "test"})
+			}
+			if calls != tt.calls {
+				t.Errorf("handler called %d times, want %d", calls, tt.calls)
+			}
+		})
+	}
+}
@@ -0,0 +1,76 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"time"
+
+	"github.com/leandroalencar/banco-dados/services/s2-processor/internal/domain/models"
+	"github.com/leandroalencar/banco-dados/services/s2-processor/internal/domain/repositories"
+)
+
+// MessagePublisher publishes a message tagged with an ID to a queue,
+// returning nil only once the broker confirmed it
+type MessagePublisher interface {
+	PublishMessageWithID(queue, messageID string, message interface{}) error
+}
+
+// OutboxRelay publishes pending outbox messages to the message broker.
+//
+// A message is marked as sent only after the broker confirmed it, and in the
+// same transaction that holds the row lock. If the relay crashes between the
+// two steps the message is published again on the next run, so delivery is
+// at-least-once and consumers must deduplicate by message ID.
+type OutboxRelay struct {
+	outboxRepo repositories.OutboxRepository
+	publisher  MessagePublisher
+	interval   time.Duration
+	batchSize  int
+}
+
+// NewOutboxRelay creates a new outbox relay
+func NewOutboxRelay(outboxRepo repositories.OutboxRepository, publisher MessagePublisher, interval time.Duration, batchSize int) *OutboxRelay {
+	return &OutboxRelay{
+		outboxRepo: outboxRepo,
+		publisher:  publisher,
+		interval:   interval,
+		batchSize:  batchSize,
+	}
+}
+
+// Run polls the outbox until ctx is cancelled
+func (r *OutboxRelay) Run(ctx context.Context) {
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	for {
+		if err := r.RelayPending(ctx); err != nil {
+			log.Printf("Error relaying outbox messages: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RelayPending publishes one batch of pending messages in insertion order.
+// It stops at the first failure so later events are not sent ahead of it.
+func (r *OutboxRelay) RelayPending(ctx context.Context) error {
+	return r.outboxRepo.ProcessPending(ctx, r.batchSize, func(ctx context.Context, messages []models.OutboxMessage) error {
+		for _, msg := range messages {
+			err := r.publisher.PublishMessageWithID(msg.Queue, msg.MessageID, json.RawMessage(msg.Payload))
+			if err != nil {
+				log.Printf("Error publishing outbox message %s: %v", msg.MessageID, err)
+				return r.outboxRepo.MarkFailed(ctx, msg.ID, err)
+			}
+			if err := r.outboxRepo.MarkSent(ctx, msg.ID); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
@@ -0,0 +1,97 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/leandroalencar/banco-dados/services/s2-processor/internal/domain/repositories/memory"
+)
+
+// flakyPublisher records the queues it published to and fails the first
+// publish to each queue in failures
+type flakyPublisher struct {
+	failures  map[string]bool
+	published []string
+}
+
+func (p *flakyPublisher) PublishMessageWithID(queue, messageID string, message interface{}) error {
+	if p.failures[queue] {
+		delete(p.failures, queue)
+		return errors.New("broker unavailable")
+	}
+	p.published = append(p.published, queue)
+	return nil
+}
+
+func TestOutboxRelayRelayPending(t *testing.T) {
+	tests := []struct {
+		name      string
+		batchSize int
+		failures  map[string]bool
+		published []string
+		pending   []string
+	}{
+		{name: "all confirmed", batchSize: 10, published: []string{"a", "b", "c"}, pending: []string{}},
+		{name: "batch limit", batchSize: 2, published: []string{"a", "b"}, pending: []string{"c"}},
+		{name: "stops at failure", batchSize: 10, failures: map[string]bool{"b": true}, published: []string{"a"}, pending: []string{"b", "c"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			outboxRepo := memory.NewOutboxRepository()
+			for _, queue := range []string{"a", "b", "c"} {
+				if err := outboxRepo.Enqueue(ctx, queue, map[string]string{"queue": queue}); err != nil {
+					t.Fatal(err)
+				}
+			}
+			publisher := &flakyPublisher{failures: tt.failures}
+			relay := NewOutboxRelay(outboxRepo, publisher, time.Second, tt.batchSize)
+
+			if err := relay.RelayPending(ctx); err != nil {
+				t.Fatalf("RelayPending() error = %v", err)
+			}
+			if !reflect.DeepEqual(publisher.published, tt.published) {
+				t.Errorf("published = %v, want %v", publisher.published, tt.published)
+			}
+			pending := []string{}
+			for _, message := range outboxRepo.Pending() {
+				pending = append(pending, message.Queue)
+			}
+			if !reflect.DeepEqual(pending, tt.pending) {
+				t.Errorf("pending = %v, want %v", pending, tt.pending)
+			}
+		})
+	}
+}
+
+func TestOutboxRelayRetriesFailedMessage(t *testing.T) {
+	ctx := context.Background()
+	outboxRepo := memory.NewOutboxRepository()
+	if err := outboxRepo.Enqueue(ctx, "a", "payload"); err != nil {
+		t.Fatal(err)
+	}
+	publisher := &flakyPublisher{failures: map[string]bool{"a": true}}
+	relay := NewOutboxRelay(outboxRepo, publisher, time.Second, 10)
+
+	if err := relay.RelayPending(ctx); err != nil {
+		t.Fatalf("first RelayPending() error = %v", err)
+	}
+	pending := outboxRepo.Pending()
+	if len(pending) != 1 || pending[0].Attempts != 1 || pending[0].LastError != "broker unavailable" {
+		t.Fatalf("after failure pending = %+v, want one message with 1 attempt and the error", pending)
+	}
+
+	if err := relay.RelayPending(ctx); err != nil {
+		t.Fatalf("second RelayPending() error = %v", err)
+	}
+	if pending := outboxRepo.Pending(); len(pending) != 0 {
+		t.Errorf("after retry pending = %+v, want none", pending)
+	}
+	if !reflect.DeepEqual(publisher.published, []string{"a"}) {
+		t.Errorf("published = %v, want [a]", publisher.published)
+	}
+}
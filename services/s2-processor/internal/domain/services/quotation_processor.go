@@ -0,0 +1,132 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/leandroalencar/banco-dados/services/s2-processor/internal/domain/models"
+	"github.com/leandroalencar/banco-dados/services/s2-processor/internal/domain/repositories"
+	"github.com/leandroalencar/banco-dados/services/s2-processor/internal/dto"
+	shared "github.com/leandroalencar/banco-dados/shared/models"
+	"github.com/leandroalencar/banco-dados/shared/utils"
+)
+
+// QuotationUpdatesQueue receives every stored quote with its variation
+const QuotationUpdatesQueue = "quotations.updates"
+
+// QuotationProcessor stores incoming quotations as currency snapshots,
+// enriched with the variation from the previous quote of the same pair
+type QuotationProcessor struct {
+	currencyRepo repositories.CurrencyRepository
+	publisher    MessagePublisher
+}
+
+// NewQuotationProcessor creates a new quotation processor
+func NewQuotationProcessor(currencyRepo repositories.CurrencyRepository, publisher MessagePublisher) *QuotationProcessor {
+	return &QuotationProcessor{
+		currencyRepo: currencyRepo,
+		publisher:    publisher,
+	}
+}
+
+// Handle processes one quotation message. The snapshot is keyed by the
+// message ID, so a redelivered message republishes the stored snapshot
+// instead of inserting it twice. Messages without an ID are refused with
+// ErrMissingMessageID.
+func (p *QuotationProcessor) Handle(msg utils.Message) error {
+	if msg.ID == "" {
+		return ErrMissingMessageID
+	}
+	ctx := context.Background()
+
+	var quotation shared.Quotation
+	if err := json.Unmarshal(msg.Body, &quotation); err != nil {
+		// Malformed messages are never going to succeed; drop them
+		log.Printf("Discarding malformed quotation: %v", err)
+		return nil
+	}
+
+	code, codein, ok := strings.Cut(quotation.CurrencyPair, "/")
+	if !ok {
+		log.Printf("Discarding quotation with invalid pair %q", quotation.CurrencyPair)
+		return nil
+	}
+
+	previous, err := p.currencyRepo.GetLatest(ctx, code, codein)
+	if err != nil {
+		return fmt.Errorf("failed to load previous quote: %w", err)
+	}
+
+	currency := normalize(msg.ID, code, codein, quotation, previous)
+	if err := p.currencyRepo.Insert(ctx, currency); err != nil {
+		if !errors.Is(err, repositories.ErrDuplicateQuote) {
+			return fmt.Errorf("failed to store quote: %w", err)
+		}
+		if currency, err = p.currencyRepo.GetByID(ctx, currency.ID); err != nil {
+			return err
+		}
+	}
+
+	return p.publisher.PublishMessageWithID(QuotationUpdatesQueue, currency.ID, dto.NewQuote(currency))
+}
+
+// normalize converts a quotation into a currency snapshot. Bid is the buy
+// price and ask the sell price; high and low cover the current UTC day.
+func normalize(id, code, codein string, q shared.Quotation, previous *models.Currency) *models.Currency {
+	bid, ask := q.BuyPrice, q.SellPrice
+	high, low := bid, bid
+	varBid, pctChange := 0.0, 0.0
+
+	if previous != nil {
+		prevBid := parsePrice(previous.Bid)
+		varBid = bid - prevBid
+		if prevBid != 0 {
+			pctChange = varBid / prevBid * 100
+		}
+		if sameDay(previous.QuotedAt, q.Timestamp) {
+			if prevHigh := parsePrice(previous.High); prevHigh > high {
+				high = prevHigh
+			}
+			if prevLow := parsePrice(previous.Low); prevLow < low {
+				low = prevLow
+			}
+		}
+	}
+
+	return &models.Currency{
+		ID:         id,
+		Code:       code,
+		Codein:     codein,
+		Name:       q.CurrencyPair,
+		High:       formatPrice(high),
+		Low:        formatPrice(low),
+		VarBid:     formatPrice(varBid),
+		PctChange:  strconv.FormatFloat(pctChange, 'f', 2, 64),
+		Bid:        formatPrice(bid),
+		Ask:        formatPrice(ask),
+		Timestamp:  strconv.FormatInt(q.Timestamp.Unix(), 10),
+		CreateDate: q.Timestamp.Format("2006-01-02 15:04:05"),
+		QuotedAt:   q.Timestamp,
+	}
+}
+
+func sameDay(a, b time.Time) bool {
+	ay, am, ad := a.UTC().Date()
+	by, bm, bd := b.UTC().Date()
+	return ay == by && am == bm && ad == bd
+}
+
+func parsePrice(s string) float64 {
+	v, _ := strconv.ParseFloat(s, 64)
+	return v
+}
+
+func formatPrice(v float64) string {
+	return strconv.FormatFloat(v, 'f', 4, 64)
+}
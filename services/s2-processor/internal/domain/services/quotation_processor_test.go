@@ -0,0 +1,63 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/leandroalencar/banco-dados/services/s2-processor/internal/domain/repositories/memory"
+	shared "github.com/leandroalencar/banco-dados/shared/models"
+	"github.com/leandroalencar/banco-dados/shared/utils"
+)
+
+// recordingPublisher keeps the IDs of the messages published to it
+type recordingPublisher struct {
+	ids []string
+}
+
+func (p *recordingPublisher) PublishMessageWithID(queue, messageID string, message interface{}) error {
+	p.ids = append(p.ids, messageID)
+	return nil
+}
+
+func TestQuotationProcessorHandle(t *testing.T) {
+	tests := []struct {
+		name      string
+		ids       []string
+		stored    []string
+		published []string
+	}{
+		{name: "distinct quotations", ids: []string{"q1", "q2"}, stored: []string{"q1", "q2"}, published: []string{"q1", "q2"}},
+		{name: "redelivered quotation", ids: []string{"q1", "q1"}, stored: []string{"q1"}, published: []string{"q1", "q1"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			currencyRepo := memory.NewCurrencyRepository()
+			publisher := &recordingPublisher{}
+			processor := NewQuotationProcessor(currencyRepo, publisher)
+
+			body, _ := json.Marshal(shared.Quotation{CurrencyPair: "USD/BRL", BuyPrice: 5, SellPrice: 5.1, Timestamp: time.Now()})
+			for _, id := range tt.ids {
+				if err := processor.Handle(utils.Message{ID: id, Body: body}); err != nil {
+					t.Fatalf("Handle(%s) error = %v", id, err)
+				}
+			}
+
+			for _, id := range tt.stored {
+				if _, err := currencyRepo.GetByID(context.Background(), id); err != nil {
+					t.Errorf("quote %s not stored: %v", id, err)
+				}
+			}
+			if len(publisher.ids) != len(tt.published) {
+				t.Fatalf("published %v, want %v", publisher.ids, tt.published)
+			}
+			for i, id := range tt.published {
+				if publisher.ids[i] != id {
+					t.Errorf("published %v, want %v", publisher.ids, tt.published)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,161 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/leandroalencar/banco-dados/services/s2-processor/internal/domain/models"
+	"github.com/leandroalencar/banco-dados/services/s2-processor/internal/domain/repositories"
+)
+
+// ErrInvalidSchedule is returned for a schedule that can't be materialized
+var ErrInvalidSchedule = errors.New("invalid schedule")
+
+// ScheduleService manages the recurring transactions and trades of users.
+// The Scheduler materializes them.
+type ScheduleService struct {
+	scheduleRepo repositories.ScheduleRepository
+	accountRepo  repositories.AccountRepository
+	categoryRepo repositories.CategoryRepository
+}
+
+// NewScheduleService creates a new schedule service
+func NewScheduleService(scheduleRepo repositories.ScheduleRepository, accountRepo repositories.AccountRepository, categoryRepo repositories.CategoryRepository) *ScheduleService {
+	return &ScheduleService{
+		scheduleRepo: scheduleRepo,
+		accountRepo:  accountRepo,
+		categoryRepo: categoryRepo,
+	}
+}
+
+// Create adds a schedule for userID. It first runs at the start of its
+// recurrence, which defaults to now; a start in the past makes the
+// scheduler catch up on the occurrences since then.
+func (s *ScheduleService) Create(ctx context.Context, userID uint, schedule *models.Schedule) error {
+	schedule.UserID = userID
+	schedule.Occurrences = 0
+	schedule.LastError = ""
+	if schedule.Recurrence.StartAt.IsZero() {
+		schedule.Recurrence.StartAt = time.Now()
+	}
+	if schedule.Recurrence.Interval == 0 {
+		schedule.Recurrence.Interval = 1
+	}
+	if err := validateRecurrence(schedule.Recurrence); err != nil {
+		return err
+	}
+	if schedule.Amount <= 0 {
+		return ErrInvalidAmount
+	}
+
+	switch schedule.Kind {
+	case models.ScheduledTransaction:
+		if err := s.validateTransaction(ctx, schedule); err != nil {
+			return err
+		}
+	case models.ScheduledTrade:
+		if err := validateTrade(schedule); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("%w: unknown kind %q", ErrInvalidSchedule, schedule.Kind)
+	}
+
+	first := schedule.Recurrence.Occurrence(0)
+	schedule.NextRunAt = &first
+	return s.scheduleRepo.Create(ctx, schedule)
+}
+
+// List returns the schedules of userID
+func (s *ScheduleService) List(ctx context.Context, userID uint) ([]models.Schedule, error) {
+	return s.scheduleRepo.GetAllByUserID(ctx, userID)
+}
+
+// Get returns a schedule of userID
+func (s *ScheduleService) Get(ctx context.Context, userID, id uint) (*models.Schedule, error) {
+	schedule, err := s.scheduleRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if schedule.UserID != userID {
+		return nil, repositories.ErrScheduleNotFound
+	}
+	return schedule, nil
+}
+
+// Delete removes a schedule of userID. What it already materialized stays.
+func (s *ScheduleService) Delete(ctx context.Context, userID, id uint) error {
+	if _, err := s.Get(ctx, userID, id); err != nil {
+		return err
+	}
+	return s.scheduleRepo.Delete(ctx, id)
+}
+
+// validateTransaction checks a scheduled transaction against the accounts
+// and categories its user may use
+func (s *ScheduleService) validateTransaction(ctx context.Context, schedule *models.Schedule) error {
+	if schedule.TransactionType != models.Income && schedule.TransactionType != models.Expense {
+		return fmt.Errorf("%w: transaction type must be income or expense", ErrInvalidSchedule)
+	}
+	schedule.TradeType = ""
+	schedule.CurrencyPair = ""
+
+	account, err := s.accountRepo.GetByID(ctx, schedule.AccountID)
+	if err != nil {
+		return err
+	}
+	if account.UserID != schedule.UserID {
+		return repositories.ErrAccountNotFound
+	}
+
+	if schedule.CategoryID == 0 {
+		return nil
+	}
+	category, err := s.categoryRepo.GetByID(ctx, schedule.CategoryID)
+	if err != nil {
+		return err
+	}
+	if !category.VisibleTo(schedule.UserID) {
+		return repositories.ErrCategoryNotFound
+	}
+	return nil
+}
+
+// validateTrade checks a scheduled currency order
+func validateTrade(schedule *models.Schedule) error {
+	if schedule.TradeType != models.TradeBuy && schedule.TradeType != models.TradeSell {
+		return fmt.Errorf("%w: trade type must be BUY or SELL", ErrInvalidSchedule)
+	}
+	schedule.CurrencyPair = strings.ToUpper(schedule.CurrencyPair)
+	code, codein, ok := strings.Cut(schedule.CurrencyPair, "/")
+	if !ok || !currencyCode.MatchString(code) || !currencyCode.MatchString(codein) {
+		return ErrInvalidCurrency
+	}
+	schedule.AccountID = 0
+	schedule.CategoryID = 0
+	schedule.TransactionType = ""
+	schedule.Description = ""
+	return nil
+}
+
+// validateRecurrence checks that a recurrence has at least one occurrence
+func validateRecurrence(r models.Recurrence) error {
+	switch r.Frequency {
+	case models.Daily, models.Weekly, models.Monthly:
+	default:
+		return fmt.Errorf("%w: unknown frequency %q", ErrInvalidSchedule, r.Frequency)
+	}
+	if r.Interval < 1 {
+		return fmt.Errorf("%w: interval must be positive", ErrInvalidSchedule)
+	}
+	if r.Count < 0 {
+		return fmt.Errorf("%w: count must not be negative", ErrInvalidSchedule)
+	}
+	if !r.Includes(0, r.Occurrence(0)) {
+		return fmt.Errorf("%w: until is before the start", ErrInvalidSchedule)
+	}
+	return nil
+}
@@ -0,0 +1,168 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/leandroalencar/banco-dados/services/s2-processor/internal/domain/models"
+	"github.com/leandroalencar/banco-dados/services/s2-processor/internal/domain/repositories"
+	"github.com/leandroalencar/banco-dados/services/s2-processor/internal/dto"
+)
+
+const (
+	// TransactionRequestsQueue receives currency orders, which s3 validates
+	// before they reach the transaction processor
+	TransactionRequestsQueue = "transactions"
+
+	// maxCatchUp bounds how many missed occurrences of one schedule are
+	// materialized per batch, so a long outage doesn't hold its lock for
+	// long. The rest are picked up by the following batches.
+	maxCatchUp = 100
+)
+
+// Scheduler materializes due occurrences of schedules: scheduled
+// transactions are posted to their account and scheduled trades are sent
+// as orders to the transactions queue.
+//
+// Each occurrence is materialized in the same database transaction that
+// advances its schedule, so it happens exactly once even with several
+// schedulers running. Occurrences missed while no scheduler ran are
+// materialized when one starts again, dated when they were due.
+//
+// An occurrence that fails is rolled back on its own and recorded as the
+// schedule's last error. Failures that retrying can't fix, such as a
+// closed account or a deleted category, end the schedule; others leave the
+// occurrence due for the next batch. Either way the other schedules of the
+// batch still run.
+type Scheduler struct {
+	uow             repositories.UnitOfWork
+	scheduleRepo    repositories.ScheduleRepository
+	transactionRepo repositories.TransactionRepository
+	outboxRepo      repositories.OutboxRepository
+	categorizer     *CategorizationService
+	budgets         *BudgetService
+	interval        time.Duration
+	batchSize       int
+}
+
+// NewScheduler creates a new scheduler
+func NewScheduler(uow repositories.UnitOfWork, scheduleRepo repositories.ScheduleRepository, transactionRepo repositories.TransactionRepository, outboxRepo repositories.OutboxRepository, categorizer *CategorizationService, budgets *BudgetService, interval time.Duration, batchSize int) *Scheduler {
+	return &Scheduler{
+		uow:             uow,
+		scheduleRepo:    scheduleRepo,
+		transactionRepo: transactionRepo,
+		outboxRepo:      outboxRepo,
+		categorizer:     categorizer,
+		budgets:         budgets,
+		interval:        interval,
+		batchSize:       batchSize,
+	}
+}
+
+// Run materializes due occurrences until ctx is cancelled
+func (s *Scheduler) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		if err := s.RunDue(ctx); err != nil {
+			log.Printf("Error running schedules: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunDue materializes the occurrences due now of one batch of schedules.
+// Budgets covering the posted transactions are checked in the same
+// database transaction, so their alerts are queued exactly when the
+// transactions are committed.
+func (s *Scheduler) RunDue(ctx context.Context) error {
+	now := time.Now()
+	return s.scheduleRepo.ProcessDue(ctx, now, s.batchSize, func(ctx context.Context, schedule *models.Schedule) error {
+		for n := 0; n < maxCatchUp && schedule.NextRunAt != nil && !schedule.NextRunAt.After(now); n++ {
+			err := s.uow.Do(ctx, func(ctx context.Context) error {
+				return s.materialize(ctx, schedule)
+			})
+			if err == nil {
+				continue
+			}
+			if ctx.Err() != nil {
+				return err
+			}
+			if permanent(err) {
+				log.Printf("Ending schedule %d: %v", schedule.ID, err)
+				schedule.End(err.Error())
+				return nil
+			}
+			log.Printf("Error running schedule %d, retrying in the next batch: %v", schedule.ID, err)
+			schedule.LastError = err.Error()
+			return nil
+		}
+		return nil
+	})
+}
+
+// permanent reports whether err fails every later attempt at an
+// occurrence too, because what the schedule refers to is gone or invalid
+func permanent(err error) bool {
+	return errors.Is(err, repositories.ErrAccountNotFound) ||
+		errors.Is(err, repositories.ErrCategoryNotFound) ||
+		errors.Is(err, repositories.ErrNotPostable)
+}
+
+// materialize creates the occurrence of schedule at NextRunAt and advances
+// the schedule. It leaves the schedule unchanged when it fails.
+func (s *Scheduler) materialize(ctx context.Context, schedule *models.Schedule) error {
+	at := *schedule.NextRunAt
+
+	switch schedule.Kind {
+	case models.ScheduledTransaction:
+		transaction := &models.Transaction{
+			AccountID:   schedule.AccountID,
+			CategoryID:  schedule.CategoryID,
+			Amount:      schedule.Amount,
+			Type:        schedule.TransactionType,
+			Description: schedule.Description,
+			Date:        at,
+		}
+		if transaction.CategoryID == 0 {
+			categoryID, err := s.categorizer.Categorize(ctx, schedule.UserID, transaction)
+			if err != nil {
+				return err
+			}
+			transaction.CategoryID = categoryID
+		}
+		if err := s.transactionRepo.Post(ctx, transaction); err != nil {
+			return err
+		}
+		if err := s.budgets.Track(ctx, transaction); err != nil {
+			return err
+		}
+
+	case models.ScheduledTrade:
+		order := dto.TradeToMessage(&models.Trade{
+			UserID:       schedule.UserID,
+			Type:         schedule.TradeType,
+			CurrencyPair: schedule.CurrencyPair,
+			Amount:       schedule.Amount,
+			RequestedAt:  at,
+		})
+		if err := s.outboxRepo.Enqueue(ctx, TransactionRequestsQueue, order); err != nil {
+			return err
+		}
+
+	default:
+		schedule.End("unknown kind " + string(schedule.Kind))
+		return nil
+	}
+
+	schedule.Advance()
+	return nil
+}
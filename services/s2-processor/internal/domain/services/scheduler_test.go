@@ -0,0 +1,71 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/leandroalencar/banco-dados/services/s2-processor/internal/domain/models"
+	"github.com/leandroalencar/banco-dados/services/s2-processor/internal/domain/repositories/memory"
+)
+
+func TestSchedulerRunDue(t *testing.T) {
+	ctx := context.Background()
+	accountRepo := memory.NewAccountRepository()
+	categoryRepo := memory.NewCategoryRepository()
+	transactionRepo := memory.NewTransactionRepository(accountRepo)
+	outboxRepo := memory.NewOutboxRepository()
+	scheduleRepo := memory.NewScheduleRepository()
+	uow := memory.NewUnitOfWork()
+	budgets := NewBudgetService(memory.NewBudgetRepository(), accountRepo, categoryRepo, transactionRepo, outboxRepo, uow)
+	categorizer := NewCategorizationService(memory.NewCategorizationRuleRepository(), categoryRepo, accountRepo, transactionRepo, budgets, uow)
+	scheduler := NewScheduler(uow, scheduleRepo, transactionRepo, outboxRepo, categorizer, budgets, time.Minute, 10)
+
+	account := &models.Account{UserID: 1, Name: "Checking", Type: models.Checking}
+	if err := accountRepo.Create(ctx, account); err != nil {
+		t.Fatal(err)
+	}
+
+	// Both schedules have two occurrences due; the first one's account
+	// doesn't exist, which must not keep the second one from running
+	start := time.Now().Add(-36 * time.Hour)
+	closed := &models.Schedule{UserID: 1, AccountID: account.ID + 1}
+	open := &models.Schedule{UserID: 1, AccountID: account.ID}
+	for _, schedule := range []*models.Schedule{closed, open} {
+		schedule.Kind = models.ScheduledTransaction
+		schedule.Recurrence = models.Recurrence{Frequency: models.Daily, Interval: 1, StartAt: start}
+		schedule.Amount = 10
+		schedule.TransactionType = models.Income
+		schedule.NextRunAt = &start
+		if err := scheduleRepo.Create(ctx, schedule); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := scheduler.RunDue(ctx); err != nil {
+		t.Fatalf("RunDue() error = %v", err)
+	}
+
+	got, err := scheduleRepo.GetByID(ctx, closed.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.NextRunAt != nil || got.Occurrences != 0 || got.LastError != "account not found" {
+		t.Errorf("schedule on a missing account: next run %v, %d occurrences, last error %q; want ended with no occurrences and the error", got.NextRunAt, got.Occurrences, got.LastError)
+	}
+
+	got, err = scheduleRepo.GetByID(ctx, open.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Occurrences != 2 || got.NextRunAt == nil || got.LastError != "" {
+		t.Errorf("schedule on an open account: next run %v, %d occurrences, last error %q; want 2 occurrences and a next run", got.NextRunAt, got.Occurrences, got.LastError)
+	}
+	transactions, err := transactionRepo.GetAllByAccountID(ctx, account.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(transactions) != 2 {
+		t.Errorf("%d transactions posted, want 2", len(transactions))
+	}
+}
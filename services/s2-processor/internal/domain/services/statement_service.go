@@ -0,0 +1,134 @@
+package services
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/leandroalencar/banco-dados/services/s2-processor/internal/domain/models"
+	"github.com/leandroalencar/banco-dados/services/s2-processor/internal/domain/repositories"
+)
+
+// StatementService generates account statements
+type StatementService struct {
+	accountRepo     repositories.AccountRepository
+	transactionRepo repositories.TransactionRepository
+	transferRepo    repositories.TransferRepository
+	tradeRepo       repositories.TradeRepository
+}
+
+// NewStatementService creates a new statement service
+func NewStatementService(accountRepo repositories.AccountRepository, transactionRepo repositories.TransactionRepository, transferRepo repositories.TransferRepository, tradeRepo repositories.TradeRepository) *StatementService {
+	return &StatementService{
+		accountRepo:     accountRepo,
+		transactionRepo: transactionRepo,
+		transferRepo:    transferRepo,
+		tradeRepo:       tradeRepo,
+	}
+}
+
+// Monthly returns the statement of an account of userID for the month
+// containing month, in UTC
+func (s *StatementService) Monthly(ctx context.Context, userID, accountID uint, month time.Time) (*models.Statement, error) {
+	from := models.MonthOf(month)
+	return s.Generate(ctx, userID, accountID, from, from.AddDate(0, 1, 0))
+}
+
+// Generate returns the statement of an account of userID for transactions
+// dated in [from, to). The opening balance is worked back from the current
+// balance, so transactions dated after the period don't affect it.
+func (s *StatementService) Generate(ctx context.Context, userID, accountID uint, from, to time.Time) (*models.Statement, error) {
+	account, err := s.accountRepo.GetByID(ctx, accountID)
+	if err != nil {
+		return nil, err
+	}
+	if account.UserID != userID {
+		return nil, repositories.ErrAccountNotFound
+	}
+
+	transactions, err := s.transactionRepo.GetAllByAccountID(ctx, accountID)
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(transactions, func(i, j int) bool {
+		if !transactions[i].Date.Equal(transactions[j].Date) {
+			return transactions[i].Date.Before(transactions[j].Date)
+		}
+		return transactions[i].ID < transactions[j].ID
+	})
+
+	statement := &models.Statement{Account: *account, From: from, To: to}
+	opening := account.Balance
+	var inPeriod []models.Transaction
+	var transferIDs, tradeIDs []uint
+	for _, t := range transactions {
+		if !t.Date.Before(from) {
+			opening -= t.BalanceDelta()
+		}
+		if !t.Date.Before(from) && t.Date.Before(to) {
+			inPeriod = append(inPeriod, t)
+			if t.TransferID != 0 {
+				transferIDs = append(transferIDs, t.TransferID)
+			}
+			if t.TradeID != 0 {
+				tradeIDs = append(tradeIDs, t.TradeID)
+			}
+		}
+	}
+
+	transfers, err := s.transferRepo.GetByIDs(ctx, transferIDs)
+	if err != nil {
+		return nil, err
+	}
+	transfersByID := make(map[uint]*models.AccountTransfer, len(transfers))
+	for i := range transfers {
+		transfersByID[transfers[i].ID] = &transfers[i]
+	}
+	trades, err := s.tradeRepo.GetByIDs(ctx, tradeIDs)
+	if err != nil {
+		return nil, err
+	}
+	tradesByID := make(map[uint]*models.Trade, len(trades))
+	for i := range trades {
+		tradesByID[trades[i].ID] = &trades[i]
+	}
+
+	statement.OpeningBalance = roundCents(opening)
+	balance := opening
+	statement.Lines = make([]models.StatementLine, len(inPeriod))
+	for i, t := range inPeriod {
+		balance += t.BalanceDelta()
+		statement.Lines[i] = models.StatementLine{
+			Transaction: t,
+			Balance:     roundCents(balance),
+			Transfer:    transfersByID[t.TransferID],
+			Trade:       tradesByID[t.TradeID],
+		}
+
+		totals := &statement.Totals
+		switch {
+		case t.Type == models.Income:
+			totals.Income += t.Amount
+		case t.Type == models.Expense:
+			totals.Expense += t.Amount
+		case t.Type == models.Exchange && t.Amount >= 0:
+			totals.TradesIn += t.Amount
+		case t.Type == models.Exchange:
+			totals.TradesOut -= t.Amount
+		case t.Amount >= 0:
+			totals.TransfersIn += t.Amount
+		default:
+			totals.TransfersOut -= t.Amount
+		}
+	}
+	statement.ClosingBalance = roundCents(balance)
+	statement.Totals = models.StatementTotals{
+		Income:       roundCents(statement.Totals.Income),
+		Expense:      roundCents(statement.Totals.Expense),
+		TransfersIn:  roundCents(statement.Totals.TransfersIn),
+		TransfersOut: roundCents(statement.Totals.TransfersOut),
+		TradesIn:     roundCents(statement.Totals.TradesIn),
+		TradesOut:    roundCents(statement.Totals.TradesOut),
+	}
+	return statement, nil
+}
@@ -0,0 +1,154 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/leandroalencar/banco-dados/services/s2-processor/internal/domain/models"
+	"github.com/leandroalencar/banco-dados/services/s2-processor/internal/domain/repositories"
+	"github.com/leandroalencar/banco-dados/services/s2-processor/internal/domain/repositories/memory"
+)
+
+func TestStatementServiceMonthly(t *testing.T) {
+	ctx := context.Background()
+	accountRepo := memory.NewAccountRepository()
+	transactionRepo := memory.NewTransactionRepository(accountRepo)
+	transferRepo := memory.NewTransferRepository(accountRepo, transactionRepo)
+	tradeRepo := memory.NewTradeRepository(accountRepo, transactionRepo)
+	statements := NewStatementService(accountRepo, transactionRepo, transferRepo, tradeRepo)
+
+	checking := &models.Account{UserID: 1, Name: "Checking", Type: models.Checking}
+	savings := &models.Account{UserID: 1, Name: "Savings", Type: models.Savings}
+	dollars := &models.Account{UserID: 1, Name: "Dollars", Type: models.Checking, Currency: "USD"}
+	for _, account := range []*models.Account{checking, savings, dollars} {
+		if err := accountRepo.Create(ctx, account); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Transfers are dated when they are made, so the months are relative to
+	// the current one
+	current := models.MonthOf(time.Now())
+	previous := current.AddDate(0, -1, 0)
+	next := current.AddDate(0, 1, 0)
+	for _, transaction := range []*models.Transaction{
+		{Type: models.Income, Amount: 1000, Date: previous.AddDate(0, 0, 1)},
+		{Type: models.Expense, Amount: 200, Date: previous.AddDate(0, 0, 10)},
+		{Type: models.Expense, Amount: 50.5, Date: current},
+		{Type: models.Income, Amount: 20, Date: next.AddDate(0, 0, 1)},
+	} {
+		transaction.AccountID = checking.ID
+		if err := transactionRepo.Post(ctx, transaction); err != nil {
+			t.Fatal(err)
+		}
+	}
+	transfer := &models.AccountTransfer{FromAccountID: checking.ID, ToAccountID: savings.ID, Amount: 300, ConvertedAmount: 300}
+	if err := transferRepo.Create(ctx, transfer); err != nil {
+		t.Fatal(err)
+	}
+	trade := &models.Trade{
+		CorrelationID:  "buy-usd",
+		UserID:         1,
+		Type:           models.TradeBuy,
+		CurrencyPair:   "USD-BRL",
+		Amount:         20,
+		ExchangeRate:   5,
+		TotalValue:     100,
+		QuotationID:    "quote-1",
+		Status:         models.TradeCompleted,
+		BaseAccountID:  dollars.ID,
+		QuoteAccountID: checking.ID,
+		RequestedAt:    time.Now(),
+	}
+	if err := tradeRepo.Create(ctx, trade); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name      string
+		month     time.Time
+		opening   float64
+		balances  []float64
+		closing   float64
+		totals    models.StatementTotals
+		transfers int
+		trades    int
+	}{
+		{
+			name:  "before the first transaction",
+			month: previous.AddDate(0, -1, 0),
+		},
+		{
+			name:     "previous month",
+			month:    previous,
+			balances: []float64{1000, 800},
+			closing:  800,
+			totals:   models.StatementTotals{Income: 1000, Expense: 200},
+		},
+		{
+			name:      "current month",
+			month:     current,
+			opening:   800,
+			balances:  []float64{749.5, 449.5, 349.5},
+			closing:   349.5,
+			totals:    models.StatementTotals{Expense: 50.5, TransfersOut: 300, TradesOut: 100},
+			transfers: 1,
+			trades:    1,
+		},
+		{
+			name:     "transactions dated ahead",
+			month:    next,
+			opening:  349.5,
+			balances: []float64{369.5},
+			closing:  369.5,
+			totals:   models.StatementTotals{Income: 20},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			statement, err := statements.Monthly(ctx, 1, checking.ID, tt.month)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if statement.OpeningBalance != tt.opening || statement.ClosingBalance != tt.closing {
+				t.Errorf("balances %v to %v, want %v to %v", statement.OpeningBalance, statement.ClosingBalance, tt.opening, tt.closing)
+			}
+			var balances []float64
+			transfers, trades := 0, 0
+			for _, line := range statement.Lines {
+				balances = append(balances, line.Balance)
+				if line.Transfer != nil {
+					transfers++
+				}
+				if line.Trade != nil {
+					trades++
+					if line.Trade.ExchangeRate != 5 || line.Trade.QuotationID != "quote-1" {
+						t.Errorf("trade line priced at %v by %q, want 5 by %q", line.Trade.ExchangeRate, line.Trade.QuotationID, "quote-1")
+					}
+				}
+			}
+			if !reflect.DeepEqual(balances, tt.balances) {
+				t.Errorf("line balances = %v, want %v", balances, tt.balances)
+			}
+			if statement.Totals != tt.totals {
+				t.Errorf("totals = %+v, want %+v", statement.Totals, tt.totals)
+			}
+			if transfers != tt.transfers {
+				t.Errorf("%d lines carry their transfer, want %d", transfers, tt.transfers)
+			}
+			if trades != tt.trades {
+				t.Errorf("%d lines carry their trade, want %d", trades, tt.trades)
+			}
+		})
+	}
+
+	t.Run("other user's account", func(t *testing.T) {
+		if _, err := statements.Monthly(ctx, 2, checking.ID, current); !errors.Is(err, repositories.ErrAccountNotFound) {
+			t.Errorf("err = %v, want %v", err, repositories.ErrAccountNotFound)
+		}
+	})
+}
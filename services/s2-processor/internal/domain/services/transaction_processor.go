@@ -0,0 +1,203 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/leandroalencar/banco-dados/services/s2-processor/internal/domain/models"
+	"github.com/leandroalencar/banco-dados/services/s2-processor/internal/domain/repositories"
+	"github.com/leandroalencar/banco-dados/services/s2-processor/internal/dto"
+	shared "github.com/leandroalencar/banco-dados/shared/models"
+	"github.com/leandroalencar/banco-dados/shared/utils"
+)
+
+const (
+	// TransactionResultsQueue receives the outcome of every request
+	TransactionResultsQueue = "transactions.results"
+
+	// TransactionHistoryQueue feeds recorded trades to the history store
+	TransactionHistoryQueue = "transactions.history"
+
+	// maxQuoteAge is how old the latest quote may be to price a trade
+	maxQuoteAge = time.Minute
+)
+
+// TransactionProcessor executes buy and sell requests against the user in
+// PostgreSQL and the latest quote in MongoDB, records the outcome and
+// replies with a result event. A completed trade is posted to the user's
+// oldest account in each currency of the pair.
+type TransactionProcessor struct {
+	userRepo     repositories.UserRepository
+	accountRepo  repositories.AccountRepository
+	currencyRepo repositories.CurrencyRepository
+	tradeRepo    repositories.TradeRepository
+	outboxRepo   repositories.OutboxRepository
+}
+
+// NewTransactionProcessor creates a new transaction processor
+func NewTransactionProcessor(userRepo repositories.UserRepository, accountRepo repositories.AccountRepository, currencyRepo repositories.CurrencyRepository, tradeRepo repositories.TradeRepository, outboxRepo repositories.OutboxRepository) *TransactionProcessor {
+	return &TransactionProcessor{
+		userRepo:     userRepo,
+		accountRepo:  accountRepo,
+		currencyRepo: currencyRepo,
+		tradeRepo:    tradeRepo,
+		outboxRepo:   outboxRepo,
+	}
+}
+
+// Handle processes one transaction request. It is meant to be wrapped with
+// Deduplicate: the trade and its result event are written with ctx, so they
+// commit together with the inbox record.
+func (p *TransactionProcessor) Handle(ctx context.Context, msg utils.Message) error {
+	var request shared.Transaction
+	if err := json.Unmarshal(msg.Body, &request); err != nil {
+		// Malformed messages are never going to succeed; drop them
+		log.Printf("Discarding malformed transaction: %v", err)
+		return nil
+	}
+
+	trade, idErr := dto.TradeFromMessage(request)
+	trade.CorrelationID = msg.ID
+	if trade.RequestedAt.IsZero() {
+		trade.RequestedAt = time.Now()
+	}
+
+	reason, err := p.execute(ctx, request, trade, idErr)
+	if err != nil {
+		return err
+	}
+	if reason == "" {
+		// The balance is checked again while the accounts are locked
+		trade.Status = models.TradeCompleted
+		err := p.tradeRepo.Create(ctx, trade)
+		if errors.Is(err, repositories.ErrInsufficientFunds) {
+			reason = insufficient(trade)
+		} else if err != nil {
+			return err
+		}
+	}
+	if reason != "" {
+		trade.Status = models.TradeRejected
+		trade.Reason = reason
+		trade.BaseAccountID, trade.QuoteAccountID = 0, 0
+		if err := p.tradeRepo.Create(ctx, trade); err != nil {
+			return err
+		}
+	}
+	if err := p.outboxRepo.Enqueue(ctx, TransactionHistoryQueue, dto.NewTrade(trade)); err != nil {
+		return err
+	}
+
+	result := shared.TransactionResult{
+		Event:         shared.TransactionCompleted,
+		CorrelationID: trade.CorrelationID,
+		Transaction:   dto.TradeToMessage(trade),
+		Reason:        trade.Reason,
+		ProcessedAt:   time.Now(),
+	}
+	if trade.Status == models.TradeRejected {
+		result.Event = shared.TransactionRejected
+	}
+	// Echo the requested user ID, even if unparseable, so the requester can
+	// match the result
+	result.Transaction.UserID = request.UserID
+
+	return p.outboxRepo.Enqueue(ctx, TransactionResultsQueue, result)
+}
+
+// execute validates the request and prices the trade. idErr is the error
+// from mapping the request's user ID: a user ID that isn't ours is the
+// external ID of a user created by another service. It returns the reason for rejecting
+// the request, or an error if it could not be processed.
+func (p *TransactionProcessor) execute(ctx context.Context, request shared.Transaction, trade *models.Trade, idErr error) (string, error) {
+	if trade.Type != models.TradeBuy && trade.Type != models.TradeSell {
+		return "unknown transaction type " + string(request.Type), nil
+	}
+	if request.Amount <= 0 {
+		return "amount must be positive", nil
+	}
+
+	var user *models.User
+	var err error
+	if idErr == nil {
+		user, err = p.userRepo.GetByID(ctx, trade.UserID)
+	} else {
+		user, err = p.userRepo.GetByExternalID(ctx, request.UserID)
+	}
+	if err != nil {
+		if errors.Is(err, repositories.ErrUserNotFound) {
+			return "user not found", nil
+		}
+		return "", err
+	}
+	trade.UserID = user.ID
+
+	code, codein, ok := strings.Cut(request.CurrencyPair, "/")
+	if !ok || code == codein {
+		return "invalid currency pair " + request.CurrencyPair, nil
+	}
+	quote, err := p.currencyRepo.GetLatest(ctx, code, codein)
+	if err != nil {
+		return "", err
+	}
+	if quote == nil {
+		return "no quotation available for " + request.CurrencyPair, nil
+	}
+	if age := time.Since(quote.QuotedAt); age > maxQuoteAge {
+		return "latest quotation is stale", nil
+	}
+
+	// The user buys at the ask price and sells at the bid price
+	rate := parsePrice(quote.Ask)
+	if trade.Type == models.TradeSell {
+		rate = parsePrice(quote.Bid)
+	}
+	trade.ExchangeRate = rate
+	trade.TotalValue = math.Round(request.Amount*rate*100) / 100
+	trade.QuotationID = quote.ID
+
+	accounts, err := p.accountRepo.GetAllByUserID(ctx, trade.UserID)
+	if err != nil {
+		return "", err
+	}
+	trade.BaseAccountID = oldestAccount(accounts, code)
+	trade.QuoteAccountID = oldestAccount(accounts, codein)
+	debit, credit := trade.Legs()
+	switch {
+	case debit.AccountID == 0:
+		return insufficient(trade), nil
+	case credit.AccountID == 0 && trade.Type == models.TradeBuy:
+		return "no " + code + " account to credit", nil
+	case credit.AccountID == 0:
+		return "no " + codein + " account to credit", nil
+	}
+
+	return "", nil
+}
+
+// insufficient returns the reason for rejecting a trade whose debited
+// account can't pay for it
+func insufficient(trade *models.Trade) string {
+	code, codein, _ := strings.Cut(trade.CurrencyPair, "/")
+	if trade.Type == models.TradeSell {
+		return "insufficient " + code + " holdings"
+	}
+	return "insufficient " + codein + " funds"
+}
+
+// oldestAccount returns the ID of the first opened account in currency, or
+// zero if there is none
+func oldestAccount(accounts []models.Account, currency string) uint {
+	var id uint
+	for _, account := range accounts {
+		if account.Currency == currency && (id == 0 || account.ID < id) {
+			id = account.ID
+		}
+	}
+	return id
+}
@@ -0,0 +1,209 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/leandroalencar/banco-dados/services/s2-processor/internal/domain/models"
+	"github.com/leandroalencar/banco-dados/services/s2-processor/internal/domain/repositories/memory"
+	shared "github.com/leandroalencar/banco-dados/shared/models"
+	"github.com/leandroalencar/banco-dados/shared/utils"
+)
+
+func TestTransactionProcessorHandle(t *testing.T) {
+	tests := []struct {
+		name     string
+		request  shared.Transaction
+		accounts map[string]float64 // balance of each of the user's accounts by currency
+		quotedAt time.Duration
+		event    shared.TransactionEvent
+		reason   string
+		total    float64
+		balances map[string]float64 // balances after the request
+	}{
+		{
+			name:     "buy at the ask price",
+			request:  shared.Transaction{UserID: "1", Type: shared.Buy, CurrencyPair: "USD/BRL", Amount: 10},
+			accounts: map[string]float64{"BRL": 100, "USD": 0},
+			event:    shared.TransactionCompleted,
+			total:    51,
+			balances: map[string]float64{"BRL": 49, "USD": 10},
+		},
+		{
+			name:     "buy with exactly enough funds",
+			request:  shared.Transaction{UserID: "1", Type: shared.Buy, CurrencyPair: "USD/BRL", Amount: 10},
+			accounts: map[string]float64{"BRL": 51, "USD": 0},
+			event:    shared.TransactionCompleted,
+			total:    51,
+			balances: map[string]float64{"BRL": 0, "USD": 10},
+		},
+		{
+			name:     "buy without enough funds",
+			request:  shared.Transaction{UserID: "1", Type: shared.Buy, CurrencyPair: "USD/BRL", Amount: 10},
+			accounts: map[string]float64{"BRL": 50, "USD": 0},
+			event:    shared.TransactionRejected,
+			reason:   "insufficient BRL funds",
+			total:    51,
+			balances: map[string]float64{"BRL": 50, "USD": 0},
+		},
+		{
+			name:     "buy without a quote currency account",
+			request:  shared.Transaction{UserID: "1", Type: shared.Buy, CurrencyPair: "USD/BRL", Amount: 10},
+			accounts: map[string]float64{"USD": 0},
+			event:    shared.TransactionRejected,
+			reason:   "insufficient BRL funds",
+			total:    51,
+			balances: map[string]float64{"USD": 0},
+		},
+		{
+			name:     "buy without a base currency account",
+			request:  shared.Transaction{UserID: "1", Type: shared.Buy, CurrencyPair: "USD/BRL", Amount: 10},
+			accounts: map[string]float64{"BRL": 100},
+			event:    shared.TransactionRejected,
+			reason:   "no USD account to credit",
+			total:    51,
+			balances: map[string]float64{"BRL": 100},
+		},
+		{
+			name:     "sell at the bid price",
+			request:  shared.Transaction{UserID: "1", Type: shared.Sell, CurrencyPair: "USD/BRL", Amount: 10},
+			accounts: map[string]float64{"BRL": 0, "USD": 10},
+			event:    shared.TransactionCompleted,
+			total:    49,
+			balances: map[string]float64{"BRL": 49, "USD": 0},
+		},
+		{
+			name:     "sell more than held",
+			request:  shared.Transaction{UserID: "1", Type: shared.Sell, CurrencyPair: "USD/BRL", Amount: 10},
+			accounts: map[string]float64{"BRL": 0, "USD": 5},
+			event:    shared.TransactionRejected,
+			reason:   "insufficient USD holdings",
+			total:    49,
+			balances: map[string]float64{"BRL": 0, "USD": 5},
+		},
+		{
+			name:     "sell without a quote currency account",
+			request:  shared.Transaction{UserID: "1", Type: shared.Sell, CurrencyPair: "USD/BRL", Amount: 10},
+			accounts: map[string]float64{"USD": 10},
+			event:    shared.TransactionRejected,
+			reason:   "no BRL account to credit",
+			total:    49,
+			balances: map[string]float64{"USD": 10},
+		},
+		{
+			name:    "unknown user",
+			request: shared.Transaction{UserID: "2", Type: shared.Buy, CurrencyPair: "USD/BRL", Amount: 10},
+			event:   shared.TransactionRejected,
+			reason:  "user not found",
+		},
+		{
+			name:    "unknown external user",
+			request: shared.Transaction{UserID: "gen-1", Type: shared.Buy, CurrencyPair: "USD/BRL", Amount: 10},
+			event:   shared.TransactionRejected,
+			reason:  "user not found",
+		},
+		{
+			name:    "non-positive amount",
+			request: shared.Transaction{UserID: "1", Type: shared.Buy, CurrencyPair: "USD/BRL"},
+			event:   shared.TransactionRejected,
+			reason:  "amount must be positive",
+		},
+		{
+			name:    "same currency on both sides",
+			request: shared.Transaction{UserID: "1", Type: shared.Buy, CurrencyPair: "BRL/BRL", Amount: 10},
+			event:   shared.TransactionRejected,
+			reason:  "invalid currency pair BRL/BRL",
+		},
+		{
+			name:    "no quotation",
+			request: shared.Transaction{UserID: "1", Type: shared.Buy, CurrencyPair: "EUR/BRL", Amount: 10},
+			event:   shared.TransactionRejected,
+			reason:  "no quotation available for EUR/BRL",
+		},
+		{
+			name:     "stale quotation",
+			request:  shared.Transaction{UserID: "1", Type: shared.Buy, CurrencyPair: "USD/BRL", Amount: 10},
+			quotedAt: -2 * maxQuoteAge,
+			event:    shared.TransactionRejected,
+			reason:   "latest quotation is stale",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			userRepo := memory.NewUserRepository()
+			accountRepo := memory.NewAccountRepository()
+			transactionRepo := memory.NewTransactionRepository(accountRepo)
+			currencyRepo := memory.NewCurrencyRepository()
+			tradeRepo := memory.NewTradeRepository(accountRepo, transactionRepo)
+			outboxRepo := memory.NewOutboxRepository()
+
+			if err := userRepo.Create(ctx, &models.User{Email: "ana@example.com"}); err != nil {
+				t.Fatal(err)
+			}
+			quote := &models.Currency{Code: "USD", Codein: "BRL", Bid: "4.9", Ask: "5.1", QuotedAt: time.Now().Add(tt.quotedAt)}
+			if err := currencyRepo.Insert(ctx, quote); err != nil {
+				t.Fatal(err)
+			}
+			for currency, balance := range tt.accounts {
+				if err := accountRepo.Create(ctx, &models.Account{UserID: 1, Name: currency, Type: models.Checking, Balance: balance, Currency: currency}); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			processor := NewTransactionProcessor(userRepo, accountRepo, currencyRepo, tradeRepo, outboxRepo)
+			body, _ := json.Marshal(tt.request)
+			if err := processor.Handle(ctx, utils.Message{ID: "request", Body: body}); err != nil {
+				t.Fatalf("Handle() error = %v", err)
+			}
+
+			var result shared.TransactionResult
+			for _, msg := range outboxRepo.Pending() {
+				if msg.Queue == TransactionResultsQueue {
+					if err := json.Unmarshal([]byte(msg.Payload), &result); err != nil {
+						t.Fatal(err)
+					}
+				}
+			}
+			if result.Event != tt.event || result.Reason != tt.reason {
+				t.Errorf("result = %s %q, want %s %q", result.Event, result.Reason, tt.event, tt.reason)
+			}
+			if result.CorrelationID != "request" {
+				t.Errorf("CorrelationID = %q, want request", result.CorrelationID)
+			}
+			if result.Transaction.TotalValue != tt.total {
+				t.Errorf("TotalValue = %v, want %v", result.Transaction.TotalValue, tt.total)
+			}
+			if result.Transaction.UserID != tt.request.UserID {
+				t.Errorf("UserID = %q, want %q", result.Transaction.UserID, tt.request.UserID)
+			}
+
+			accounts, err := accountRepo.GetAllByUserID(ctx, 1)
+			if err != nil {
+				t.Fatal(err)
+			}
+			balances := map[string]float64{}
+			for _, account := range accounts {
+				balances[account.Currency] = account.Balance
+			}
+			if len(tt.accounts) > 0 && !reflect.DeepEqual(balances, tt.balances) {
+				t.Errorf("balances = %v, want %v", balances, tt.balances)
+			}
+			legs, err := transactionRepo.GetAllByUserID(ctx, 1, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			wantLegs := 0
+			if tt.event == shared.TransactionCompleted {
+				wantLegs = 2
+			}
+			if len(legs) != wantLegs {
+				t.Errorf("%d ledger entries, want %d", len(legs), wantLegs)
+			}
+		})
+	}
+}
@@ -0,0 +1,103 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/leandroalencar/banco-dados/services/s2-processor/internal/domain/models"
+	"github.com/leandroalencar/banco-dados/services/s2-processor/internal/domain/repositories"
+)
+
+// TransactionService records income and expense transactions on behalf of
+// users
+type TransactionService struct {
+	accountRepo     repositories.AccountRepository
+	categoryRepo    repositories.CategoryRepository
+	transactionRepo repositories.TransactionRepository
+	categorizer     *CategorizationService
+	budgets         *BudgetService
+	uow             repositories.UnitOfWork
+}
+
+// NewTransactionService creates a new transaction service
+func NewTransactionService(accountRepo repositories.AccountRepository, categoryRepo repositories.CategoryRepository, transactionRepo repositories.TransactionRepository, categorizer *CategorizationService, budgets *BudgetService, uow repositories.UnitOfWork) *TransactionService {
+	return &TransactionService{
+		accountRepo:     accountRepo,
+		categoryRepo:    categoryRepo,
+		transactionRepo: transactionRepo,
+		categorizer:     categorizer,
+		budgets:         budgets,
+		uow:             uow,
+	}
+}
+
+// Create records a transaction on an account of userID and applies it to
+// the balance. Its category, if any, must be one userID can use; without
+// one, the categorizer picks it. Budgets covering an expense are checked
+// in the same unit of work that records it, so an alert it triggers is
+// never lost.
+func (s *TransactionService) Create(ctx context.Context, userID uint, transaction *models.Transaction) error {
+	if transaction.Amount <= 0 {
+		return ErrInvalidAmount
+	}
+	if transaction.Date.IsZero() {
+		transaction.Date = time.Now()
+	}
+
+	account, err := s.accountRepo.GetByID(ctx, transaction.AccountID)
+	if err != nil {
+		return err
+	}
+	if account.UserID != userID {
+		return repositories.ErrAccountNotFound
+	}
+
+	if transaction.CategoryID == 0 {
+		categoryID, err := s.categorizer.Categorize(ctx, userID, transaction)
+		if err != nil {
+			return err
+		}
+		transaction.CategoryID = categoryID
+	} else if err := s.checkCategory(ctx, userID, transaction.CategoryID); err != nil {
+		return err
+	}
+
+	return s.uow.Do(ctx, func(ctx context.Context) error {
+		if err := s.transactionRepo.Post(ctx, transaction); err != nil {
+			return err
+		}
+		return s.budgets.Track(ctx, transaction)
+	})
+}
+
+// Get returns a transaction on one of userID's open accounts
+func (s *TransactionService) Get(ctx context.Context, userID, id uint) (*models.Transaction, error) {
+	transaction, err := s.transactionRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	account, err := s.accountRepo.GetByID(ctx, transaction.AccountID)
+	if errors.Is(err, repositories.ErrAccountNotFound) || (err == nil && account.UserID != userID) {
+		return nil, repositories.ErrTransactionNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return transaction, nil
+}
+
+// checkCategory verifies that categoryID is empty or usable by userID
+func (s *TransactionService) checkCategory(ctx context.Context, userID, categoryID uint) error {
+	if categoryID == 0 {
+		return nil
+	}
+	category, err := s.categoryRepo.GetByID(ctx, categoryID)
+	if err != nil {
+		return err
+	}
+	if !category.VisibleTo(userID) {
+		return repositories.ErrCategoryNotFound
+	}
+	return nil
+}
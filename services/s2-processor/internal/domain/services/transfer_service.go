@@ -0,0 +1,118 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"math"
+	"time"
+
+	"github.com/leandroalencar/banco-dados/services/s2-processor/internal/domain/models"
+	"github.com/leandroalencar/banco-dados/services/s2-processor/internal/domain/repositories"
+)
+
+var (
+	// ErrSameAccount is returned for a transfer to its source account
+	ErrSameAccount = errors.New("cannot transfer to the same account")
+
+	// ErrInvalidAmount is returned for amounts that are not positive after
+	// rounding to cents
+	ErrInvalidAmount = errors.New("amount must be positive")
+
+	// ErrRateUnavailable is returned when there is no fresh quotation to
+	// convert between the accounts' currencies
+	ErrRateUnavailable = errors.New("no current exchange rate between the account currencies")
+)
+
+// TransferService moves money between accounts
+type TransferService struct {
+	accountRepo  repositories.AccountRepository
+	currencyRepo repositories.CurrencyRepository
+	transferRepo repositories.TransferRepository
+}
+
+// NewTransferService creates a new transfer service
+func NewTransferService(accountRepo repositories.AccountRepository, currencyRepo repositories.CurrencyRepository, transferRepo repositories.TransferRepository) *TransferService {
+	return &TransferService{
+		accountRepo:  accountRepo,
+		currencyRepo: currencyRepo,
+		transferRepo: transferRepo,
+	}
+}
+
+// Transfer moves transfer.Amount from an account of userID to any open
+// account, converting at the latest quotation when the currencies differ.
+// The transfer is filled in with the conversion and both legs.
+func (s *TransferService) Transfer(ctx context.Context, userID uint, transfer *models.AccountTransfer) error {
+	transfer.Amount = roundCents(transfer.Amount)
+	if transfer.Amount <= 0 {
+		return ErrInvalidAmount
+	}
+	if transfer.FromAccountID == transfer.ToAccountID {
+		return ErrSameAccount
+	}
+
+	from, err := s.accountRepo.GetByID(ctx, transfer.FromAccountID)
+	if err != nil {
+		return err
+	}
+	if from.UserID != userID {
+		return repositories.ErrAccountNotFound
+	}
+	to, err := s.accountRepo.GetByID(ctx, transfer.ToAccountID)
+	if err != nil {
+		return err
+	}
+
+	transfer.ExchangeRate = 1
+	transfer.QuotationID = ""
+	if from.Currency != to.Currency {
+		rate, quotationID, err := s.conversionRate(ctx, from.Currency, to.Currency)
+		if err != nil {
+			return err
+		}
+		transfer.ExchangeRate = rate
+		transfer.QuotationID = quotationID
+	}
+	transfer.ConvertedAmount = roundCents(transfer.Amount * transfer.ExchangeRate)
+	if transfer.ConvertedAmount <= 0 {
+		return ErrInvalidAmount
+	}
+
+	return s.transferRepo.Create(ctx, transfer)
+}
+
+// conversionRate returns how many units of to one unit of from is worth and
+// the quotation it comes from. The bank buys from at its bid price; with
+// only the inverse pair quoted, it sells to at its ask price.
+func (s *TransferService) conversionRate(ctx context.Context, from, to string) (float64, string, error) {
+	quote, err := s.currencyRepo.GetLatest(ctx, from, to)
+	if err != nil {
+		return 0, "", err
+	}
+	if isFresh(quote) {
+		if bid := parsePrice(quote.Bid); bid > 0 {
+			return bid, quote.ID, nil
+		}
+	}
+
+	quote, err = s.currencyRepo.GetLatest(ctx, to, from)
+	if err != nil {
+		return 0, "", err
+	}
+	if isFresh(quote) {
+		if ask := parsePrice(quote.Ask); ask > 0 {
+			return 1 / ask, quote.ID, nil
+		}
+	}
+
+	return 0, "", ErrRateUnavailable
+}
+
+// isFresh reports whether quote exists and is recent enough to price with
+func isFresh(quote *models.Currency) bool {
+	return quote != nil && time.Since(quote.QuotedAt) <= maxQuoteAge
+}
+
+func roundCents(amount float64) float64 {
+	return math.Round(amount*100) / 100
+}
@@ -0,0 +1,65 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+
+	"github.com/leandroalencar/banco-dados/services/s2-processor/internal/domain/repositories"
+	"github.com/leandroalencar/banco-dados/services/s2-processor/internal/dto"
+	shared "github.com/leandroalencar/banco-dados/shared/models"
+	"github.com/leandroalencar/banco-dados/shared/utils"
+)
+
+// UsersQueue receives the lifecycle events of users created outside the API
+const UsersQueue = "users"
+
+// UserEventProcessor applies user lifecycle events to the users in
+// PostgreSQL. Events identify users by their external IDs, so they never
+// touch users registered through the API.
+type UserEventProcessor struct {
+	userRepo repositories.UserRepository
+}
+
+// NewUserEventProcessor creates a new user event processor
+func NewUserEventProcessor(userRepo repositories.UserRepository) *UserEventProcessor {
+	return &UserEventProcessor{userRepo: userRepo}
+}
+
+// Handle applies one user event. It is meant to be wrapped with
+// Deduplicate, so a redelivered event is not applied over a later one.
+func (p *UserEventProcessor) Handle(ctx context.Context, msg utils.Message) error {
+	var event shared.UserEvent
+	if err := json.Unmarshal(msg.Body, &event); err != nil {
+		// Malformed messages are never going to succeed; drop them
+		log.Printf("Discarding malformed user event: %v", err)
+		return nil
+	}
+
+	user, err := dto.UserFromMessage(event.User)
+	if err != nil {
+		log.Printf("Discarding %s event: %v", event.Event, err)
+		return nil
+	}
+
+	switch event.Event {
+	case shared.UserCreated, shared.UserUpdated:
+		if user.UpdatedAt.IsZero() {
+			user.UpdatedAt = event.OccurredAt
+		}
+		return p.userRepo.Upsert(ctx, user)
+	case shared.UserDeleted:
+		existing, err := p.userRepo.GetByExternalID(ctx, user.ExternalID)
+		if errors.Is(err, repositories.ErrUserNotFound) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		return p.userRepo.Delete(ctx, existing.ID)
+	default:
+		log.Printf("Discarding unknown user event %q", event.Event)
+		return nil
+	}
+}
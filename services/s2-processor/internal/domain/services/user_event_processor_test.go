@@ -0,0 +1,84 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/leandroalencar/banco-dados/services/s2-processor/internal/domain/models"
+	"github.com/leandroalencar/banco-dados/services/s2-processor/internal/domain/repositories"
+	"github.com/leandroalencar/banco-dados/services/s2-processor/internal/domain/repositories/memory"
+	shared "github.com/leandroalencar/banco-dados/shared/models"
+	"github.com/leandroalencar/banco-dados/shared/utils"
+)
+
+func TestUserEventProcessorHandle(t *testing.T) {
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	created := shared.UserEvent{
+		Event:      shared.UserCreated,
+		User:       shared.User{ID: "gen-7", Name: "Ana Souza", Email: "ana@example.com", UpdatedAt: start},
+		OccurredAt: start,
+	}
+	updated := shared.UserEvent{
+		Event:      shared.UserUpdated,
+		User:       shared.User{ID: "gen-7", Name: "Ana Lima", Email: "ana.lima@example.com", UpdatedAt: start.Add(time.Hour)},
+		OccurredAt: start.Add(time.Hour),
+	}
+	deleted := shared.UserEvent{Event: shared.UserDeleted, User: shared.User{ID: "gen-7"}, OccurredAt: start.Add(2 * time.Hour)}
+
+	tests := []struct {
+		name     string
+		events   []shared.UserEvent
+		ids      []string
+		email    string
+		lastName string
+	}{
+		{name: "created", events: []shared.UserEvent{created}, ids: []string{"a"}, email: "ana@example.com", lastName: "Souza"},
+		{name: "updated", events: []shared.UserEvent{created, updated}, ids: []string{"a", "b"}, email: "ana.lima@example.com", lastName: "Lima"},
+		{name: "update redelivered after a newer one", events: []shared.UserEvent{created, updated, updated}, ids: []string{"a", "b", "b"}, email: "ana.lima@example.com", lastName: "Lima"},
+		{name: "older update", events: []shared.UserEvent{updated, created}, ids: []string{"a", "b"}, email: "ana.lima@example.com", lastName: "Lima"},
+		{name: "deleted", events: []shared.UserEvent{created, deleted}, ids: []string{"a", "b"}},
+		{name: "recreated after deletion", events: []shared.UserEvent{created, deleted, updated}, ids: []string{"a", "b", "c"}, email: "ana.lima@example.com", lastName: "Lima"},
+		{name: "decimal id", events: []shared.UserEvent{{Event: shared.UserCreated, User: shared.User{ID: "1", Name: "Ana Souza", Email: "ana@example.com"}}}, ids: []string{"a"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			userRepo := memory.NewUserRepository()
+			registered := &models.User{Email: "bia@example.com", FirstName: "Bia"}
+			if err := userRepo.Create(ctx, registered); err != nil {
+				t.Fatal(err)
+			}
+			handle := Deduplicate(memory.NewInboxRepository(), NewUserEventProcessor(userRepo).Handle)
+			for i, event := range tt.events {
+				body, _ := json.Marshal(event)
+				if err := handle(utils.Message{ID: tt.ids[i], Queue: UsersQueue, Body: body}); err != nil {
+					t.Fatalf("Handle(%s) error = %v", event.Event, err)
+				}
+			}
+
+			// Events never reach users registered through the API, whatever
+			// their IDs
+			if user, err := userRepo.GetByID(ctx, registered.ID); err != nil || user.Email != registered.Email {
+				t.Errorf("registered user = %v, %v, want it unchanged", user, err)
+			}
+
+			user, err := userRepo.GetByExternalID(ctx, "gen-7")
+			if tt.email == "" {
+				if !errors.Is(err, repositories.ErrUserNotFound) {
+					t.Errorf("GetByExternalID() = %v, %v, want ErrUserNotFound", user, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if user.Email != tt.email || user.FirstName != "Ana" || user.LastName != tt.lastName {
+				t.Errorf("user = %s %s <%s>, want Ana %s <%s>", user.FirstName, user.LastName, user.Email, tt.lastName, tt.email)
+			}
+		})
+	}
+}
@@ -4,23 +4,39 @@ import (
 	"context"
 	"errors"
 	"regexp"
+	"strconv"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
+	"github.com/leandroalencar/banco-dados/services/s2-processor/internal/domain/models"
 	"github.com/leandroalencar/banco-dados/services/s2-processor/internal/domain/repositories"
-	"github.com/leandroalencar/banco-dados/shared/models"
-	"golang.org/x/crypto/bcrypt"
+)
+
+var (
+	// ErrInvalidEmail is returned for a malformed email address
+	ErrInvalidEmail = errors.New("invalid email format")
+
+	// ErrWeakPassword is returned for a password that is too easy to guess
+	ErrWeakPassword = errors.New("password must be at least 8 characters and include uppercase, lowercase, number, and special character")
+
+	// ErrEmailTaken is returned when registering an email that already has
+	// a user
+	ErrEmailTaken = errors.New("email already registered")
+
+	// ErrInvalidCredentials is returned when logging in with an unknown
+	// email or a wrong password
+	ErrInvalidCredentials = errors.New("invalid email or password")
 )
 
 // UserService handles business logic related to users
 type UserService struct {
-	userRepo      *repositories.AccountRepository
+	userRepo      repositories.UserRepository
 	jwtSecret     string
 	jwtExpiration time.Duration
 }
 
 // NewUserService creates a new user service
-func NewUserService(userRepo *repositories.AccountRepository, jwtSecret string, jwtExpiration time.Duration) *UserService {
+func NewUserService(userRepo repositories.UserRepository, jwtSecret string, jwtExpiration time.Duration) *UserService {
 	return &UserService{
 		userRepo:      userRepo,
 		jwtSecret:     jwtSecret,
@@ -37,7 +53,7 @@ type RegisterUserInput struct {
 
 // RegisterUserOutput contains the result of a successful registration
 type RegisterUserOutput struct {
-	ID        string    `json:"id"`
+	ID        uint      `json:"id"`
 	Email     string    `json:"email"`
 	Name      string    `json:"name"`
 	CreatedAt time.Time `json:"created_at"`
@@ -48,27 +64,32 @@ type RegisterUserOutput struct {
 func (s *UserService) RegisterUser(ctx context.Context, input RegisterUserInput) (*RegisterUserOutput, error) {
 	// Validate email format
 	if !isValidEmail(input.Email) {
-		return nil, errors.New("invalid email format")
+		return nil, ErrInvalidEmail
 	}
 
 	// Validate password strength
 	if !isStrongPassword(input.Password) {
-		return nil, errors.New("password must be at least 8 characters and include uppercase, lowercase, number, and special character")
+		return nil, ErrWeakPassword
 	}
 
-	// Create new user
-	_, err := bcrypt.GenerateFromPassword([]byte(input.Password), bcrypt.DefaultCost)
-	if err != nil {
-		return nil, errors.New("failed to hash password")
+	// Refuse emails that are already registered
+	if _, err := s.userRepo.GetByEmail(ctx, input.Email); err == nil {
+		return nil, ErrEmailTaken
+	} else if !errors.Is(err, repositories.ErrUserNotFound) {
+		return nil, err
 	}
 
+	// Create new user
 	user := &models.User{
 		Email:     input.Email,
-		Name:      input.Name,
-		Balance:   0,
+		Password:  input.Password,
 		CreatedAt: time.Now(),
 		UpdatedAt: time.Now(),
 	}
+	user.SetName(input.Name)
+	if err := user.HashPassword(); err != nil {
+		return nil, errors.New("failed to hash password")
+	}
 
 	// Save user to database
 	if err := s.userRepo.Create(ctx, user); err != nil {
@@ -85,20 +106,45 @@ func (s *UserService) RegisterUser(ctx context.Context, input RegisterUserInput)
 	return &RegisterUserOutput{
 		ID:        user.ID,
 		Email:     user.Email,
-		Name:      user.Name,
+		Name:      user.Name(),
 		CreatedAt: user.CreatedAt,
 		Token:     token,
 	}, nil
 }
 
+// Login checks a user's email and password and returns a new token for them
+func (s *UserService) Login(ctx context.Context, email, password string) (string, error) {
+	user, err := s.userRepo.GetByEmail(ctx, email)
+	if errors.Is(err, repositories.ErrUserNotFound) {
+		return "", ErrInvalidCredentials
+	}
+	if err != nil {
+		return "", err
+	}
+	if !user.CheckPassword(password) {
+		return "", ErrInvalidCredentials
+	}
+
+	token, err := s.generateToken(user.ID)
+	if err != nil {
+		return "", errors.New("failed to generate token")
+	}
+	return token, nil
+}
+
+// Get returns a user by ID
+func (s *UserService) Get(ctx context.Context, id uint) (*models.User, error) {
+	return s.userRepo.GetByID(ctx, id)
+}
+
 // generateToken creates a new JWT token for a user
-func (s *UserService) generateToken(userID string) (string, error) {
+func (s *UserService) generateToken(userID uint) (string, error) {
 	// Set expiration time
 	expirationTime := time.Now().Add(s.jwtExpiration)
 
 	// Create claims
 	claims := jwt.MapClaims{
-		"user_id": userID,
+		"user_id": strconv.FormatUint(uint64(userID), 10),
 		"exp":     expirationTime.Unix(),
 		"iat":     time.Now().Unix(),
 	}
@@ -115,6 +161,28 @@ func (s *UserService) generateToken(userID string) (string, error) {
 	return tokenString, nil
 }
 
+// Authenticate validates a token issued by this service and returns the ID
+// of the user it was issued to
+func (s *UserService) Authenticate(tokenString string) (uint, error) {
+	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
+		return []byte(s.jwtSecret), nil
+	}, jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}), jwt.WithExpirationRequired())
+	if err != nil {
+		return 0, errors.New("invalid token: " + err.Error())
+	}
+
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok {
+		return 0, errors.New("invalid token claims")
+	}
+	subject, _ := claims["user_id"].(string)
+	userID, err := strconv.ParseUint(subject, 10, 64)
+	if err != nil {
+		return 0, errors.New("invalid user id in token")
+	}
+	return uint(userID), nil
+}
+
 // Helper functions for validation
 func isValidEmail(email string) bool {
 	pattern := `^[a-zA-Z0-9._%+-]+@[a-zA-Z0-9.-]+\.[a-zA-Z]{2,}$`
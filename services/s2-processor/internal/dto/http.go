@@ -0,0 +1,638 @@
+// Package dto maps domain models to and from the representations other
+// services and clients see: JSON bodies of the HTTP API and queue messages
+// in shared/models.
+package dto
+
+import (
+	"time"
+
+	"github.com/leandroalencar/banco-dados/services/s2-processor/internal/domain/models"
+)
+
+// CreateUserRequest is the body of POST /users
+type CreateUserRequest struct {
+	Email     string `json:"email" binding:"required,email"`
+	Password  string `json:"password" binding:"required,min=8"`
+	FirstName string `json:"first_name" binding:"required"`
+	LastName  string `json:"last_name"`
+}
+
+// ToModel returns the user the request asks to create
+func (r CreateUserRequest) ToModel() *models.User {
+	return &models.User{
+		Email:     r.Email,
+		Password:  r.Password,
+		FirstName: r.FirstName,
+		LastName:  r.LastName,
+	}
+}
+
+// LoginRequest is the body of POST /login
+type LoginRequest struct {
+	Email    string `json:"email" binding:"required"`
+	Password string `json:"password" binding:"required"`
+}
+
+// User is a user as returned by the API. It never includes the password.
+type User struct {
+	ID        uint      `json:"id"`
+	Email     string    `json:"email"`
+	FirstName string    `json:"first_name"`
+	LastName  string    `json:"last_name"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// NewUser maps a domain user to its API representation
+func NewUser(u *models.User) User {
+	return User{
+		ID:        u.ID,
+		Email:     u.Email,
+		FirstName: u.FirstName,
+		LastName:  u.LastName,
+		CreatedAt: u.CreatedAt,
+		UpdatedAt: u.UpdatedAt,
+	}
+}
+
+// TransactionRequest is the body of POST /transactions. Transfers are made
+// with POST /transfers instead.
+type TransactionRequest struct {
+	AccountID   uint      `json:"account_id" binding:"required"`
+	CategoryID  uint      `json:"category_id"`
+	Amount      float64   `json:"amount" binding:"required,gt=0"`
+	Type        string    `json:"type" binding:"required,oneof=income expense"`
+	Description string    `json:"description"`
+	Date        time.Time `json:"date"`
+}
+
+// ToModel returns the transaction the request asks to record. A missing
+// date means now.
+func (r TransactionRequest) ToModel() *models.Transaction {
+	date := r.Date
+	if date.IsZero() {
+		date = time.Now()
+	}
+	return &models.Transaction{
+		AccountID:   r.AccountID,
+		CategoryID:  r.CategoryID,
+		Amount:      r.Amount,
+		Type:        models.TransactionType(r.Type),
+		Description: r.Description,
+		Date:        date,
+	}
+}
+
+// Transaction is a ledger transaction as returned by the API
+type Transaction struct {
+	ID          uint      `json:"id"`
+	AccountID   uint      `json:"account_id"`
+	CategoryID  uint      `json:"category_id"`
+	TransferID  uint      `json:"transfer_id,omitempty"`
+	Amount      float64   `json:"amount"`
+	Type        string    `json:"type"`
+	Description string    `json:"description"`
+	Date        time.Time `json:"date"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// NewTransaction maps a domain transaction to its API representation
+func NewTransaction(t *models.Transaction) Transaction {
+	return Transaction{
+		ID:          t.ID,
+		AccountID:   t.AccountID,
+		CategoryID:  t.CategoryID,
+		TransferID:  t.TransferID,
+		Amount:      t.Amount,
+		Type:        string(t.Type),
+		Description: t.Description,
+		Date:        t.Date,
+		CreatedAt:   t.CreatedAt,
+		UpdatedAt:   t.UpdatedAt,
+	}
+}
+
+// Trade is a trade as returned by the API. Recorded trades travel to the
+// history store in the same format.
+type Trade struct {
+	ID            uint      `json:"id"`
+	CorrelationID string    `json:"correlation_id"`
+	UserID        uint      `json:"user_id"`
+	Type          string    `json:"type"`
+	CurrencyPair  string    `json:"currency_pair"`
+	Amount        float64   `json:"amount"`
+	ExchangeRate  float64   `json:"exchange_rate"`
+	TotalValue    float64   `json:"total_value"`
+	QuotationID   string    `json:"quotation_id"`
+	Status        string    `json:"status"`
+	Reason        string    `json:"reason,omitempty"`
+	RequestedAt   time.Time `json:"requested_at"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+// NewTrade maps a domain trade to its API representation
+func NewTrade(t *models.Trade) Trade {
+	return Trade{
+		ID:            t.ID,
+		CorrelationID: t.CorrelationID,
+		UserID:        t.UserID,
+		Type:          string(t.Type),
+		CurrencyPair:  t.CurrencyPair,
+		Amount:        t.Amount,
+		ExchangeRate:  t.ExchangeRate,
+		TotalValue:    t.TotalValue,
+		QuotationID:   t.QuotationID,
+		Status:        string(t.Status),
+		Reason:        t.Reason,
+		RequestedAt:   t.RequestedAt,
+		CreatedAt:     t.CreatedAt,
+	}
+}
+
+// ToModel returns the trade t represents
+func (t Trade) ToModel() *models.Trade {
+	return &models.Trade{
+		ID:            t.ID,
+		CorrelationID: t.CorrelationID,
+		UserID:        t.UserID,
+		Type:          models.TradeType(t.Type),
+		CurrencyPair:  t.CurrencyPair,
+		Amount:        t.Amount,
+		ExchangeRate:  t.ExchangeRate,
+		TotalValue:    t.TotalValue,
+		QuotationID:   t.QuotationID,
+		Status:        models.TradeStatus(t.Status),
+		Reason:        t.Reason,
+		RequestedAt:   t.RequestedAt,
+		CreatedAt:     t.CreatedAt,
+	}
+}
+
+// CreateAccountRequest is the body of POST /accounts
+type CreateAccountRequest struct {
+	Name        string `json:"name" binding:"required"`
+	Type        string `json:"type" binding:"required,oneof=checking savings credit investment"`
+	Currency    string `json:"currency" binding:"omitempty,len=3"`
+	Description string `json:"description"`
+}
+
+// ToModel returns the account the request asks to open
+func (r CreateAccountRequest) ToModel() *models.Account {
+	return &models.Account{
+		Name:        r.Name,
+		Type:        models.AccountType(r.Type),
+		Currency:    r.Currency,
+		Description: r.Description,
+	}
+}
+
+// UpdateAccountRequest is the body of PATCH /accounts/:id. Omitted fields
+// are left unchanged.
+type UpdateAccountRequest struct {
+	Name        *string `json:"name" binding:"omitempty,min=1"`
+	Type        *string `json:"type" binding:"omitempty,oneof=checking savings credit investment"`
+	Description *string `json:"description"`
+}
+
+// Account is an account as returned by the API
+type Account struct {
+	ID          uint      `json:"id"`
+	UserID      uint      `json:"user_id"`
+	Name        string    `json:"name"`
+	Type        string    `json:"type"`
+	Balance     float64   `json:"balance"`
+	Currency    string    `json:"currency"`
+	Description string    `json:"description"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// NewAccount maps a domain account to its API representation
+func NewAccount(a *models.Account) Account {
+	return Account{
+		ID:          a.ID,
+		UserID:      a.UserID,
+		Name:        a.Name,
+		Type:        string(a.Type),
+		Balance:     a.Balance,
+		Currency:    a.Currency,
+		Description: a.Description,
+		CreatedAt:   a.CreatedAt,
+		UpdatedAt:   a.UpdatedAt,
+	}
+}
+
+// TransferRequest is the body of POST /transfers
+type TransferRequest struct {
+	FromAccountID uint    `json:"from_account_id" binding:"required"`
+	ToAccountID   uint    `json:"to_account_id" binding:"required"`
+	Amount        float64 `json:"amount" binding:"required,gt=0"`
+	Description   string  `json:"description"`
+}
+
+// ToModel returns the transfer the request asks for
+func (r TransferRequest) ToModel() *models.AccountTransfer {
+	return &models.AccountTransfer{
+		FromAccountID: r.FromAccountID,
+		ToAccountID:   r.ToAccountID,
+		Amount:        r.Amount,
+		Description:   r.Description,
+	}
+}
+
+// Transfer is a transfer as returned by the API, with both of its legs
+type Transfer struct {
+	ID              uint        `json:"id"`
+	FromAccountID   uint        `json:"from_account_id"`
+	ToAccountID     uint        `json:"to_account_id"`
+	Amount          float64     `json:"amount"`
+	ConvertedAmount float64     `json:"converted_amount"`
+	ExchangeRate    float64     `json:"exchange_rate"`
+	QuotationID     string      `json:"quotation_id,omitempty"`
+	Description     string      `json:"description"`
+	CreatedAt       time.Time   `json:"created_at"`
+	Debit           Transaction `json:"debit"`
+	Credit          Transaction `json:"credit"`
+}
+
+// NewTransfer maps a domain transfer to its API representation
+func NewTransfer(t *models.AccountTransfer) Transfer {
+	return Transfer{
+		ID:              t.ID,
+		FromAccountID:   t.FromAccountID,
+		ToAccountID:     t.ToAccountID,
+		Amount:          t.Amount,
+		ConvertedAmount: t.ConvertedAmount,
+		ExchangeRate:    t.ExchangeRate,
+		QuotationID:     t.QuotationID,
+		Description:     t.Description,
+		CreatedAt:       t.CreatedAt,
+		Debit:           NewTransaction(&t.Debit),
+		Credit:          NewTransaction(&t.Credit),
+	}
+}
+
+// CategoryRequest is the body of POST /categories
+type CategoryRequest struct {
+	Name     string `json:"name" binding:"required"`
+	ParentID uint   `json:"parent_id"`
+}
+
+// ToModel returns the category the request asks to create
+func (r CategoryRequest) ToModel() *models.Category {
+	return &models.Category{
+		Name:     r.Name,
+		ParentID: r.ParentID,
+	}
+}
+
+// UpdateCategoryRequest is the body of PATCH /categories/:id. Omitted fields
+// are left unchanged; a parent_id of 0 moves the category to the top level.
+type UpdateCategoryRequest struct {
+	Name     *string `json:"name" binding:"omitempty,min=1"`
+	ParentID *uint   `json:"parent_id"`
+}
+
+// Category is a category as returned by the API
+type Category struct {
+	ID        uint      `json:"id"`
+	ParentID  uint      `json:"parent_id,omitempty"`
+	Name      string    `json:"name"`
+	System    bool      `json:"system"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// NewCategory maps a domain category to its API representation
+func NewCategory(c *models.Category) Category {
+	return Category{
+		ID:        c.ID,
+		ParentID:  c.ParentID,
+		Name:      c.Name,
+		System:    c.IsSystem(),
+		CreatedAt: c.CreatedAt,
+		UpdatedAt: c.UpdatedAt,
+	}
+}
+
+// CategorizationRuleRequest is the body of POST /categorization-rules and
+// PUT /categorization-rules/:id
+type CategorizationRuleRequest struct {
+	CategoryID         uint     `json:"category_id" binding:"required"`
+	Priority           int      `json:"priority"`
+	DescriptionPattern string   `json:"description_pattern"`
+	MatchType          string   `json:"match_type" binding:"omitempty,oneof=contains equals regex"`
+	MinAmount          *float64 `json:"min_amount"`
+	MaxAmount          *float64 `json:"max_amount"`
+	AccountID          uint     `json:"account_id"`
+}
+
+// ToModel returns the rule the request describes
+func (r CategorizationRuleRequest) ToModel() *models.CategorizationRule {
+	return &models.CategorizationRule{
+		CategoryID:         r.CategoryID,
+		Priority:           r.Priority,
+		DescriptionPattern: r.DescriptionPattern,
+		MatchType:          models.MatchType(r.MatchType),
+		MinAmount:          r.MinAmount,
+		MaxAmount:          r.MaxAmount,
+		AccountID:          r.AccountID,
+	}
+}
+
+// CategorizationRule is a categorization rule as returned by the API
+type CategorizationRule struct {
+	ID                 uint      `json:"id"`
+	CategoryID         uint      `json:"category_id"`
+	Priority           int       `json:"priority"`
+	DescriptionPattern string    `json:"description_pattern,omitempty"`
+	MatchType          string    `json:"match_type"`
+	MinAmount          *float64  `json:"min_amount,omitempty"`
+	MaxAmount          *float64  `json:"max_amount,omitempty"`
+	AccountID          uint      `json:"account_id,omitempty"`
+	CreatedAt          time.Time `json:"created_at"`
+	UpdatedAt          time.Time `json:"updated_at"`
+}
+
+// NewCategorizationRule maps a domain rule to its API representation
+func NewCategorizationRule(r *models.CategorizationRule) CategorizationRule {
+	return CategorizationRule{
+		ID:                 r.ID,
+		CategoryID:         r.CategoryID,
+		Priority:           r.Priority,
+		DescriptionPattern: r.DescriptionPattern,
+		MatchType:          string(r.MatchType),
+		MinAmount:          r.MinAmount,
+		MaxAmount:          r.MaxAmount,
+		AccountID:          r.AccountID,
+		CreatedAt:          r.CreatedAt,
+		UpdatedAt:          r.UpdatedAt,
+	}
+}
+
+// ScheduleRequest is the body of POST /schedules. Transactions need
+// account_id and transaction_type; trades need trade_type and
+// currency_pair. The schedule repeats every interval periods of frequency
+// from start_at, until until or for count occurrences.
+type ScheduleRequest struct {
+	Kind            string     `json:"kind" binding:"required,oneof=transaction trade"`
+	Frequency       string     `json:"frequency" binding:"required,oneof=daily weekly monthly"`
+	Interval        int        `json:"interval" binding:"omitempty,min=1"`
+	StartAt         time.Time  `json:"start_at"`
+	Until           *time.Time `json:"until"`
+	Count           int        `json:"count" binding:"omitempty,min=1"`
+	Amount          float64    `json:"amount" binding:"required,gt=0"`
+	AccountID       uint       `json:"account_id" binding:"required_if=Kind transaction"`
+	CategoryID      uint       `json:"category_id"`
+	TransactionType string     `json:"transaction_type" binding:"required_if=Kind transaction,omitempty,oneof=income expense"`
+	Description     string     `json:"description"`
+	TradeType       string     `json:"trade_type" binding:"required_if=Kind trade,omitempty,oneof=BUY SELL"`
+	CurrencyPair    string     `json:"currency_pair" binding:"required_if=Kind trade"`
+}
+
+// ToModel returns the schedule the request asks to create
+func (r ScheduleRequest) ToModel() *models.Schedule {
+	return &models.Schedule{
+		Kind: models.ScheduleKind(r.Kind),
+		Recurrence: models.Recurrence{
+			Frequency: models.Frequency(r.Frequency),
+			Interval:  r.Interval,
+			StartAt:   r.StartAt,
+			Until:     r.Until,
+			Count:     r.Count,
+		},
+		Amount:          r.Amount,
+		AccountID:       r.AccountID,
+		CategoryID:      r.CategoryID,
+		TransactionType: models.TransactionType(r.TransactionType),
+		Description:     r.Description,
+		TradeType:       models.TradeType(r.TradeType),
+		CurrencyPair:    r.CurrencyPair,
+	}
+}
+
+// Schedule is a schedule as returned by the API. NextRunAt is omitted once
+// the schedule has ended.
+type Schedule struct {
+	ID              uint       `json:"id"`
+	Kind            string     `json:"kind"`
+	Frequency       string     `json:"frequency"`
+	Interval        int        `json:"interval"`
+	StartAt         time.Time  `json:"start_at"`
+	Until           *time.Time `json:"until,omitempty"`
+	Count           int        `json:"count,omitempty"`
+	Amount          float64    `json:"amount"`
+	AccountID       uint       `json:"account_id,omitempty"`
+	CategoryID      uint       `json:"category_id,omitempty"`
+	TransactionType string     `json:"transaction_type,omitempty"`
+	Description     string     `json:"description,omitempty"`
+	TradeType       string     `json:"trade_type,omitempty"`
+	CurrencyPair    string     `json:"currency_pair,omitempty"`
+	Occurrences     int        `json:"occurrences"`
+	NextRunAt       *time.Time `json:"next_run_at,omitempty"`
+	LastError       string     `json:"last_error,omitempty"`
+	CreatedAt       time.Time  `json:"created_at"`
+	UpdatedAt       time.Time  `json:"updated_at"`
+}
+
+// NewSchedule maps a domain schedule to its API representation
+func NewSchedule(s *models.Schedule) Schedule {
+	return Schedule{
+		ID:              s.ID,
+		Kind:            string(s.Kind),
+		Frequency:       string(s.Recurrence.Frequency),
+		Interval:        s.Recurrence.Interval,
+		StartAt:         s.Recurrence.StartAt,
+		Until:           s.Recurrence.Until,
+		Count:           s.Recurrence.Count,
+		Amount:          s.Amount,
+		AccountID:       s.AccountID,
+		CategoryID:      s.CategoryID,
+		TransactionType: string(s.TransactionType),
+		Description:     s.Description,
+		TradeType:       string(s.TradeType),
+		CurrencyPair:    s.CurrencyPair,
+		Occurrences:     s.Occurrences,
+		NextRunAt:       s.NextRunAt,
+		LastError:       s.LastError,
+		CreatedAt:       s.CreatedAt,
+		UpdatedAt:       s.UpdatedAt,
+	}
+}
+
+// BudgetRequest is the body of POST /budgets. Thresholds are percentages
+// of the limit and default to 80 and 100.
+type BudgetRequest struct {
+	CategoryID uint    `json:"category_id" binding:"required"`
+	Limit      float64 `json:"limit" binding:"required,gt=0"`
+	Currency   string  `json:"currency" binding:"omitempty,len=3"`
+	Thresholds []int   `json:"thresholds"`
+}
+
+// ToModel returns the budget the request asks to create
+func (r BudgetRequest) ToModel() *models.Budget {
+	return &models.Budget{
+		CategoryID: r.CategoryID,
+		Limit:      r.Limit,
+		Currency:   r.Currency,
+		Thresholds: r.Thresholds,
+	}
+}
+
+// UpdateBudgetRequest is the body of PATCH /budgets/:id. Omitted fields are
+// left unchanged.
+type UpdateBudgetRequest struct {
+	Limit      *float64 `json:"limit" binding:"omitempty,gt=0"`
+	Thresholds []int    `json:"thresholds"`
+}
+
+// Budget is a budget as returned by the API
+type Budget struct {
+	ID         uint      `json:"id"`
+	CategoryID uint      `json:"category_id"`
+	Limit      float64   `json:"limit"`
+	Currency   string    `json:"currency"`
+	Thresholds []int     `json:"thresholds"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+// NewBudget maps a domain budget to its API representation
+func NewBudget(b *models.Budget) Budget {
+	return Budget{
+		ID:         b.ID,
+		CategoryID: b.CategoryID,
+		Limit:      b.Limit,
+		Currency:   b.Currency,
+		Thresholds: b.Thresholds,
+		CreatedAt:  b.CreatedAt,
+		UpdatedAt:  b.UpdatedAt,
+	}
+}
+
+// BudgetStatus is the spending against a budget in a month, as returned by
+// GET /budgets/:id/status
+type BudgetStatus struct {
+	BudgetID   uint    `json:"budget_id"`
+	CategoryID uint    `json:"category_id"`
+	Month      string  `json:"month"`
+	Currency   string  `json:"currency"`
+	Limit      float64 `json:"limit"`
+	Spent      float64 `json:"spent"`
+	Remaining  float64 `json:"remaining"`
+	Percent    float64 `json:"percent"`
+	Reached    []int   `json:"thresholds_reached"`
+}
+
+// NewBudgetStatus maps a domain budget status to its API representation
+func NewBudgetStatus(s *models.BudgetStatus) BudgetStatus {
+	reached := s.Reached()
+	if reached == nil {
+		reached = []int{}
+	}
+	return BudgetStatus{
+		BudgetID:   s.Budget.ID,
+		CategoryID: s.Budget.CategoryID,
+		Month:      s.Month.Format("2006-01"),
+		Currency:   s.Budget.Currency,
+		Limit:      s.Budget.Limit,
+		Spent:      s.Spent,
+		Remaining:  s.Remaining(),
+		Percent:    s.Percent(),
+		Reached:    reached,
+	}
+}
+
+// Statement is an account statement as returned by the API. It covers
+// transactions dated from From up to, but excluding, To.
+type Statement struct {
+	AccountID      uint            `json:"account_id"`
+	Currency       string          `json:"currency"`
+	From           time.Time       `json:"from"`
+	To             time.Time       `json:"to"`
+	OpeningBalance float64         `json:"opening_balance"`
+	ClosingBalance float64         `json:"closing_balance"`
+	Totals         StatementTotals `json:"totals"`
+	Lines          []StatementLine `json:"transactions"`
+}
+
+// StatementTotals sums the transactions of a statement by type
+type StatementTotals struct {
+	Income       float64 `json:"income"`
+	Expense      float64 `json:"expense"`
+	TransfersIn  float64 `json:"transfers_in"`
+	TransfersOut float64 `json:"transfers_out"`
+	TradesIn     float64 `json:"trades_in"`
+	TradesOut    float64 `json:"trades_out"`
+}
+
+// StatementLine is a transaction on a statement with the balance after it.
+// FX is set on transfer legs that converted between currencies and on the
+// legs of currency trades.
+type StatementLine struct {
+	Transaction
+	Balance float64      `json:"balance"`
+	FX      *StatementFX `json:"fx,omitempty"`
+}
+
+// StatementFX describes the conversion of a transfer or trade between
+// currencies: Amount left the from account and ConvertedAmount reached the
+// to account
+type StatementFX struct {
+	FromAccountID   uint    `json:"from_account_id"`
+	ToAccountID     uint    `json:"to_account_id"`
+	Amount          float64 `json:"amount"`
+	ConvertedAmount float64 `json:"converted_amount"`
+	ExchangeRate    float64 `json:"exchange_rate"`
+	QuotationID     string  `json:"quotation_id"`
+}
+
+// NewStatement maps a domain statement to its API representation
+func NewStatement(s *models.Statement) Statement {
+	lines := make([]StatementLine, len(s.Lines))
+	for i, line := range s.Lines {
+		lines[i] = StatementLine{
+			Transaction: NewTransaction(&line.Transaction),
+			Balance:     line.Balance,
+		}
+		if t := line.Transfer; t != nil && t.QuotationID != "" {
+			lines[i].FX = &StatementFX{
+				FromAccountID:   t.FromAccountID,
+				ToAccountID:     t.ToAccountID,
+				Amount:          t.Amount,
+				ConvertedAmount: t.ConvertedAmount,
+				ExchangeRate:    t.ExchangeRate,
+				QuotationID:     t.QuotationID,
+			}
+		}
+		if t := line.Trade; t != nil {
+			debit, credit := t.Legs()
+			lines[i].FX = &StatementFX{
+				FromAccountID:   debit.AccountID,
+				ToAccountID:     credit.AccountID,
+				Amount:          -debit.Amount,
+				ConvertedAmount: credit.Amount,
+				ExchangeRate:    t.ExchangeRate,
+				QuotationID:     t.QuotationID,
+			}
+		}
+	}
+	return Statement{
+		AccountID:      s.Account.ID,
+		Currency:       s.Account.Currency,
+		From:           s.From,
+		To:             s.To,
+		OpeningBalance: s.OpeningBalance,
+		ClosingBalance: s.ClosingBalance,
+		Totals: StatementTotals{
+			Income:       s.Totals.Income,
+			Expense:      s.Totals.Expense,
+			TransfersIn:  s.Totals.TransfersIn,
+			TransfersOut: s.Totals.TransfersOut,
+			TradesIn:     s.Totals.TradesIn,
+			TradesOut:    s.Totals.TradesOut,
+		},
+		Lines: lines,
+	}
+}
@@ -0,0 +1,141 @@
+package dto
+
+import (
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/leandroalencar/banco-dados/services/s2-processor/internal/domain/models"
+	shared "github.com/leandroalencar/banco-dados/shared/models"
+)
+
+// ErrInvalidID is returned when a message carries an ID that is not a
+// decimal number. IDs travel as strings on the queues and are numeric in
+// PostgreSQL.
+var ErrInvalidID = errors.New("invalid id")
+
+// TradeFromMessage maps a transaction request from the transactions queue
+// to the trade it asks for. The trade has no outcome yet; a malformed user
+// ID is reported as ErrInvalidID along with the rest of the trade.
+func TradeFromMessage(msg shared.Transaction) (*models.Trade, error) {
+	trade := &models.Trade{
+		Type:         models.TradeType(msg.Type),
+		CurrencyPair: msg.CurrencyPair,
+		Amount:       msg.Amount,
+		RequestedAt:  msg.Timestamp,
+	}
+	userID, err := parseID(msg.UserID)
+	if err != nil {
+		return trade, err
+	}
+	trade.UserID = userID
+	return trade, nil
+}
+
+// TradeToMessage maps a trade to the transaction message format, as sent
+// back in transaction results
+func TradeToMessage(trade *models.Trade) shared.Transaction {
+	return shared.Transaction{
+		ID:           formatID(trade.ID),
+		UserID:       formatID(trade.UserID),
+		Type:         shared.TransactionType(trade.Type),
+		CurrencyPair: trade.CurrencyPair,
+		Amount:       trade.Amount,
+		ExchangeRate: trade.ExchangeRate,
+		TotalValue:   trade.TotalValue,
+		Status:       string(trade.Status),
+		Timestamp:    trade.RequestedAt,
+		QuotationID:  trade.QuotationID,
+	}
+}
+
+// ErrInvalidExternalID is returned when a user from another service has an
+// empty or decimal ID. Decimal IDs would be mistaken for ours in the
+// transaction requests that reference the user.
+var ErrInvalidExternalID = errors.New("invalid external id")
+
+// UserFromMessage maps a user from the users queue. The message's ID
+// becomes the user's external ID; the user gets an ID of its own when it
+// is stored. Balances belong to accounts, so the message's balance is not
+// part of the user.
+func UserFromMessage(msg shared.User) (*models.User, error) {
+	if _, err := parseID(msg.ID); msg.ID == "" || err == nil {
+		return nil, ErrInvalidExternalID
+	}
+	user := &models.User{
+		ExternalID: msg.ID,
+		Email:      msg.Email,
+		CreatedAt:  msg.CreatedAt,
+		UpdatedAt:  msg.UpdatedAt,
+	}
+	user.SetName(msg.Name)
+	return user, nil
+}
+
+// BudgetAlertToMessage maps a budget status whose spending reached
+// threshold to the alert published on the budget alerts queue
+func BudgetAlertToMessage(status *models.BudgetStatus, threshold int) shared.BudgetAlert {
+	return shared.BudgetAlert{
+		BudgetID:   formatID(status.Budget.ID),
+		UserID:     formatID(status.Budget.UserID),
+		CategoryID: formatID(status.Budget.CategoryID),
+		Month:      status.Month.Format("2006-01"),
+		Currency:   status.Budget.Currency,
+		Limit:      status.Budget.Limit,
+		Spent:      status.Spent,
+		Threshold:  threshold,
+		OccurredAt: time.Now(),
+	}
+}
+
+// Quote is a stored quote as published on the quotation updates queue, in
+// the format used by AwesomeAPI
+type Quote struct {
+	ID         string    `json:"id"`
+	Code       string    `json:"code"`
+	Codein     string    `json:"codein"`
+	Name       string    `json:"name"`
+	High       string    `json:"high"`
+	Low        string    `json:"low"`
+	VarBid     string    `json:"varBid"`
+	PctChange  string    `json:"pctChange"`
+	Bid        string    `json:"bid"`
+	Ask        string    `json:"ask"`
+	Timestamp  string    `json:"timestamp"`
+	CreateDate string    `json:"create_date"`
+	QuotedAt   time.Time `json:"quoted_at"`
+}
+
+// NewQuote maps a quote to the quotation updates format
+func NewQuote(c *models.Currency) Quote {
+	return Quote{
+		ID:         c.ID,
+		Code:       c.Code,
+		Codein:     c.Codein,
+		Name:       c.Name,
+		High:       c.High,
+		Low:        c.Low,
+		VarBid:     c.VarBid,
+		PctChange:  c.PctChange,
+		Bid:        c.Bid,
+		Ask:        c.Ask,
+		Timestamp:  c.Timestamp,
+		CreateDate: c.CreateDate,
+		QuotedAt:   c.QuotedAt,
+	}
+}
+
+func parseID(s string) (uint, error) {
+	id, err := strconv.ParseUint(s, 10, 64)
+	if err != nil {
+		return 0, ErrInvalidID
+	}
+	return uint(id), nil
+}
+
+func formatID(id uint) string {
+	if id == 0 {
+		return ""
+	}
+	return strconv.FormatUint(uint64(id), 10)
+}
@@ -0,0 +1,88 @@
+package database
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/gocql/gocql"
+)
+
+// cassandraSchema creates the tables in the keyspace. Statements must be
+// idempotent, as they run on every start.
+var cassandraSchema = []string{
+	`CREATE TABLE IF NOT EXISTS transactions_by_user (
+		user_id bigint,
+		requested_at timestamp,
+		trade_id bigint,
+		correlation_id text,
+		type text,
+		currency_pair text,
+		amount double,
+		exchange_rate double,
+		total_value double,
+		quotation_id text,
+		status text,
+		reason text,
+		PRIMARY KEY ((user_id), requested_at, trade_id)
+	) WITH CLUSTERING ORDER BY (requested_at DESC, trade_id DESC)`,
+}
+
+// NewCassandraSession connects to the comma separated CASSANDRA_HOSTS and
+// bootstraps CASSANDRA_KEYSPACE and its tables if they don't exist
+func NewCassandraSession() (*gocql.Session, error) {
+	hosts := strings.Split(os.Getenv("CASSANDRA_HOSTS"), ",")
+	keyspace := os.Getenv("CASSANDRA_KEYSPACE")
+	if keyspace == "" {
+		keyspace = "banco"
+	}
+
+	if err := bootstrapKeyspace(hosts, keyspace); err != nil {
+		return nil, err
+	}
+
+	cluster := gocql.NewCluster(hosts...)
+	cluster.Keyspace = keyspace
+	cluster.Consistency = gocql.Quorum
+	cluster.Timeout = 10 * time.Second
+
+	session, err := cluster.CreateSession()
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to Cassandra: %w", err)
+	}
+
+	for _, stmt := range cassandraSchema {
+		if err := session.Query(stmt).Exec(); err != nil {
+			session.Close()
+			return nil, fmt.Errorf("failed to create Cassandra schema: %w", err)
+		}
+	}
+
+	return session, nil
+}
+
+// bootstrapKeyspace creates the keyspace, which has to exist before a
+// session can be bound to it
+func bootstrapKeyspace(hosts []string, keyspace string) error {
+	cluster := gocql.NewCluster(hosts...)
+	cluster.Timeout = 10 * time.Second
+
+	session, err := cluster.CreateSession()
+	if err != nil {
+		return fmt.Errorf("failed to connect to Cassandra: %w", err)
+	}
+	defer session.Close()
+
+	replication := os.Getenv("CASSANDRA_REPLICATION_FACTOR")
+	if replication == "" {
+		replication = "1"
+	}
+
+	stmt := fmt.Sprintf(`CREATE KEYSPACE IF NOT EXISTS %s
+		WITH replication = {'class': 'SimpleStrategy', 'replication_factor': %s}`, keyspace, replication)
+	if err := session.Query(stmt).Exec(); err != nil {
+		return fmt.Errorf("failed to create Cassandra keyspace: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,290 @@
+// Package migrations versions the PostgreSQL schema of s2-processor.
+//
+// Each migration is a pair of files in sql/ named NNNN_description.up.sql and
+// NNNN_description.down.sql, where NNNN is its version. Migrations are applied
+// in version order and recorded in the schema_migrations table.
+package migrations
+
+import (
+	"context"
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+//go:embed sql/*.sql
+var files embed.FS
+
+// lockID serializes migration runs across processes
+const lockID = 7265431
+
+// ErrOutdatedSchema is returned by Check when migrations are pending
+var ErrOutdatedSchema = errors.New("database schema is outdated")
+
+// Migration is one versioned schema change
+type Migration struct {
+	Version int
+	Name    string
+	Up      string
+	Down    string
+}
+
+// AppliedMigration is a row of the schema_migrations table
+type AppliedMigration struct {
+	Version   int       `gorm:"primaryKey;autoIncrement:false"`
+	Name      string    `gorm:"not null"`
+	AppliedAt time.Time `gorm:"not null"`
+}
+
+func (AppliedMigration) TableName() string {
+	return "schema_migrations"
+}
+
+// Status describes a migration and whether it was applied
+type Status struct {
+	Migration
+	AppliedAt *time.Time
+}
+
+// Migrator applies and reverts migrations on a database
+type Migrator struct {
+	db         *gorm.DB
+	migrations []Migration
+}
+
+// NewMigrator creates a migrator for the embedded migrations
+func NewMigrator(db *gorm.DB) (*Migrator, error) {
+	migrations, err := load(files)
+	if err != nil {
+		return nil, err
+	}
+	return &Migrator{db: db, migrations: migrations}, nil
+}
+
+// Latest returns the version of the newest known migration
+func (m *Migrator) Latest() int {
+	if len(m.migrations) == 0 {
+		return 0
+	}
+	return m.migrations[len(m.migrations)-1].Version
+}
+
+// Current returns the version of the newest applied migration, or 0 if none
+func (m *Migrator) Current(ctx context.Context) (int, error) {
+	applied, err := m.applied(ctx, m.db)
+	if err != nil {
+		return 0, err
+	}
+	current := 0
+	for version := range applied {
+		if version > current {
+			current = version
+		}
+	}
+	return current, nil
+}
+
+// Up applies every pending migration in order. Each migration runs in its
+// own transaction together with its schema_migrations row.
+func (m *Migrator) Up(ctx context.Context) ([]Migration, error) {
+	var done []Migration
+	for _, migration := range m.migrations {
+		ran, err := m.run(ctx, func(tx *gorm.DB, applied map[int]time.Time) (bool, error) {
+			if _, ok := applied[migration.Version]; ok {
+				return false, nil
+			}
+			if err := tx.Exec(migration.Up).Error; err != nil {
+				return false, err
+			}
+			return true, tx.Create(&AppliedMigration{
+				Version:   migration.Version,
+				Name:      migration.Name,
+				AppliedAt: time.Now(),
+			}).Error
+		})
+		if err != nil {
+			return done, fmt.Errorf("migration %04d_%s: %w", migration.Version, migration.Name, err)
+		}
+		if ran {
+			done = append(done, migration)
+		}
+	}
+	return done, nil
+}
+
+// Down reverts the last steps applied migrations, newest first
+func (m *Migrator) Down(ctx context.Context, steps int) ([]Migration, error) {
+	var done []Migration
+	for i := 0; i < steps; i++ {
+		var reverted *Migration
+		_, err := m.run(ctx, func(tx *gorm.DB, applied map[int]time.Time) (bool, error) {
+			for j := len(m.migrations) - 1; j >= 0; j-- {
+				migration := m.migrations[j]
+				if _, ok := applied[migration.Version]; !ok {
+					continue
+				}
+				if err := tx.Exec(migration.Down).Error; err != nil {
+					return false, fmt.Errorf("migration %04d_%s: %w", migration.Version, migration.Name, err)
+				}
+				reverted = &migration
+				return true, tx.Delete(&AppliedMigration{}, migration.Version).Error
+			}
+			return false, nil
+		})
+		if err != nil {
+			return done, err
+		}
+		if reverted == nil {
+			break
+		}
+		done = append(done, *reverted)
+	}
+	return done, nil
+}
+
+// Status lists every known migration with the time it was applied
+func (m *Migrator) Status(ctx context.Context) ([]Status, error) {
+	applied, err := m.applied(ctx, m.db)
+	if err != nil {
+		return nil, err
+	}
+	statuses := make([]Status, len(m.migrations))
+	for i, migration := range m.migrations {
+		statuses[i].Migration = migration
+		if appliedAt, ok := applied[migration.Version]; ok {
+			statuses[i].AppliedAt = &appliedAt
+		}
+	}
+	return statuses, nil
+}
+
+// Check returns ErrOutdatedSchema if any known migration is not applied.
+// Services call it on startup so they never run against a schema older than
+// the code expects.
+func (m *Migrator) Check(ctx context.Context) error {
+	applied, err := m.applied(ctx, m.db)
+	if err != nil {
+		return err
+	}
+	var pending []string
+	for _, migration := range m.migrations {
+		if _, ok := applied[migration.Version]; !ok {
+			pending = append(pending, fmt.Sprintf("%04d_%s", migration.Version, migration.Name))
+		}
+	}
+	if len(pending) > 0 {
+		return fmt.Errorf("%w: pending migrations %s", ErrOutdatedSchema, strings.Join(pending, ", "))
+	}
+	return nil
+}
+
+// run calls fn in a transaction holding the migration lock, passing the
+// migrations applied at that point
+func (m *Migrator) run(ctx context.Context, fn func(tx *gorm.DB, applied map[int]time.Time) (bool, error)) (bool, error) {
+	ran := false
+	err := m.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("SELECT pg_advisory_xact_lock(?)", lockID).Error; err != nil {
+			return err
+		}
+		if err := tx.Exec(`CREATE TABLE IF NOT EXISTS schema_migrations (
+			version BIGINT PRIMARY KEY,
+			name TEXT NOT NULL,
+			applied_at TIMESTAMPTZ NOT NULL
+		)`).Error; err != nil {
+			return err
+		}
+		applied, err := m.applied(ctx, tx)
+		if err != nil {
+			return err
+		}
+		ran, err = fn(tx, applied)
+		return err
+	})
+	return ran, err
+}
+
+// applied returns the applied migration versions and when they were applied.
+// A database that was never migrated has none.
+func (m *Migrator) applied(ctx context.Context, db *gorm.DB) (map[int]time.Time, error) {
+	applied := make(map[int]time.Time)
+	if !db.Migrator().HasTable(&AppliedMigration{}) {
+		return applied, nil
+	}
+
+	var rows []AppliedMigration
+	if err := db.WithContext(ctx).Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	for _, row := range rows {
+		applied[row.Version] = row.AppliedAt
+	}
+	return applied, nil
+}
+
+// load reads the migrations in fsys and sorts them by version. Every version
+// needs both an up and a down file.
+func load(fsys fs.FS) ([]Migration, error) {
+	names, err := fs.Glob(fsys, "sql/*.sql")
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := make(map[int]*Migration)
+	for _, name := range names {
+		base := path.Base(name)
+		var direction string
+		switch {
+		case strings.HasSuffix(base, ".up.sql"):
+			direction = "up"
+		case strings.HasSuffix(base, ".down.sql"):
+			direction = "down"
+		default:
+			return nil, fmt.Errorf("migration %s: name must end in .up.sql or .down.sql", base)
+		}
+
+		stem := strings.TrimSuffix(base, "."+direction+".sql")
+		prefix, description, ok := strings.Cut(stem, "_")
+		version, err := strconv.Atoi(prefix)
+		if !ok || err != nil || version <= 0 {
+			return nil, fmt.Errorf("migration %s: name must start with a positive version and an underscore", base)
+		}
+
+		body, err := fs.ReadFile(fsys, name)
+		if err != nil {
+			return nil, err
+		}
+
+		migration, exists := byVersion[version]
+		if !exists {
+			migration = &Migration{Version: version, Name: description}
+			byVersion[version] = migration
+		} else if migration.Name != description {
+			return nil, fmt.Errorf("migration %04d: conflicting names %q and %q", version, migration.Name, description)
+		}
+		if direction == "up" {
+			migration.Up = string(body)
+		} else {
+			migration.Down = string(body)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, migration := range byVersion {
+		if migration.Up == "" || migration.Down == "" {
+			return nil, fmt.Errorf("migration %04d_%s: needs both up and down files", migration.Version, migration.Name)
+		}
+		migrations = append(migrations, *migration)
+	}
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+	return migrations, nil
+}
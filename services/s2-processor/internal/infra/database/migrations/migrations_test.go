@@ -0,0 +1,121 @@
+package migrations
+
+import (
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestLoad(t *testing.T) {
+	file := func(body string) *fstest.MapFile {
+		return &fstest.MapFile{Data: []byte(body)}
+	}
+
+	tests := []struct {
+		name     string
+		fsys     fstest.MapFS
+		versions []int
+		err      string
+	}{
+		{name: "empty", fsys: fstest.MapFS{}},
+		{
+			name: "sorted by version",
+			fsys: fstest.MapFS{
+				"sql/0010_b.up.sql":   file("UP 10"),
+				"sql/0010_b.down.sql": file("DOWN 10"),
+				"sql/0002_a.up.sql":   file("UP 2"),
+				"sql/0002_a.down.sql": file("DOWN 2"),
+			},
+			versions: []int{2, 10},
+		},
+		{
+			name: "files outside sql are ignored",
+			fsys: fstest.MapFS{
+				"sql/0001_a.up.sql":   file("UP"),
+				"sql/0001_a.down.sql": file("DOWN"),
+				"0002_b.up.sql":       file("UP"),
+				"sql/README":          file("notes"),
+			},
+			versions: []int{1},
+		},
+		{
+			name: "missing down",
+			fsys: fstest.MapFS{"sql/0001_a.up.sql": file("UP")},
+			err:  "migration 0001_a: needs both up and down files",
+		},
+		{
+			name: "empty up",
+			fsys: fstest.MapFS{
+				"sql/0001_a.up.sql":   file(""),
+				"sql/0001_a.down.sql": file("DOWN"),
+			},
+			err: "migration 0001_a: needs both up and down files",
+		},
+		{
+			name: "unknown direction",
+			fsys: fstest.MapFS{"sql/0001_a.sql": file("UP")},
+			err:  "migration 0001_a.sql: name must end in .up.sql or .down.sql",
+		},
+		{
+			name: "no version",
+			fsys: fstest.MapFS{"sql/create_a.up.sql": file("UP")},
+			err:  "migration create_a.up.sql: name must start with a positive version and an underscore",
+		},
+		{
+			name: "zero version",
+			fsys: fstest.MapFS{"sql/0000_a.up.sql": file("UP")},
+			err:  "migration 0000_a.up.sql: name must start with a positive version and an underscore",
+		},
+		{
+			name: "no description",
+			fsys: fstest.MapFS{"sql/0001.up.sql": file("UP")},
+			err:  "migration 0001.up.sql: name must start with a positive version and an underscore",
+		},
+		{
+			name: "conflicting names",
+			fsys: fstest.MapFS{
+				"sql/0001_a.up.sql":   file("UP"),
+				"sql/0001_b.down.sql": file("DOWN"),
+			},
+			err: "conflicting names",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			migrations, err := load(tt.fsys)
+			if tt.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Fatalf("err = %v, want %q", err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(migrations) != len(tt.versions) {
+				t.Fatalf("loaded %d migrations, want %d", len(migrations), len(tt.versions))
+			}
+			for i, migration := range migrations {
+				if migration.Version != tt.versions[i] {
+					t.Errorf("migration %d has version %d, want %d", i, migration.Version, tt.versions[i])
+				}
+				if migration.Up == "" || migration.Down == "" {
+					t.Errorf("migration %d is missing its up or down SQL", migration.Version)
+				}
+			}
+		})
+	}
+}
+
+func TestEmbeddedMigrations(t *testing.T) {
+	migrations, err := load(files)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, migration := range migrations {
+		if migration.Version != i+1 {
+			t.Errorf("migration %04d_%s: versions should be consecutive from 1, want %04d", migration.Version, migration.Name, i+1)
+		}
+	}
+}
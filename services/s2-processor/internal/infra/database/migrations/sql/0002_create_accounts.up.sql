@@ -0,0 +1,13 @@
+CREATE TABLE accounts (
+    id          BIGSERIAL PRIMARY KEY,
+    user_id     BIGINT NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    name        TEXT NOT NULL,
+    type        TEXT NOT NULL CHECK (type IN ('checking', 'savings', 'credit', 'investment')),
+    balance     NUMERIC(20, 2) NOT NULL DEFAULT 0,
+    currency    CHAR(3) NOT NULL DEFAULT 'BRL',
+    description TEXT NOT NULL DEFAULT '',
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at  TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX idx_accounts_user_id ON accounts (user_id);
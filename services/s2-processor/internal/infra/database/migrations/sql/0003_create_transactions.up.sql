@@ -0,0 +1,13 @@
+CREATE TABLE transactions (
+    id          BIGSERIAL PRIMARY KEY,
+    account_id  BIGINT NOT NULL REFERENCES accounts (id) ON DELETE CASCADE,
+    category_id BIGINT NOT NULL DEFAULT 0,
+    amount      NUMERIC(20, 2) NOT NULL,
+    type        TEXT NOT NULL CHECK (type IN ('income', 'expense', 'transfer')),
+    description TEXT NOT NULL DEFAULT '',
+    date        TIMESTAMPTZ NOT NULL,
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at  TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX idx_transactions_account_id_date ON transactions (account_id, date DESC);
@@ -0,0 +1,3 @@
+DROP TABLE trades;
+DROP TABLE processed_messages;
+DROP TABLE outbox_messages;
@@ -0,0 +1,42 @@
+-- These tables used to be created by gorm's AutoMigrate, so they may already
+-- exist; the names match what AutoMigrate produced.
+CREATE TABLE IF NOT EXISTS outbox_messages (
+    id         BIGSERIAL PRIMARY KEY,
+    message_id TEXT NOT NULL,
+    queue      TEXT NOT NULL,
+    payload    JSONB NOT NULL,
+    status     TEXT NOT NULL DEFAULT 'pending',
+    attempts   BIGINT NOT NULL DEFAULT 0,
+    last_error TEXT,
+    created_at TIMESTAMPTZ,
+    sent_at    TIMESTAMPTZ
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_outbox_messages_message_id ON outbox_messages (message_id);
+CREATE INDEX IF NOT EXISTS idx_outbox_messages_status ON outbox_messages (status);
+
+CREATE TABLE IF NOT EXISTS processed_messages (
+    message_id   TEXT NOT NULL,
+    queue        TEXT NOT NULL,
+    processed_at TIMESTAMPTZ NOT NULL,
+    PRIMARY KEY (message_id, queue)
+);
+
+CREATE TABLE IF NOT EXISTS trades (
+    id             BIGSERIAL PRIMARY KEY,
+    correlation_id TEXT NOT NULL,
+    user_id        BIGINT NOT NULL,
+    type           TEXT NOT NULL,
+    currency_pair  TEXT NOT NULL,
+    amount         NUMERIC NOT NULL,
+    exchange_rate  NUMERIC,
+    total_value    NUMERIC,
+    quotation_id   TEXT,
+    status         TEXT NOT NULL,
+    reason         TEXT,
+    requested_at   TIMESTAMPTZ NOT NULL,
+    created_at     TIMESTAMPTZ
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_trades_correlation_id ON trades (correlation_id);
+CREATE INDEX IF NOT EXISTS idx_trades_user_id ON trades (user_id);
@@ -0,0 +1,11 @@
+-- Dropping deleted_at would bring closed accounts back as open ones, so
+-- refuse while there are any
+DO $$
+BEGIN
+    IF EXISTS (SELECT 1 FROM accounts WHERE deleted_at IS NOT NULL) THEN
+        RAISE EXCEPTION 'cannot revert 0005: closed accounts would be reopened';
+    END IF;
+END $$;
+
+DROP INDEX idx_accounts_deleted_at;
+ALTER TABLE accounts DROP COLUMN deleted_at;
@@ -0,0 +1,3 @@
+ALTER TABLE accounts ADD COLUMN deleted_at TIMESTAMPTZ;
+
+CREATE INDEX idx_accounts_deleted_at ON accounts (deleted_at);
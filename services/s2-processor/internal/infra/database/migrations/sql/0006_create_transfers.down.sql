@@ -0,0 +1,17 @@
+-- Take the transfer legs out of the balances they were added to before
+-- deleting them
+UPDATE accounts
+SET balance = accounts.balance - legs.total
+FROM (
+    SELECT account_id, SUM(amount) AS total
+    FROM transactions
+    WHERE transfer_id IS NOT NULL
+    GROUP BY account_id
+) legs
+WHERE accounts.id = legs.account_id;
+
+DELETE FROM transactions WHERE transfer_id IS NOT NULL;
+
+ALTER TABLE transactions DROP COLUMN transfer_id;
+
+DROP TABLE transfers;
@@ -0,0 +1,16 @@
+CREATE TABLE transfers (
+    id               BIGSERIAL PRIMARY KEY,
+    from_account_id  BIGINT NOT NULL REFERENCES accounts (id),
+    to_account_id    BIGINT NOT NULL REFERENCES accounts (id),
+    amount           NUMERIC(20, 2) NOT NULL CHECK (amount > 0),
+    converted_amount NUMERIC(20, 2) NOT NULL,
+    exchange_rate    NUMERIC NOT NULL,
+    quotation_id     TEXT NOT NULL DEFAULT '',
+    description      TEXT NOT NULL DEFAULT '',
+    created_at       TIMESTAMPTZ NOT NULL DEFAULT now(),
+    CHECK (from_account_id <> to_account_id)
+);
+
+ALTER TABLE transactions ADD COLUMN transfer_id BIGINT REFERENCES transfers (id);
+
+CREATE INDEX idx_transactions_transfer_id ON transactions (transfer_id);
@@ -0,0 +1,7 @@
+DROP INDEX idx_transactions_category_id;
+ALTER TABLE transactions DROP CONSTRAINT fk_transactions_category;
+UPDATE transactions SET category_id = 0 WHERE category_id IS NULL;
+ALTER TABLE transactions ALTER COLUMN category_id SET DEFAULT 0;
+ALTER TABLE transactions ALTER COLUMN category_id SET NOT NULL;
+
+DROP TABLE categories;
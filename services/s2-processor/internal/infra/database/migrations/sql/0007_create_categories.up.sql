@@ -0,0 +1,36 @@
+-- Categories with no user are system defaults, visible to everyone
+CREATE TABLE categories (
+    id         BIGSERIAL PRIMARY KEY,
+    user_id    BIGINT REFERENCES users (id) ON DELETE CASCADE,
+    parent_id  BIGINT REFERENCES categories (id),
+    name       TEXT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX idx_categories_user_id ON categories (user_id);
+CREATE INDEX idx_categories_parent_id ON categories (parent_id);
+CREATE UNIQUE INDEX idx_categories_name ON categories (COALESCE(user_id, 0), COALESCE(parent_id, 0), lower(name));
+
+INSERT INTO categories (name) VALUES
+    ('Housing'), ('Food'), ('Transport'), ('Health'), ('Education'),
+    ('Leisure'), ('Shopping'), ('Salary'), ('Investments'), ('Other');
+
+INSERT INTO categories (parent_id, name)
+SELECT p.id, c.name
+FROM (VALUES
+    ('Housing', 'Rent'), ('Housing', 'Utilities'),
+    ('Food', 'Groceries'), ('Food', 'Restaurants'),
+    ('Transport', 'Fuel'), ('Transport', 'Public transport')
+) AS c (parent, name)
+JOIN categories p ON p.name = c.parent AND p.user_id IS NULL AND p.parent_id IS NULL;
+
+-- Transactions used 0 for "no category"; that becomes NULL so the column
+-- can reference categories
+ALTER TABLE transactions ALTER COLUMN category_id DROP NOT NULL;
+ALTER TABLE transactions ALTER COLUMN category_id DROP DEFAULT;
+UPDATE transactions SET category_id = NULL WHERE category_id = 0;
+ALTER TABLE transactions ADD CONSTRAINT fk_transactions_category
+    FOREIGN KEY (category_id) REFERENCES categories (id) ON DELETE SET NULL;
+
+CREATE INDEX idx_transactions_category_id ON transactions (category_id);
@@ -0,0 +1,15 @@
+CREATE TABLE categorization_rules (
+    id                  BIGSERIAL PRIMARY KEY,
+    user_id             BIGINT NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    category_id         BIGINT NOT NULL REFERENCES categories (id) ON DELETE CASCADE,
+    priority            INTEGER NOT NULL DEFAULT 0,
+    description_pattern TEXT NOT NULL DEFAULT '',
+    match_type          TEXT NOT NULL DEFAULT 'contains' CHECK (match_type IN ('contains', 'equals', 'regex')),
+    min_amount          NUMERIC(20, 2),
+    max_amount          NUMERIC(20, 2),
+    account_id          BIGINT REFERENCES accounts (id) ON DELETE CASCADE,
+    created_at          TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at          TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX idx_categorization_rules_user_id ON categorization_rules (user_id, priority);
@@ -0,0 +1,29 @@
+CREATE TABLE schedules (
+    id               BIGSERIAL PRIMARY KEY,
+    user_id          BIGINT NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    kind             TEXT NOT NULL CHECK (kind IN ('transaction', 'trade')),
+    frequency        TEXT NOT NULL CHECK (frequency IN ('daily', 'weekly', 'monthly')),
+    interval         INTEGER NOT NULL DEFAULT 1 CHECK (interval > 0),
+    start_at         TIMESTAMPTZ NOT NULL,
+    until            TIMESTAMPTZ,
+    count            INTEGER NOT NULL DEFAULT 0 CHECK (count >= 0),
+    amount           NUMERIC(20, 2) NOT NULL CHECK (amount > 0),
+    account_id       BIGINT REFERENCES accounts (id),
+    category_id      BIGINT REFERENCES categories (id) ON DELETE SET NULL,
+    transaction_type TEXT NOT NULL DEFAULT '',
+    description      TEXT NOT NULL DEFAULT '',
+    trade_type       TEXT NOT NULL DEFAULT '',
+    currency_pair    TEXT NOT NULL DEFAULT '',
+    occurrences      INTEGER NOT NULL DEFAULT 0,
+    next_run_at      TIMESTAMPTZ,
+    last_error       TEXT NOT NULL DEFAULT '',
+    created_at       TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at       TIMESTAMPTZ NOT NULL DEFAULT now(),
+    CHECK ((kind = 'transaction' AND account_id IS NOT NULL AND transaction_type IN ('income', 'expense'))
+        OR (kind = 'trade' AND trade_type IN ('BUY', 'SELL') AND currency_pair <> ''))
+);
+
+CREATE INDEX idx_schedules_user_id ON schedules (user_id);
+
+-- The scheduler only looks at schedules that have not ended
+CREATE INDEX idx_schedules_next_run_at ON schedules (next_run_at) WHERE next_run_at IS NOT NULL;
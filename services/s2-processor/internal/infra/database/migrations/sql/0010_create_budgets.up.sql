@@ -0,0 +1,21 @@
+CREATE TABLE budgets (
+    id           BIGSERIAL PRIMARY KEY,
+    user_id      BIGINT NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    category_id  BIGINT NOT NULL REFERENCES categories (id) ON DELETE CASCADE,
+    limit_amount NUMERIC(20, 2) NOT NULL CHECK (limit_amount > 0),
+    currency     CHAR(3) NOT NULL,
+    thresholds   JSONB NOT NULL DEFAULT '[80, 100]',
+    created_at   TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at   TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX idx_budgets_user_id ON budgets (user_id);
+
+-- One row per threshold reached in a month, so each alert is sent once
+CREATE TABLE budget_alerts (
+    budget_id  BIGINT NOT NULL REFERENCES budgets (id) ON DELETE CASCADE,
+    month      DATE NOT NULL,
+    threshold  INTEGER NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (budget_id, month, threshold)
+);
@@ -0,0 +1,25 @@
+-- Take the exchange legs out of the balances they were added to before
+-- deleting them
+UPDATE accounts
+SET balance = accounts.balance - legs.total
+FROM (
+    SELECT account_id, SUM(amount) AS total
+    FROM transactions
+    WHERE trade_id IS NOT NULL
+    GROUP BY account_id
+) legs
+WHERE accounts.id = legs.account_id;
+
+DELETE FROM transactions WHERE trade_id IS NOT NULL;
+
+DROP INDEX idx_transactions_trade_id;
+
+ALTER TABLE transactions DROP CONSTRAINT transactions_type_check;
+ALTER TABLE transactions ADD CONSTRAINT transactions_type_check
+    CHECK (type IN ('income', 'expense', 'transfer'));
+
+ALTER TABLE transactions DROP COLUMN trade_id;
+
+ALTER TABLE trades
+    DROP COLUMN base_account_id,
+    DROP COLUMN quote_account_id;
@@ -0,0 +1,14 @@
+-- Completed trades move money between two accounts of their user, recorded
+-- as a pair of exchange transactions. Trades completed before this
+-- migration were never posted and keep no accounts.
+ALTER TABLE trades
+    ADD COLUMN base_account_id  BIGINT REFERENCES accounts (id),
+    ADD COLUMN quote_account_id BIGINT REFERENCES accounts (id);
+
+ALTER TABLE transactions ADD COLUMN trade_id BIGINT REFERENCES trades (id);
+
+ALTER TABLE transactions DROP CONSTRAINT transactions_type_check;
+ALTER TABLE transactions ADD CONSTRAINT transactions_type_check
+    CHECK (type IN ('income', 'expense', 'transfer', 'exchange'));
+
+CREATE INDEX idx_transactions_trade_id ON transactions (trade_id);
@@ -0,0 +1,17 @@
+-- Dropping deleted_at would bring deleted users back, so refuse while
+-- there are any
+DO $$
+BEGIN
+    IF EXISTS (SELECT 1 FROM users WHERE deleted_at IS NOT NULL) THEN
+        RAISE EXCEPTION 'cannot revert 0012: deleted users would be restored';
+    END IF;
+END $$;
+
+DROP INDEX idx_users_email;
+CREATE UNIQUE INDEX idx_users_email ON users (email);
+
+DROP INDEX idx_users_external_id;
+
+ALTER TABLE users
+    DROP COLUMN deleted_at,
+    DROP COLUMN external_id;
@@ -0,0 +1,12 @@
+-- Users from other services are keyed on the ID they have there, so their
+-- events never reach users registered through the API. Deleted users are
+-- kept, as their accounts may be referenced by other users' transfers.
+ALTER TABLE users
+    ADD COLUMN external_id TEXT,
+    ADD COLUMN deleted_at  TIMESTAMPTZ;
+
+CREATE UNIQUE INDEX idx_users_external_id ON users (external_id);
+
+-- The email of a deleted user can be registered again
+DROP INDEX idx_users_email;
+CREATE UNIQUE INDEX idx_users_email ON users (email) WHERE deleted_at IS NULL;
@@ -0,0 +1,40 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"log"
+	"os"
+
+	"github.com/joho/godotenv"
+	"github.com/leandroalencar/banco-dados/services/s3-validator/internal/audit"
+	"github.com/leandroalencar/banco-dados/services/s3-validator/internal/infra/database"
+)
+
+func init() {
+	if err := godotenv.Load(); err != nil {
+		log.Println("No .env file found")
+	}
+}
+
+// Walks the audit trail and reports the first broken link in the hash chain.
+// Exits with status 1 if the chain does not verify.
+func main() {
+	mongoDB, err := database.ConnectMongoDB()
+	if err != nil {
+		log.Fatalf("Failed to connect to MongoDB: %v", err)
+	}
+
+	count, err := audit.Verify(context.Background(), mongoDB)
+	var broken *audit.BrokenLink
+	if errors.As(err, &broken) {
+		log.Printf("Verified %d records before failure", count)
+		log.Printf("FAIL: %v", broken)
+		os.Exit(1)
+	}
+	if err != nil {
+		log.Fatalf("Failed to verify audit trail: %v", err)
+	}
+
+	log.Printf("OK: %d records verified", count)
+}
@@ -0,0 +1,230 @@
+package anomaly
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/leandroalencar/banco-dados/shared/models"
+)
+
+// Config holds the thresholds used to flag a quotation
+type Config struct {
+	// Window is the number of recent quotes kept per pair
+	Window int
+	// MinSamples is how many quotes a pair needs before the deviation
+	// check applies
+	MinSamples int
+	// MaxSigma is the number of standard deviations from the rolling mean
+	// beyond which a quote is a spike
+	MaxSigma float64
+	// MaxJumpPct is the largest allowed change from the previous quote, in
+	// percent
+	MaxJumpPct float64
+	// MaxAge is how old a quote may be when it arrives
+	MaxAge time.Duration
+	// Quarantine holds back anomalous quotes instead of forwarding them
+	Quarantine bool
+}
+
+// DefaultConfig returns the thresholds used when none are configured
+func DefaultConfig() Config {
+	return Config{
+		Window:     50,
+		MinSamples: 10,
+		MaxSigma:   4,
+		MaxJumpPct: 5,
+		MaxAge:     time.Minute,
+		Quarantine: true,
+	}
+}
+
+// ConfigFromEnv overrides the defaults with ANOMALY_WINDOW,
+// ANOMALY_MIN_SAMPLES, ANOMALY_MAX_SIGMA, ANOMALY_MAX_JUMP_PCT,
+// ANOMALY_MAX_AGE and ANOMALY_QUARANTINE when they are set
+func ConfigFromEnv() (Config, error) {
+	cfg := DefaultConfig()
+	var err error
+
+	if v := os.Getenv("ANOMALY_WINDOW"); v != "" {
+		if cfg.Window, err = strconv.Atoi(v); err != nil {
+			return cfg, fmt.Errorf("invalid ANOMALY_WINDOW: %w", err)
+		}
+	}
+	if v := os.Getenv("ANOMALY_MIN_SAMPLES"); v != "" {
+		if cfg.MinSamples, err = strconv.Atoi(v); err != nil {
+			return cfg, fmt.Errorf("invalid ANOMALY_MIN_SAMPLES: %w", err)
+		}
+	}
+	if v := os.Getenv("ANOMALY_MAX_SIGMA"); v != "" {
+		if cfg.MaxSigma, err = strconv.ParseFloat(v, 64); err != nil {
+			return cfg, fmt.Errorf("invalid ANOMALY_MAX_SIGMA: %w", err)
+		}
+	}
+	if v := os.Getenv("ANOMALY_MAX_JUMP_PCT"); v != "" {
+		if cfg.MaxJumpPct, err = strconv.ParseFloat(v, 64); err != nil {
+			return cfg, fmt.Errorf("invalid ANOMALY_MAX_JUMP_PCT: %w", err)
+		}
+	}
+	if v := os.Getenv("ANOMALY_MAX_AGE"); v != "" {
+		if cfg.MaxAge, err = time.ParseDuration(v); err != nil {
+			return cfg, fmt.Errorf("invalid ANOMALY_MAX_AGE: %w", err)
+		}
+	}
+	if v := os.Getenv("ANOMALY_QUARANTINE"); v != "" {
+		if cfg.Quarantine, err = strconv.ParseBool(v); err != nil {
+			return cfg, fmt.Errorf("invalid ANOMALY_QUARANTINE: %w", err)
+		}
+	}
+
+	return cfg, nil
+}
+
+// series is the rolling window of mid prices for one pair
+type series struct {
+	mids []float64
+	next int
+	last float64
+	// consecutive anomalous quotes since the last normal one
+	anomalies int
+}
+
+func (s *series) add(mid float64, window int) {
+	if len(s.mids) < window {
+		s.mids = append(s.mids, mid)
+	} else {
+		s.mids[s.next] = mid
+		s.next = (s.next + 1) % window
+	}
+	s.last = mid
+}
+
+func (s *series) stats() (mean, stddev float64) {
+	for _, m := range s.mids {
+		mean += m
+	}
+	mean /= float64(len(s.mids))
+	for _, m := range s.mids {
+		stddev += (m - mean) * (m - mean)
+	}
+	return mean, math.Sqrt(stddev / float64(len(s.mids)))
+}
+
+// inspectedCapacity is how many message IDs InspectOnce remembers.
+// Redeliveries arrive shortly after the original, so recent IDs suffice.
+const inspectedCapacity = 10000
+
+// Detector keeps rolling statistics per currency pair and flags quotes that
+// deviate from them
+type Detector struct {
+	cfg Config
+	now func() time.Time
+
+	mu     sync.Mutex
+	series map[string]*series
+
+	// inspected holds the alerts raised for recently inspected message IDs,
+	// which order lists oldest first
+	inspected map[string][]models.QuotationAlert
+	order     []string
+}
+
+// NewDetector creates a detector with the given thresholds
+func NewDetector(cfg Config) *Detector {
+	return &Detector{
+		cfg:       cfg,
+		now:       time.Now,
+		series:    make(map[string]*series),
+		inspected: make(map[string][]models.QuotationAlert),
+	}
+}
+
+// InspectOnce inspects the quotation of message id like Inspect, unless the
+// message was already inspected. A redelivered message gets the alerts of
+// its first inspection and fresh set to false, so it neither adds to the
+// history twice nor raises its alerts again.
+func (d *Detector) InspectOnce(id string, q models.Quotation) (alerts []models.QuotationAlert, fresh bool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if alerts, ok := d.inspected[id]; ok {
+		return alerts, false
+	}
+	alerts = d.inspect(q)
+	if len(d.order) == inspectedCapacity {
+		delete(d.inspected, d.order[0])
+		d.order = d.order[1:]
+	}
+	d.inspected[id] = alerts
+	d.order = append(d.order, id)
+	return alerts, true
+}
+
+// Inspect checks a quotation against its pair's recent history. Only quotes
+// without anomalies are added to the history, so a bad tick cannot drag the
+// statistics towards itself. After MinSamples anomalies in a row the history
+// is discarded, as the market has most likely moved to a new level.
+func (d *Detector) Inspect(q models.Quotation) []models.QuotationAlert {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.inspect(q)
+}
+
+func (d *Detector) inspect(q models.Quotation) []models.QuotationAlert {
+	var alerts []models.QuotationAlert
+	alert := func(kind models.AlertKind, format string, args ...interface{}) {
+		alerts = append(alerts, models.QuotationAlert{
+			Kind:         kind,
+			CurrencyPair: q.CurrencyPair,
+			Detail:       fmt.Sprintf(format, args...),
+			Quotation:    q,
+			Quarantined:  d.cfg.Quarantine,
+			DetectedAt:   d.now(),
+		})
+	}
+
+	if q.BuyPrice > q.SellPrice {
+		alert(models.AlertInvertedSpread, "buy %.6f above sell %.6f", q.BuyPrice, q.SellPrice)
+	}
+	if age := d.now().Sub(q.Timestamp); age > d.cfg.MaxAge {
+		alert(models.AlertStale, "quote is %s old, max %s", age.Round(time.Second), d.cfg.MaxAge)
+	}
+
+	mid := (q.BuyPrice + q.SellPrice) / 2
+	s, ok := d.series[q.CurrencyPair]
+	if !ok {
+		s = &series{}
+		d.series[q.CurrencyPair] = s
+	}
+
+	if len(s.mids) > 0 && s.last > 0 {
+		jump := math.Abs(mid-s.last) / s.last * 100
+		if jump > d.cfg.MaxJumpPct {
+			alert(models.AlertJump, "mid moved %.2f%% from %.6f to %.6f, max %.2f%%", jump, s.last, mid, d.cfg.MaxJumpPct)
+		}
+	}
+	if len(s.mids) >= d.cfg.MinSamples {
+		mean, stddev := s.stats()
+		if stddev > 0 {
+			sigma := math.Abs(mid-mean) / stddev
+			if sigma > d.cfg.MaxSigma {
+				alert(models.AlertSpike, "mid %.6f is %.1fσ from mean %.6f, max %.1fσ", mid, sigma, mean, d.cfg.MaxSigma)
+			}
+		}
+	}
+
+	if len(alerts) == 0 {
+		s.anomalies = 0
+		s.add(mid, d.cfg.Window)
+		return nil
+	}
+
+	s.anomalies++
+	if s.anomalies >= d.cfg.MinSamples {
+		delete(d.series, q.CurrencyPair)
+	}
+	return alerts
+}
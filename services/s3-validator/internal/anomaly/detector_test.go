@@ -0,0 +1,72 @@
+package anomaly
+
+import (
+	"testing"
+	"time"
+
+	"github.com/leandroalencar/banco-dados/shared/models"
+)
+
+func TestInspectOnce(t *testing.T) {
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	quote := func(mid float64) models.Quotation {
+		return models.Quotation{CurrencyPair: "USD/BRL", BuyPrice: mid - 0.01, SellPrice: mid + 0.01, Timestamp: now}
+	}
+
+	tests := []struct {
+		name   string
+		ids    []string
+		mids   []float64
+		alerts []int
+		fresh  []bool
+	}{
+		{
+			name:   "distinct messages",
+			ids:    []string{"a", "b"},
+			mids:   []float64{5, 6},
+			alerts: []int{0, 1},
+			fresh:  []bool{true, true},
+		},
+		{
+			name:   "redelivered anomaly keeps its alerts",
+			ids:    []string{"a", "b", "b"},
+			mids:   []float64{5, 6, 6},
+			alerts: []int{0, 1, 1},
+			fresh:  []bool{true, true, false},
+		},
+		{
+			name:   "redelivered quote is not added to the history twice",
+			ids:    []string{"a", "a", "b"},
+			mids:   []float64{5, 5, 5.1},
+			alerts: []int{0, 0, 0},
+			fresh:  []bool{true, false, true},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := NewDetector(DefaultConfig())
+			d.now = func() time.Time { return now }
+			for i, id := range tt.ids {
+				alerts, fresh := d.InspectOnce(id, quote(tt.mids[i]))
+				if len(alerts) != tt.alerts[i] || fresh != tt.fresh[i] {
+					t.Errorf("InspectOnce(%s) = %d alerts, fresh %v; want %d, %v", id, len(alerts), fresh, tt.alerts[i], tt.fresh[i])
+				}
+			}
+			if n := len(d.series["USD/BRL"].mids); n != len(d.inspected)-countAnomalous(d) {
+				t.Errorf("history has %d quotes, want one per normal message", n)
+			}
+		})
+	}
+}
+
+// countAnomalous counts the inspected messages that raised alerts
+func countAnomalous(d *Detector) int {
+	n := 0
+	for _, alerts := range d.inspected {
+		if len(alerts) > 0 {
+			n++
+		}
+	}
+	return n
+}
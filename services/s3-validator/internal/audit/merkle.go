@@ -0,0 +1,34 @@
+package audit
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+)
+
+// MerkleRoot computes the root of a binary Merkle tree over hex-encoded
+// leaf hashes. An odd node at any level is paired with itself.
+func MerkleRoot(leaves []string) string {
+	if len(leaves) == 0 {
+		return ""
+	}
+
+	level := make([][]byte, len(leaves))
+	for i, leaf := range leaves {
+		level[i], _ = hex.DecodeString(leaf)
+	}
+
+	for len(level) > 1 {
+		next := make([][]byte, 0, (len(level)+1)/2)
+		for i := 0; i < len(level); i += 2 {
+			left, right := level[i], level[i]
+			if i+1 < len(level) {
+				right = level[i+1]
+			}
+			sum := sha256.Sum256(append(append([]byte{}, left...), right...))
+			next = append(next, sum[:])
+		}
+		level = next
+	}
+
+	return hex.EncodeToString(level[0])
+}
@@ -0,0 +1,47 @@
+package audit
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"testing"
+)
+
+func TestMerkleRoot(t *testing.T) {
+	leaf := func(s string) string {
+		sum := sha256.Sum256([]byte(s))
+		return hex.EncodeToString(sum[:])
+	}
+	node := func(left, right string) string {
+		l, _ := hex.DecodeString(left)
+		r, _ := hex.DecodeString(right)
+		sum := sha256.Sum256(append(l, r...))
+		return hex.EncodeToString(sum[:])
+	}
+	a, b, c, d, e := leaf("a"), leaf("b"), leaf("c"), leaf("d"), leaf("e")
+
+	tests := []struct {
+		name   string
+		leaves []string
+		want   string
+	}{
+		{name: "no leaves", leaves: nil, want: ""},
+		{name: "one leaf is its own root", leaves: []string{a}, want: a},
+		{name: "two leaves", leaves: []string{a, b}, want: node(a, b)},
+		{name: "odd leaf paired with itself", leaves: []string{a, b, c}, want: node(node(a, b), node(c, c))},
+		{name: "four leaves", leaves: []string{a, b, c, d}, want: node(node(a, b), node(c, d))},
+		{
+			name:   "odd node on an upper level",
+			leaves: []string{a, b, c, d, e},
+			want:   node(node(node(a, b), node(c, d)), node(node(e, e), node(e, e))),
+		},
+		{name: "order matters", leaves: []string{b, a}, want: node(b, a)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := MerkleRoot(tt.leaves); got != tt.want {
+				t.Errorf("MerkleRoot = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,110 @@
+package audit
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"time"
+)
+
+type Outcome string
+
+const (
+	Accepted    Outcome = "accepted"
+	Rejected    Outcome = "rejected"
+	Quarantined Outcome = "quarantined"
+)
+
+// Envelope holds the broker metadata a message was delivered with
+type Envelope struct {
+	MessageID string `json:"message_id,omitempty" bson:"message_id,omitempty"`
+	// Headers are kept as the JSON they were encoded to on receipt, so the
+	// stored bytes, and therefore the record hash, survive a round trip.
+	Headers     json.RawMessage `json:"headers,omitempty" bson:"headers,omitempty"`
+	SentAt      time.Time       `json:"sent_at,omitempty" bson:"sent_at,omitempty"`
+	Redelivered bool            `json:"redelivered" bson:"redelivered"`
+}
+
+// Record is one observed message in the audit trail. Records form a hash
+// chain: each one stores the hash of its predecessor, so editing, removing or
+// reordering any record breaks every link after it.
+type Record struct {
+	Seq          int64     `json:"seq" bson:"seq"`
+	PrevHash     string    `json:"prev_hash" bson:"prev_hash"`
+	Hash         string    `json:"hash" bson:"hash"`
+	Envelope     Envelope  `json:"envelope" bson:"envelope"`
+	Queue        string    `json:"queue" bson:"queue"`
+	Payload      string    `json:"payload" bson:"payload"`
+	UserID       string    `json:"user_id,omitempty" bson:"user_id,omitempty"`
+	CurrencyPair string    `json:"currency_pair,omitempty" bson:"currency_pair,omitempty"`
+	Outcome      Outcome   `json:"outcome" bson:"outcome"`
+	Violations   []string  `json:"violations,omitempty" bson:"violations,omitempty"`
+	ReceivedAt   time.Time `json:"received_at" bson:"received_at"`
+	// Checkpoint is set on the record closing each checkpoint interval, so
+	// the two are written together. It is derived from hashes, so it is not
+	// part of the record's own hash.
+	Checkpoint *Checkpoint `json:"checkpoint,omitempty" bson:"checkpoint,omitempty"`
+}
+
+// ComputeHash returns the SHA-256 of the record's contents and PrevHash.
+// Times are hashed as Unix milliseconds, the precision MongoDB stores.
+func (r *Record) ComputeHash() string {
+	canonical, _ := json.Marshal(struct {
+		Seq          int64           `json:"seq"`
+		PrevHash     string          `json:"prev_hash"`
+		MessageID    string          `json:"message_id"`
+		Headers      json.RawMessage `json:"headers"`
+		SentAt       int64           `json:"sent_at"`
+		Redelivered  bool            `json:"redelivered"`
+		Queue        string          `json:"queue"`
+		Payload      string          `json:"payload"`
+		UserID       string          `json:"user_id"`
+		CurrencyPair string          `json:"currency_pair"`
+		Outcome      Outcome         `json:"outcome"`
+		Violations   []string        `json:"violations"`
+		ReceivedAt   int64           `json:"received_at"`
+	}{
+		Seq:          r.Seq,
+		PrevHash:     r.PrevHash,
+		MessageID:    r.Envelope.MessageID,
+		Headers:      r.Envelope.Headers,
+		SentAt:       r.Envelope.SentAt.UnixMilli(),
+		Redelivered:  r.Envelope.Redelivered,
+		Queue:        r.Queue,
+		Payload:      r.Payload,
+		UserID:       r.UserID,
+		CurrencyPair: r.CurrencyPair,
+		Outcome:      r.Outcome,
+		Violations:   r.Violations,
+		ReceivedAt:   r.ReceivedAt.UnixMilli(),
+	})
+	sum := sha256.Sum256(canonical)
+	return hex.EncodeToString(sum[:])
+}
+
+// Checkpoint is the Merkle root over the hashes of a contiguous range of
+// records, letting an auditor vouch for a segment without the whole chain.
+type Checkpoint struct {
+	FromSeq   int64     `json:"from_seq" bson:"from_seq"`
+	ToSeq     int64     `json:"to_seq" bson:"to_seq"`
+	Root      string    `json:"root" bson:"root"`
+	CreatedAt time.Time `json:"created_at" bson:"created_at"`
+}
+
+// Head is the sequence number and hash of the last record in the chain. It
+// is stored apart from the records, so removing records from the end of the
+// chain, which leaves the remaining links intact, is still detected.
+type Head struct {
+	Seq  int64  `json:"seq" bson:"seq"`
+	Hash string `json:"hash" bson:"hash"`
+}
+
+// Filter selects audit records. Zero-valued fields are ignored.
+type Filter struct {
+	UserID       string
+	CurrencyPair string
+	Outcome      Outcome
+	From         time.Time
+	To           time.Time
+	Limit        int64
+}
@@ -0,0 +1,279 @@
+package audit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	defaultLimit = 100
+
+	// CheckpointInterval is the number of records covered by each Merkle
+	// checkpoint.
+	CheckpointInterval = 1000
+
+	// appendRetries bounds how often Append reloads the chain head after
+	// losing a race with another writer.
+	appendRetries = 3
+)
+
+// headID is the _id of the chain head document
+const headID = "head"
+
+// Store persists audit records in MongoDB as an append-only hash chain.
+// Records are keyed by message ID, so a redelivered message is recorded
+// once. Appends are serialized; a unique index on seq makes a second writer
+// fail instead of forking the chain.
+type Store struct {
+	records *mongo.Collection
+	head    *mongo.Collection
+
+	mu       sync.Mutex
+	lastSeq  int64
+	lastHash string
+	// hashes of the records appended since the last checkpoint
+	pending []string
+}
+
+// NewStore creates an audit store, ensures its indexes exist and loads the
+// current head of the chain. It fails if records are missing from the end
+// of the chain.
+func NewStore(ctx context.Context, db *mongo.Database) (*Store, error) {
+	s := &Store{
+		records: db.Collection("audit_trail"),
+		head:    db.Collection("audit_head"),
+	}
+
+	_, err := s.records.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "seq", Value: 1}}, Options: options.Index().SetUnique(true)},
+		{
+			Keys: bson.D{{Key: "envelope.message_id", Value: 1}},
+			Options: options.Index().SetUnique(true).
+				SetPartialFilterExpression(bson.M{"envelope.message_id": bson.M{"$exists": true}}),
+		},
+		{Keys: bson.D{{Key: "received_at", Value: -1}}},
+		{Keys: bson.D{{Key: "user_id", Value: 1}, {Key: "received_at", Value: -1}}},
+		{Keys: bson.D{{Key: "currency_pair", Value: 1}, {Key: "received_at", Value: -1}}},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.loadHead(ctx); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// loadHead reads the last record and the hashes not yet covered by a
+// checkpoint, and brings the stored head up to the last record if a crash
+// left it behind
+func (s *Store) loadHead(ctx context.Context) error {
+	s.lastSeq, s.lastHash, s.pending = 0, "", nil
+
+	head, err := LoadHead(ctx, s.head)
+	if err != nil {
+		return err
+	}
+
+	var last Record
+	err = s.records.FindOne(ctx, bson.M{}, options.FindOne().SetSort(bson.D{{Key: "seq", Value: -1}})).Decode(&last)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		if head != nil && head.Seq > 0 {
+			return fmt.Errorf("audit chain head is at seq %d but no records exist", head.Seq)
+		}
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if head != nil && head.Seq > last.Seq {
+		return fmt.Errorf("audit chain head is at seq %d but the last record is %d", head.Seq, last.Seq)
+	}
+	s.lastSeq, s.lastHash = last.Seq, last.Hash
+	if err := s.advanceHead(ctx, &last); err != nil {
+		return err
+	}
+
+	covered := int64(0)
+	var checkpointed Record
+	err = s.records.FindOne(ctx,
+		bson.M{"checkpoint": bson.M{"$exists": true}},
+		options.FindOne().SetSort(bson.D{{Key: "seq", Value: -1}}).SetProjection(bson.M{"seq": 1})).Decode(&checkpointed)
+	if err == nil {
+		covered = checkpointed.Seq
+	} else if !errors.Is(err, mongo.ErrNoDocuments) {
+		return err
+	}
+
+	cursor, err := s.records.Find(ctx,
+		bson.M{"seq": bson.M{"$gt": covered}},
+		options.Find().SetSort(bson.D{{Key: "seq", Value: 1}}).SetProjection(bson.M{"hash": 1}))
+	if err != nil {
+		return err
+	}
+	defer cursor.Close(ctx)
+
+	for cursor.Next(ctx) {
+		var r struct {
+			Hash string `bson:"hash"`
+		}
+		if err := cursor.Decode(&r); err != nil {
+			return err
+		}
+		s.pending = append(s.pending, r.Hash)
+	}
+	return cursor.Err()
+}
+
+// LoadHead reads the stored chain head, returning nil if there is none
+func LoadHead(ctx context.Context, collection *mongo.Collection) (*Head, error) {
+	var head Head
+	err := collection.FindOne(ctx, bson.M{"_id": headID}).Decode(&head)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &head, nil
+}
+
+// advanceHead moves the stored head to record unless it is already there or
+// further along
+func (s *Store) advanceHead(ctx context.Context, record *Record) error {
+	_, err := s.head.UpdateOne(ctx,
+		bson.M{"_id": headID, "seq": bson.M{"$lt": record.Seq}},
+		bson.M{"$set": bson.M{"seq": record.Seq, "hash": record.Hash}},
+		options.Update().SetUpsert(true))
+	if mongo.IsDuplicateKeyError(err) {
+		// The filter missed because the head is already past record
+		return nil
+	}
+	return err
+}
+
+// Append links record to the end of the chain and stores it, together with
+// a checkpoint if it closes an interval. A record whose message ID was
+// already recorded is replaced by the stored one instead of being appended
+// again.
+func (s *Store) Append(ctx context.Context, record *Record) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// MongoDB keeps millisecond precision; truncate so the stored record
+	// matches the one that was hashed.
+	record.ReceivedAt = record.ReceivedAt.Truncate(time.Millisecond)
+	record.Envelope.SentAt = record.Envelope.SentAt.Truncate(time.Millisecond)
+
+	for attempt := 0; ; attempt++ {
+		if stored, err := s.recorded(ctx, record.Envelope.MessageID); err != nil || stored != nil {
+			if stored != nil {
+				*record = *stored
+				err = s.advanceHead(ctx, stored)
+			}
+			return err
+		}
+
+		record.Seq = s.lastSeq + 1
+		record.PrevHash = s.lastHash
+		record.Hash = record.ComputeHash()
+		record.Checkpoint = nil
+		if len(s.pending)+1 >= CheckpointInterval {
+			record.Checkpoint = &Checkpoint{
+				FromSeq:   record.Seq - int64(len(s.pending)),
+				ToSeq:     record.Seq,
+				Root:      MerkleRoot(append(s.pending, record.Hash)),
+				CreatedAt: time.Now().Truncate(time.Millisecond),
+			}
+		}
+
+		_, err := s.records.InsertOne(ctx, record)
+		if err == nil {
+			break
+		}
+		if !mongo.IsDuplicateKeyError(err) || attempt == appendRetries {
+			return err
+		}
+		// Another writer extended the chain, possibly with this message;
+		// continue from its head
+		if err := s.loadHead(ctx); err != nil {
+			return err
+		}
+	}
+
+	s.lastSeq, s.lastHash = record.Seq, record.Hash
+	s.pending = append(s.pending, record.Hash)
+	if record.Checkpoint != nil {
+		s.pending = nil
+	}
+	return s.advanceHead(ctx, record)
+}
+
+// recorded returns the record of messageID, or nil if it has none
+func (s *Store) recorded(ctx context.Context, messageID string) (*Record, error) {
+	if messageID == "" {
+		return nil, nil
+	}
+	var record Record
+	err := s.records.FindOne(ctx, bson.M{"envelope.message_id": messageID}).Decode(&record)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &record, nil
+}
+
+// Find returns the records matching filter, newest first
+func (s *Store) Find(ctx context.Context, filter Filter) ([]Record, error) {
+	query := bson.M{}
+	if filter.UserID != "" {
+		query["user_id"] = filter.UserID
+	}
+	if filter.CurrencyPair != "" {
+		query["currency_pair"] = filter.CurrencyPair
+	}
+	if filter.Outcome != "" {
+		query["outcome"] = filter.Outcome
+	}
+	if !filter.From.IsZero() || !filter.To.IsZero() {
+		received := bson.M{}
+		if !filter.From.IsZero() {
+			received["$gte"] = filter.From
+		}
+		if !filter.To.IsZero() {
+			received["$lte"] = filter.To
+		}
+		query["received_at"] = received
+	}
+
+	limit := filter.Limit
+	if limit <= 0 {
+		limit = defaultLimit
+	}
+
+	opts := options.Find().
+		SetSort(bson.D{{Key: "received_at", Value: -1}}).
+		SetLimit(limit)
+
+	cursor, err := s.records.Find(ctx, query, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	records := []Record{}
+	if err := cursor.All(ctx, &records); err != nil {
+		return nil, err
+	}
+	return records, nil
+}
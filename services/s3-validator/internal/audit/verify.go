@@ -0,0 +1,107 @@
+package audit
+
+import (
+	"context"
+	"fmt"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// BrokenLink describes the first point where the chain fails verification
+type BrokenLink struct {
+	Seq    int64
+	Reason string
+}
+
+func (b *BrokenLink) Error() string {
+	return fmt.Sprintf("audit chain broken at seq %d: %s", b.Seq, b.Reason)
+}
+
+// Verify walks the audit trail in sequence order, recomputing every hash and
+// checkpoint, and checks that the chain reaches the stored head. It returns
+// the number of records verified and a *BrokenLink describing the first
+// inconsistency, if any.
+func Verify(ctx context.Context, db *mongo.Database) (int64, error) {
+	// Read the head first: records appended while verifying only extend the
+	// chain past it
+	head, err := LoadHead(ctx, db.Collection("audit_head"))
+	if err != nil {
+		return 0, err
+	}
+
+	cursor, err := db.Collection("audit_trail").Find(ctx, bson.M{}, options.Find().SetSort(bson.D{{Key: "seq", Value: 1}}))
+	if err != nil {
+		return 0, err
+	}
+	defer cursor.Close(ctx)
+
+	v := &chainVerifier{head: head}
+	for cursor.Next(ctx) {
+		var record Record
+		if err := cursor.Decode(&record); err != nil {
+			return v.count, err
+		}
+		if err := v.add(&record); err != nil {
+			return v.count, err
+		}
+	}
+	if err := cursor.Err(); err != nil {
+		return v.count, err
+	}
+	return v.count, v.finish()
+}
+
+// chainVerifier checks records passed to add in sequence order
+type chainVerifier struct {
+	head *Head
+
+	prev    Record
+	count   int64
+	segment []string
+	// reachedHead is set once the record at the head's seq was seen
+	reachedHead bool
+}
+
+func (v *chainVerifier) add(record *Record) error {
+	expectedSeq := v.prev.Seq + 1
+	switch {
+	case record.Seq != expectedSeq:
+		return &BrokenLink{Seq: expectedSeq, Reason: fmt.Sprintf("record missing, next stored seq is %d", record.Seq)}
+	case record.PrevHash != v.prev.Hash:
+		return &BrokenLink{Seq: record.Seq, Reason: "prev_hash does not match hash of the previous record"}
+	case record.Hash != record.ComputeHash():
+		return &BrokenLink{Seq: record.Seq, Reason: "hash does not match record contents"}
+	}
+
+	v.segment = append(v.segment, record.Hash)
+	if c := record.Checkpoint; c != nil {
+		if c.ToSeq != record.Seq || c.FromSeq != record.Seq-int64(len(v.segment))+1 || MerkleRoot(v.segment) != c.Root {
+			return &BrokenLink{Seq: record.Seq, Reason: fmt.Sprintf("checkpoint %d-%d root does not match records", c.FromSeq, c.ToSeq)}
+		}
+		v.segment = nil
+	}
+
+	if v.head != nil && record.Seq == v.head.Seq {
+		if record.Hash != v.head.Hash {
+			return &BrokenLink{Seq: record.Seq, Reason: "hash does not match the stored chain head"}
+		}
+		v.reachedHead = true
+	}
+
+	v.prev = *record
+	v.count++
+	return nil
+}
+
+// finish checks that the records verified so far reach the chain head
+func (v *chainVerifier) finish() error {
+	switch {
+	case v.head == nil && v.count > 0:
+		return &BrokenLink{Seq: v.prev.Seq, Reason: "chain head is missing"}
+	case v.head != nil && v.head.Seq > 0 && !v.reachedHead:
+		return &BrokenLink{Seq: v.prev.Seq + 1, Reason: fmt.Sprintf("records up to the chain head at seq %d are missing", v.head.Seq)}
+	}
+	return nil
+}
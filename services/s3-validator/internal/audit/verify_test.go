@@ -0,0 +1,141 @@
+package audit
+
+import (
+	"errors"
+	"strconv"
+	"testing"
+	"time"
+)
+
+// chain builds n linked records, with a checkpoint every interval records
+func chain(n, interval int) []Record {
+	records := make([]Record, n)
+	var prevHash string
+	var segment []string
+	for i := range records {
+		r := &records[i]
+		r.Seq = int64(i + 1)
+		r.PrevHash = prevHash
+		r.Envelope.MessageID = "m" + strconv.Itoa(i+1)
+		r.Queue = "quotations"
+		r.Payload = `{"currency_pair":"USD/BRL"}`
+		r.Outcome = Accepted
+		r.ReceivedAt = time.Date(2026, 1, 1, 0, 0, i, 0, time.UTC)
+		r.Hash = r.ComputeHash()
+		prevHash = r.Hash
+
+		segment = append(segment, r.Hash)
+		if interval > 0 && len(segment) == interval {
+			r.Checkpoint = &Checkpoint{FromSeq: r.Seq - int64(interval) + 1, ToSeq: r.Seq, Root: MerkleRoot(segment)}
+			segment = nil
+		}
+	}
+	return records
+}
+
+func TestChainVerifier(t *testing.T) {
+	tests := []struct {
+		name   string
+		mutate func(records []Record, head *Head) ([]Record, *Head)
+		// brokenAt is the seq of the broken link, or 0 if the chain verifies
+		brokenAt int64
+	}{
+		{
+			name:   "intact",
+			mutate: func(records []Record, head *Head) ([]Record, *Head) { return records, head },
+		},
+		{
+			name:   "empty without head",
+			mutate: func([]Record, *Head) ([]Record, *Head) { return nil, nil },
+		},
+		{
+			name: "record appended after the head was read",
+			mutate: func(records []Record, head *Head) ([]Record, *Head) {
+				return records, &Head{Seq: records[4].Seq, Hash: records[4].Hash}
+			},
+		},
+		{
+			name: "edited payload",
+			mutate: func(records []Record, head *Head) ([]Record, *Head) {
+				records[2].Payload = `{"currency_pair":"EUR/BRL"}`
+				return records, head
+			},
+			brokenAt: 3,
+		},
+		{
+			name: "removed record",
+			mutate: func(records []Record, head *Head) ([]Record, *Head) {
+				return append(records[:3], records[4:]...), head
+			},
+			brokenAt: 4,
+		},
+		{
+			name: "rehashed record",
+			mutate: func(records []Record, head *Head) ([]Record, *Head) {
+				records[3].Payload = `{"currency_pair":"EUR/BRL"}`
+				records[3].Hash = records[3].ComputeHash()
+				return records, head
+			},
+			brokenAt: 5,
+		},
+		{
+			name: "truncated tail",
+			mutate: func(records []Record, head *Head) ([]Record, *Head) {
+				return records[:4], head
+			},
+			brokenAt: 5,
+		},
+		{
+			name: "missing head",
+			mutate: func(records []Record, head *Head) ([]Record, *Head) {
+				return records, nil
+			},
+			brokenAt: 6,
+		},
+		{
+			name: "head hash differs",
+			mutate: func(records []Record, head *Head) ([]Record, *Head) {
+				head.Hash = records[0].Hash
+				return records, head
+			},
+			brokenAt: 6,
+		},
+		{
+			name: "forged checkpoint root",
+			mutate: func(records []Record, head *Head) ([]Record, *Head) {
+				records[2].Checkpoint.Root = MerkleRoot([]string{records[0].Hash})
+				return records, head
+			},
+			brokenAt: 3,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			records := chain(6, 3)
+			head := &Head{Seq: records[5].Seq, Hash: records[5].Hash}
+			records, head = tt.mutate(records, head)
+
+			v := &chainVerifier{head: head}
+			var err error
+			for i := range records {
+				if err = v.add(&records[i]); err != nil {
+					break
+				}
+			}
+			if err == nil {
+				err = v.finish()
+			}
+
+			var broken *BrokenLink
+			switch {
+			case tt.brokenAt == 0 && err != nil:
+				t.Errorf("verify error = %v, want none", err)
+			case tt.brokenAt != 0 && !errors.As(err, &broken):
+				t.Errorf("verify error = %v, want broken link at %d", err, tt.brokenAt)
+			case tt.brokenAt != 0 && broken.Seq != tt.brokenAt:
+				t.Errorf("broken at %d (%s), want %d", broken.Seq, broken.Reason, tt.brokenAt)
+			}
+		})
+	}
+}
@@ -0,0 +1,117 @@
+package validator
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/leandroalencar/banco-dados/shared/models"
+)
+
+// DefaultCurrencyPairs are the pairs accepted when none are configured
+var DefaultCurrencyPairs = []string{"USD/BRL", "EUR/BRL", "GBP/BRL", "BTC/BRL"}
+
+const (
+	// clockSkew is how far in the future a timestamp may be before it is
+	// considered invalid, to tolerate small clock differences between hosts.
+	clockSkew = 5 * time.Second
+
+	// totalTolerance is the absolute difference allowed between TotalValue
+	// and Amount × ExchangeRate, covering rounding to cents.
+	totalTolerance = 0.01
+)
+
+// Validator checks messages against the business rules of each type
+type Validator struct {
+	knownPairs map[string]bool
+	now        func() time.Time
+}
+
+// NewValidator creates a validator accepting the given currency pairs
+func NewValidator(pairs []string) *Validator {
+	known := make(map[string]bool, len(pairs))
+	for _, pair := range pairs {
+		known[strings.ToUpper(strings.TrimSpace(pair))] = true
+	}
+	return &Validator{
+		knownPairs: known,
+		now:        time.Now,
+	}
+}
+
+// ValidateQuotation returns the rules violated by a quotation
+func (v *Validator) ValidateQuotation(q models.Quotation) []string {
+	var violations []string
+
+	violations = append(violations, v.checkPair(q.CurrencyPair)...)
+	if q.BuyPrice <= 0 {
+		violations = append(violations, "buy_price must be positive")
+	}
+	if q.SellPrice <= 0 {
+		violations = append(violations, "sell_price must be positive")
+	}
+	if q.BuyPrice > q.SellPrice {
+		violations = append(violations, fmt.Sprintf("buy_price %.6f is greater than sell_price %.6f", q.BuyPrice, q.SellPrice))
+	}
+	violations = append(violations, v.checkTimestamp(q.Timestamp)...)
+
+	return violations
+}
+
+// ValidateTransaction returns the rules violated by a transaction
+func (v *Validator) ValidateTransaction(t models.Transaction) []string {
+	var violations []string
+
+	if t.UserID == "" {
+		violations = append(violations, "user_id is required")
+	}
+	switch t.Type {
+	case models.Buy, models.Sell:
+	case "":
+		violations = append(violations, "type is required")
+	default:
+		violations = append(violations, fmt.Sprintf("unknown type %q", t.Type))
+	}
+	violations = append(violations, v.checkPair(t.CurrencyPair)...)
+	if t.Amount <= 0 {
+		violations = append(violations, "amount must be positive")
+	}
+	if t.ExchangeRate < 0 {
+		violations = append(violations, "exchange_rate must not be negative")
+	}
+	if t.TotalValue < 0 {
+		violations = append(violations, "total_value must not be negative")
+	}
+	// Requests from the generator are not priced yet; only check the total
+	// once a rate has been applied.
+	if t.ExchangeRate > 0 || t.TotalValue > 0 {
+		expected := t.Amount * t.ExchangeRate
+		if math.Abs(t.TotalValue-expected) > totalTolerance {
+			violations = append(violations, fmt.Sprintf("total_value %.2f does not match amount × exchange_rate %.2f", t.TotalValue, expected))
+		}
+	}
+	violations = append(violations, v.checkTimestamp(t.Timestamp)...)
+
+	return violations
+}
+
+func (v *Validator) checkPair(pair string) []string {
+	if pair == "" {
+		return []string{"currency_pair is required"}
+	}
+	if !v.knownPairs[pair] {
+		return []string{fmt.Sprintf("unknown currency_pair %q", pair)}
+	}
+	return nil
+}
+
+func (v *Validator) checkTimestamp(ts time.Time) []string {
+	if ts.IsZero() {
+		return []string{"timestamp is required"}
+	}
+	if ts.After(v.now().Add(clockSkew)) {
+		return []string{fmt.Sprintf("timestamp %s is in the future", ts.Format(time.RFC3339))}
+	}
+	return nil
+}
@@ -0,0 +1,151 @@
+package validator
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/leandroalencar/banco-dados/shared/models"
+)
+
+var now = time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+
+func newTestValidator() *Validator {
+	v := NewValidator([]string{"usd/brl ", "EUR/BRL"})
+	v.now = func() time.Time { return now }
+	return v
+}
+
+func TestValidateQuotation(t *testing.T) {
+	valid := models.Quotation{CurrencyPair: "USD/BRL", BuyPrice: 4.95, SellPrice: 5.05, Timestamp: now}
+
+	tests := []struct {
+		name   string
+		change func(q *models.Quotation)
+		want   []string
+	}{
+		{name: "valid", change: func(q *models.Quotation) {}},
+		{name: "equal prices", change: func(q *models.Quotation) { q.BuyPrice = q.SellPrice }},
+		{name: "within clock skew", change: func(q *models.Quotation) { q.Timestamp = now.Add(clockSkew) }},
+		{
+			name:   "missing pair",
+			change: func(q *models.Quotation) { q.CurrencyPair = "" },
+			want:   []string{"currency_pair is required"},
+		},
+		{
+			name:   "unknown pair",
+			change: func(q *models.Quotation) { q.CurrencyPair = "JPY/BRL" },
+			want:   []string{`unknown currency_pair "JPY/BRL"`},
+		},
+		{
+			name:   "zero buy price",
+			change: func(q *models.Quotation) { q.BuyPrice = 0 },
+			want:   []string{"buy_price must be positive"},
+		},
+		{
+			name:   "negative prices",
+			change: func(q *models.Quotation) { q.BuyPrice, q.SellPrice = -1, -2 },
+			want: []string{
+				"buy_price must be positive",
+				"sell_price must be positive",
+				"buy_price -1.000000 is greater than sell_price -2.000000",
+			},
+		},
+		{
+			name:   "crossed prices",
+			change: func(q *models.Quotation) { q.BuyPrice = 5.10 },
+			want:   []string{"buy_price 5.100000 is greater than sell_price 5.050000"},
+		},
+		{
+			name:   "missing timestamp",
+			change: func(q *models.Quotation) { q.Timestamp = time.Time{} },
+			want:   []string{"timestamp is required"},
+		},
+		{
+			name:   "future timestamp",
+			change: func(q *models.Quotation) { q.Timestamp = now.Add(time.Minute) },
+			want:   []string{"timestamp 2026-01-01T12:01:00Z is in the future"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q := valid
+			tt.change(&q)
+			if got := newTestValidator().ValidateQuotation(q); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("violations = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateTransaction(t *testing.T) {
+	valid := models.Transaction{UserID: "1", Type: models.Buy, CurrencyPair: "EUR/BRL", Amount: 100, Timestamp: now}
+
+	tests := []struct {
+		name   string
+		change func(t *models.Transaction)
+		want   []string
+	}{
+		{name: "unpriced request", change: func(t *models.Transaction) {}},
+		{name: "sell", change: func(t *models.Transaction) { t.Type = models.Sell }},
+		{
+			name:   "priced",
+			change: func(t *models.Transaction) { t.ExchangeRate, t.TotalValue = 5.4321, 543.21 },
+		},
+		{
+			name:   "priced within rounding",
+			change: func(t *models.Transaction) { t.ExchangeRate, t.TotalValue = 5.43214, 543.21 },
+		},
+		{
+			name:   "missing user",
+			change: func(t *models.Transaction) { t.UserID = "" },
+			want:   []string{"user_id is required"},
+		},
+		{
+			name:   "missing type",
+			change: func(t *models.Transaction) { t.Type = "" },
+			want:   []string{"type is required"},
+		},
+		{
+			name:   "unknown type",
+			change: func(t *models.Transaction) { t.Type = "HOLD" },
+			want:   []string{`unknown type "HOLD"`},
+		},
+		{
+			name:   "zero amount",
+			change: func(t *models.Transaction) { t.Amount = 0 },
+			want:   []string{"amount must be positive"},
+		},
+		{
+			name:   "total without rate",
+			change: func(t *models.Transaction) { t.TotalValue = 500 },
+			want:   []string{"total_value 500.00 does not match amount × exchange_rate 0.00"},
+		},
+		{
+			name:   "mispriced total",
+			change: func(t *models.Transaction) { t.ExchangeRate, t.TotalValue = 5, 499.98 },
+			want:   []string{"total_value 499.98 does not match amount × exchange_rate 500.00"},
+		},
+		{
+			name:   "negative rate",
+			change: func(t *models.Transaction) { t.ExchangeRate = -5 },
+			want:   []string{"exchange_rate must not be negative"},
+		},
+		{
+			name:   "future timestamp",
+			change: func(t *models.Transaction) { t.Timestamp = now.Add(clockSkew + time.Second) },
+			want:   []string{"timestamp 2026-01-01T12:00:06Z is in the future"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			transaction := valid
+			tt.change(&transaction)
+			if got := newTestValidator().ValidateTransaction(transaction); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("violations = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,22 @@
+package models
+
+import "time"
+
+type AlertKind string
+
+const (
+	AlertSpike          AlertKind = "SPIKE"
+	AlertJump           AlertKind = "JUMP"
+	AlertInvertedSpread AlertKind = "INVERTED_SPREAD"
+	AlertStale          AlertKind = "STALE"
+)
+
+// QuotationAlert is published when a quotation looks anomalous
+type QuotationAlert struct {
+	Kind         AlertKind `json:"kind" bson:"kind"`
+	CurrencyPair string    `json:"currency_pair" bson:"currency_pair"`
+	Detail       string    `json:"detail" bson:"detail"`
+	Quotation    Quotation `json:"quotation" bson:"quotation"`
+	Quarantined  bool      `json:"quarantined" bson:"quarantined"`
+	DetectedAt   time.Time `json:"detected_at" bson:"detected_at"`
+}
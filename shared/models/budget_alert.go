@@ -0,0 +1,18 @@
+package models
+
+import "time"
+
+// BudgetAlert is published the first time a user's spending in a month
+// reaches a threshold of one of their budgets. Threshold is a percentage of
+// Limit and Month is formatted as YYYY-MM.
+type BudgetAlert struct {
+	BudgetID   string    `json:"budget_id" bson:"budget_id"`
+	UserID     string    `json:"user_id" bson:"user_id"`
+	CategoryID string    `json:"category_id" bson:"category_id"`
+	Month      string    `json:"month" bson:"month"`
+	Currency   string    `json:"currency" bson:"currency"`
+	Limit      float64   `json:"limit" bson:"limit"`
+	Spent      float64   `json:"spent" bson:"spent"`
+	Threshold  int       `json:"threshold" bson:"threshold"`
+	OccurredAt time.Time `json:"occurred_at" bson:"occurred_at"`
+}
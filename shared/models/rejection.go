@@ -0,0 +1,15 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Rejection is published for messages that failed validation
+type Rejection struct {
+	Queue      string          `json:"queue" bson:"queue"`
+	MessageID  string          `json:"message_id,omitempty" bson:"message_id,omitempty"`
+	Payload    json.RawMessage `json:"payload" bson:"payload"`
+	Violations []string        `json:"violations" bson:"violations"`
+	RejectedAt time.Time       `json:"rejected_at" bson:"rejected_at"`
+}
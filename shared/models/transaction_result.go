@@ -0,0 +1,20 @@
+package models
+
+import "time"
+
+type TransactionEvent string
+
+const (
+	TransactionCompleted TransactionEvent = "transaction.completed"
+	TransactionRejected  TransactionEvent = "transaction.rejected"
+)
+
+// TransactionResult is published once a transaction request was processed.
+// CorrelationID is the message ID of the request it answers.
+type TransactionResult struct {
+	Event         TransactionEvent `json:"event" bson:"event"`
+	CorrelationID string           `json:"correlation_id" bson:"correlation_id"`
+	Transaction   Transaction      `json:"transaction" bson:"transaction"`
+	Reason        string           `json:"reason,omitempty" bson:"reason,omitempty"`
+	ProcessedAt   time.Time        `json:"processed_at" bson:"processed_at"`
+}
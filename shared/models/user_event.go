@@ -0,0 +1,19 @@
+package models
+
+import "time"
+
+type UserEventType string
+
+const (
+	UserCreated UserEventType = "user.created"
+	UserUpdated UserEventType = "user.updated"
+	UserDeleted UserEventType = "user.deleted"
+)
+
+// UserEvent describes a change in a user's lifecycle. For deletions only
+// User.ID is meaningful.
+type UserEvent struct {
+	Event      UserEventType `json:"event" bson:"event"`
+	User       User          `json:"user" bson:"user"`
+	OccurredAt time.Time     `json:"occurred_at" bson:"occurred_at"`
+}
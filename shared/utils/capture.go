@@ -0,0 +1,111 @@
+package utils
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// CapturedMessage is one line of a capture file
+type CapturedMessage struct {
+	Queue      string          `json:"queue"`
+	MessageID  string          `json:"message_id,omitempty"`
+	CapturedAt time.Time       `json:"captured_at"`
+	Body       json.RawMessage `json:"body"`
+}
+
+// Capture appends messages to a JSON lines file
+type Capture struct {
+	mu      sync.Mutex
+	file    *os.File
+	encoder *json.Encoder
+	// queues holds the captured queues; empty means all of them
+	queues map[string]bool
+}
+
+// NewCapture opens path for appending and records messages on queues, or
+// on every queue if none is named
+func NewCapture(path string, queues []string) (*Capture, error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return nil, err
+	}
+
+	set := make(map[string]bool, len(queues))
+	for _, q := range queues {
+		if q = strings.TrimSpace(q); q != "" {
+			set[q] = true
+		}
+	}
+
+	return &Capture{
+		file:    file,
+		encoder: json.NewEncoder(file),
+		queues:  set,
+	}, nil
+}
+
+// Record writes a message if its queue is captured
+func (c *Capture) Record(queue, messageID string, body []byte) error {
+	if len(c.queues) > 0 && !c.queues[queue] {
+		return nil
+	}
+
+	raw := json.RawMessage(body)
+	if !json.Valid(body) {
+		raw, _ = json.Marshal(string(body))
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.encoder.Encode(CapturedMessage{
+		Queue:      queue,
+		MessageID:  messageID,
+		CapturedAt: time.Now(),
+		Body:       raw,
+	})
+}
+
+// Close closes the capture file
+func (c *Capture) Close() error {
+	return c.file.Close()
+}
+
+// CaptureReader reads a capture file line by line
+type CaptureReader struct {
+	scanner *bufio.Scanner
+	line    int
+}
+
+// NewCaptureReader reads captured messages from r
+func NewCaptureReader(r io.Reader) *CaptureReader {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	return &CaptureReader{scanner: scanner}
+}
+
+// Next returns the next message, or io.EOF at the end of the file
+func (r *CaptureReader) Next() (CapturedMessage, error) {
+	for r.scanner.Scan() {
+		r.line++
+		line := r.scanner.Bytes()
+		if len(strings.TrimSpace(string(line))) == 0 {
+			continue
+		}
+
+		var msg CapturedMessage
+		if err := json.Unmarshal(line, &msg); err != nil {
+			return msg, fmt.Errorf("line %d: %w", r.line, err)
+		}
+		return msg, nil
+	}
+	if err := r.scanner.Err(); err != nil {
+		return CapturedMessage{}, err
+	}
+	return CapturedMessage{}, io.EOF
+}
@@ -0,0 +1,58 @@
+package utils
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCaptureQueues(t *testing.T) {
+	tests := []struct {
+		name   string
+		queues []string
+		want   []string
+	}{
+		{name: "named queues", queues: []string{"quotations", " transactions"}, want: []string{"quotations", "transactions"}},
+		{name: "no queues captures all", queues: nil, want: []string{"quotations", "transactions", "users"}},
+		{name: "empty names capture all", queues: []string{""}, want: []string{"quotations", "transactions", "users"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "capture.jsonl")
+			capture, err := NewCapture(path, tt.queues)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, queue := range []string{"quotations", "transactions", "users"} {
+				if err := capture.Record(queue, "id", []byte(`{}`)); err != nil {
+					t.Fatal(err)
+				}
+			}
+			capture.Close()
+
+			data, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			reader := NewCaptureReader(bytes.NewReader(data))
+			var got []string
+			for {
+				msg, err := reader.Next()
+				if err != nil {
+					break
+				}
+				got = append(got, msg.Queue)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("captured %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("captured %v, want %v", got, tt.want)
+				}
+			}
+		})
+	}
+}
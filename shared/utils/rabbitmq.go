@@ -2,14 +2,39 @@ package utils
 
 import (
 	"encoding/json"
+	"errors"
 	"log"
+	"os"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/streadway/amqp"
 )
 
+// Message is a delivery received from a queue along with its envelope
+type Message struct {
+	ID          string
+	Queue       string
+	Body        []byte
+	Headers     map[string]interface{}
+	Timestamp   time.Time
+	Redelivered bool
+}
+
+// ErrNotConfirmed is returned when the broker did not confirm a published
+// message, which may or may not have been delivered
+var ErrNotConfirmed = errors.New("broker did not confirm the message")
+
 type RabbitMQ struct {
 	conn    *amqp.Connection
 	channel *amqp.Channel
+	capture *Capture
+
+	// publishMu serializes publishing, so each confirmation read from
+	// confirms belongs to the message just published
+	publishMu sync.Mutex
+	confirms  chan amqp.Confirmation
 }
 
 func NewRabbitMQ(url string) (*RabbitMQ, error) {
@@ -23,13 +48,70 @@ func NewRabbitMQ(url string) (*RabbitMQ, error) {
 		return nil, err
 	}
 
+	// Have the broker confirm every publish, so a message is known to be
+	// stored before the publisher forgets it
+	if err := ch.Confirm(false); err != nil {
+		conn.Close()
+		return nil, err
+	}
+
 	return &RabbitMQ{
-		conn:    conn,
-		channel: ch,
+		conn:     conn,
+		channel:  ch,
+		confirms: ch.NotifyPublish(make(chan amqp.Confirmation, 1)),
 	}, nil
 }
 
+// EnableCapture tees every message published or consumed on queues, or on
+// all queues if none is named, to a JSON lines file at path, for replaying
+// the traffic later
+func (r *RabbitMQ) EnableCapture(path string, queues []string) error {
+	capture, err := NewCapture(path, queues)
+	if err != nil {
+		return err
+	}
+	r.capture = capture
+	return nil
+}
+
+// EnableCaptureFromEnv enables capture when CAPTURE_FILE is set, recording
+// the comma separated queues in CAPTURE_QUEUES, or all queues if it is unset
+func (r *RabbitMQ) EnableCaptureFromEnv() error {
+	path := os.Getenv("CAPTURE_FILE")
+	if path == "" {
+		return nil
+	}
+	queues := os.Getenv("CAPTURE_QUEUES")
+	if strings.TrimSpace(queues) == "" {
+		log.Printf("Capturing all queues to %s", path)
+		return r.EnableCapture(path, nil)
+	}
+	log.Printf("Capturing %s to %s", queues, path)
+	return r.EnableCapture(path, strings.Split(queues, ","))
+}
+
+func (r *RabbitMQ) record(queue, messageID string, body []byte) {
+	if r.capture == nil {
+		return
+	}
+	if err := r.capture.Record(queue, messageID, body); err != nil {
+		log.Printf("Error capturing message: %v", err)
+	}
+}
+
+// PublishMessage publishes a persistent message tagged with a new message ID
 func (r *RabbitMQ) PublishMessage(queue string, message interface{}) error {
+	return r.PublishMessageWithID(queue, NewMessageID(), message)
+}
+
+// PublishMessageWithID publishes a persistent message tagged with messageID,
+// which consumers can use to detect redeliveries of the same message. It
+// returns once the broker confirmed the message, or ErrNotConfirmed if the
+// broker nacked it or the channel closed first.
+func (r *RabbitMQ) PublishMessageWithID(queue, messageID string, message interface{}) error {
+	r.publishMu.Lock()
+	defer r.publishMu.Unlock()
+
 	// Declare queue
 	_, err := r.channel.QueueDeclare(
 		queue, // name
@@ -49,18 +131,33 @@ func (r *RabbitMQ) PublishMessage(queue string, message interface{}) error {
 		return err
 	}
 
-	// Publish message
-	err = r.channel.Publish(
+	return r.publish(queue, amqp.Publishing{
+		ContentType:  "application/json",
+		DeliveryMode: amqp.Persistent,
+		MessageId:    messageID,
+		Timestamp:    time.Now(),
+		Body:         body,
+	})
+}
+
+// publish sends msg to a declared queue and waits for the broker to confirm
+// it. The caller must hold publishMu.
+func (r *RabbitMQ) publish(queue string, msg amqp.Publishing) error {
+	err := r.channel.Publish(
 		"",    // exchange
 		queue, // routing key
 		false, // mandatory
 		false, // immediate
-		amqp.Publishing{
-			ContentType: "application/json",
-			Body:        body,
-		})
+		msg)
+	if err != nil {
+		return err
+	}
 
-	return err
+	if confirm, ok := <-r.confirms; !ok || !confirm.Ack {
+		return ErrNotConfirmed
+	}
+	r.record(queue, msg.MessageId, msg.Body)
+	return nil
 }
 
 func (r *RabbitMQ) ConsumeMessages(queue string, handler func([]byte) error) error {
@@ -92,6 +189,7 @@ func (r *RabbitMQ) ConsumeMessages(queue string, handler func([]byte) error) err
 
 	go func() {
 		for d := range msgs {
+			r.record(queue, d.MessageId, d.Body)
 			if err := handler(d.Body); err != nil {
 				log.Printf("Error processing message: %v", err)
 			}
@@ -101,7 +199,100 @@ func (r *RabbitMQ) ConsumeMessages(queue string, handler func([]byte) error) err
 	return nil
 }
 
+// ConsumeWithAck consumes messages with manual acknowledgement. A message is
+// acked once handler returns nil. On error it is requeued once; if it fails
+// again it is moved to the queue's dead letter queue, so a poison message
+// cannot block the queue and is kept for inspection.
+func (r *RabbitMQ) ConsumeWithAck(queue string, handler func(Message) error) error {
+	// Declare queue and its dead letter queue
+	for _, name := range []string{queue, DeadLetterQueue(queue)} {
+		_, err := r.channel.QueueDeclare(
+			name,  // name
+			true,  // durable
+			false, // delete when unused
+			false, // exclusive
+			false, // no-wait
+			nil,   // arguments
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	msgs, err := r.channel.Consume(
+		queue, // queue
+		"",    // consumer
+		false, // auto-ack
+		false, // exclusive
+		false, // no-local
+		false, // no-wait
+		nil,   // args
+	)
+	if err != nil {
+		return err
+	}
+
+	go func() {
+		for d := range msgs {
+			r.record(queue, d.MessageId, d.Body)
+			msg := Message{
+				ID:          d.MessageId,
+				Queue:       queue,
+				Body:        d.Body,
+				Headers:     d.Headers,
+				Timestamp:   d.Timestamp,
+				Redelivered: d.Redelivered,
+			}
+			if err := handler(msg); err != nil {
+				log.Printf("Error processing message %s from %s: %v", d.MessageId, queue, err)
+				if !d.Redelivered {
+					d.Nack(false, true)
+					continue
+				}
+				if err := r.deadLetter(queue, d, err); err != nil {
+					log.Printf("Error dead-lettering message %s from %s: %v", d.MessageId, queue, err)
+					d.Nack(false, true)
+					continue
+				}
+			}
+			d.Ack(false)
+		}
+	}()
+
+	return nil
+}
+
+// DeadLetterQueue returns the queue where messages of queue that keep
+// failing are moved
+func DeadLetterQueue(queue string) string {
+	return queue + ".dead"
+}
+
+// deadLetter copies a delivery that failed with cause to the dead letter
+// queue of queue, keeping its ID and headers
+func (r *RabbitMQ) deadLetter(queue string, d amqp.Delivery, cause error) error {
+	headers := amqp.Table{}
+	for k, v := range d.Headers {
+		headers[k] = v
+	}
+	headers["x-error"] = cause.Error()
+
+	r.publishMu.Lock()
+	defer r.publishMu.Unlock()
+	return r.publish(DeadLetterQueue(queue), amqp.Publishing{
+		Headers:      headers,
+		ContentType:  d.ContentType,
+		DeliveryMode: amqp.Persistent,
+		MessageId:    d.MessageId,
+		Timestamp:    d.Timestamp,
+		Body:         d.Body,
+	})
+}
+
 func (r *RabbitMQ) Close() {
+	if r.capture != nil {
+		r.capture.Close()
+	}
 	if r.channel != nil {
 		r.channel.Close()
 	}